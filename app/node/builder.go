@@ -208,6 +208,8 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build node.Network")
 	}
+	nd.ConfigModule.OnValidate(nd.network.ValidateConfig)
+	nd.ConfigModule.OnChange(nd.network.ApplyConfig)
 
 	nd.VersionTable, err = version.ConfigureProtocolVersions(nd.network.NetworkName)
 	if err != nil {
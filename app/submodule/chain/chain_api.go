@@ -4,14 +4,20 @@ import (
 	"context"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	"github.com/filecoin-project/venus/pkg/specactors/policy"
+	"github.com/filecoin-project/venus/pkg/state"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/vm"
 	"github.com/ipfs/go-cid"
 	xerrors "github.com/pkg/errors"
 	"io"
+	"sort"
 	"time"
 )
 
@@ -20,7 +26,21 @@ type BlockMessage struct {
 	BlsMessage   []*types.UnsignedMessage
 }
 
+// ActorState is an actor with its decoded state.
+type ActorState struct {
+	Balance types.AttoFIL
+	Code    cid.Cid
+	// Name is the name of the builtin actor code, e.g. fil/2/storageminer.
+	Name  string
+	State interface{}
+}
+
 type ChainAPI struct { //nolint
+	MarketStateAPI
+	MinerStateAPI
+	PowerStateAPI
+	VerifregStateAPI
+
 	chain *ChainSubmodule
 }
 
@@ -57,13 +77,65 @@ func (chainAPI *ChainAPI) ProtocolParameters(ctx context.Context) (*ProtocolPara
 		supportedSectors = append(supportedSectors, SectorInfo{sectorSize, maxUserBytes})
 	}
 
+	head, err := chainAPI.ChainHead()
+	if err != nil {
+		return nil, err
+	}
+	height := head.EnsureHeight()
+
+	var beaconSchedule []BeaconSchedulePoint
+	for start, drand := range chainAPI.chain.drandSchedule {
+		beaconSchedule = append(beaconSchedule, BeaconSchedulePoint{
+			Start:   start,
+			Network: drand.String(),
+			Servers: config.DrandConfigs[drand].Servers,
+		})
+	}
+	sort.Slice(beaconSchedule, func(i, j int) bool {
+		return beaconSchedule[i].Start < beaconSchedule[j].Start
+	})
+
 	return &ProtocolParams{
-		Network:          networkName,
-		BlockTime:        chainAPI.chain.config.BlockTime(),
-		SupportedSectors: supportedSectors,
+		Network:             networkName,
+		BlockTime:           chainAPI.chain.config.BlockTime(),
+		SupportedSectors:    supportedSectors,
+		BlockDelaySecs:      uint64(chainAPI.chain.config.BlockTime().Seconds()),
+		NetworkVersion:      chainAPI.chain.Fork.GetNtwkVersion(ctx, height),
+		BeaconSchedule:      beaconSchedule,
+		SupportedSealProofs: policy.SupportedProofTypes(),
+		GasPricelistEpoch:   chainAPI.chain.gasPriceSchedule.PricelistEpoch(height),
 	}, nil
 }
 
+// StateNetworkVersion returns the network version at the tipset, the head when the key is empty.
+func (chainAPI *ChainAPI) StateNetworkVersion(ctx context.Context, key block.TipSetKey) (network.Version, error) {
+	if key.Empty() {
+		key = chainAPI.chain.ChainReader.GetHead()
+	}
+	ts, err := chainAPI.chain.ChainReader.GetTipSet(key)
+	if err != nil {
+		return network.Version0, xerrors.Wrapf(err, "failed to load tipset %s", key)
+	}
+	return chainAPI.chain.Fork.GetNtwkVersion(ctx, ts.EnsureHeight()), nil
+}
+
+// StateUpgradeSchedule returns the enabled upgrades of the network, ordered by height.
+func (chainAPI *ChainAPI) StateUpgradeSchedule(ctx context.Context) []NetworkUpgrade {
+	var out []NetworkUpgrade
+	for _, upgrade := range chainAPI.chain.Fork.GetUpgradeSchedule() {
+		out = append(out, NetworkUpgrade{
+			Height:          upgrade.Height,
+			Network:         upgrade.Network,
+			Name:            upgrade.Name,
+			HasMigration:    upgrade.Migration != nil,
+			Expensive:       upgrade.Expensive,
+			HasPreMigration: upgrade.PreMigrate != nil,
+			PreMigrations:   upgrade.PreMigrations,
+		})
+	}
+	return out
+}
+
 func (chainAPI *ChainAPI) ChainHead() (*block.TipSet, error) {
 	headkey := chainAPI.chain.ChainReader.GetHead()
 	return chainAPI.chain.ChainReader.GetTipSet(headkey)
@@ -78,6 +150,41 @@ func (chainAPI *ChainAPI) ChainSetHead(ctx context.Context, key block.TipSetKey)
 	return chainAPI.chain.ChainReader.SetHead(ctx, ts)
 }
 
+// ChainGetCheckPoint returns the current checkpoint tipset. No fork of the chain below it is ever synced.
+func (chainAPI *ChainAPI) ChainGetCheckPoint() (*block.TipSet, error) {
+	return chainAPI.chain.ChainReader.GetCheckPointTipSet()
+}
+
+// ChainSetCheckPoint sets `key` as the checkpoint iff it is the head or one of its ancestors.
+func (chainAPI *ChainAPI) ChainSetCheckPoint(ctx context.Context, key block.TipSetKey) error {
+	return chainAPI.chain.ChainReader.UpdateCheckPoint(ctx, key)
+}
+
+// ChainClearCheckPoint resets the checkpoint to the genesis tipset.
+func (chainAPI *ChainAPI) ChainClearCheckPoint(ctx context.Context) error {
+	return chainAPI.chain.ChainReader.ClearCheckPoint(ctx)
+}
+
+// ChainReorgs returns up to `count` of the most recent reorgs of the head, newest first.
+func (chainAPI *ChainAPI) ChainReorgs(count int) []*chain.ReorgEvent {
+	return chainAPI.chain.ChainReader.Reorgs(count)
+}
+
+// StateDiff returns the actors added, removed and modified between the states of the
+// tipsets preKey and curKey. If drill is true the changes of miner sectors, market deals
+// and power claims are included.
+func (chainAPI *ChainAPI) StateDiff(ctx context.Context, preKey, curKey block.TipSetKey, drill bool) (*state.StateDiff, error) {
+	preRoot, err := chainAPI.chain.State.GetTipSetStateRoot(ctx, preKey)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to get state root for %s", preKey)
+	}
+	curRoot, err := chainAPI.chain.State.GetTipSetStateRoot(ctx, curKey)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to get state root for %s", curKey)
+	}
+	return state.DiffStateTrees(ctx, chainAPI.chain.State, preRoot, curRoot, drill)
+}
+
 // ChainTipSet returns the tipset at the given key
 func (chainAPI *ChainAPI) ChainTipSet(key block.TipSetKey) (*block.TipSet, error) {
 	return chainAPI.chain.ChainReader.GetTipSet(key)
@@ -106,6 +213,33 @@ func (chainAPI *ChainAPI) ListActor(ctx context.Context) (map[address.Address]*t
 	return chainAPI.chain.State.LsActors(ctx)
 }
 
+// StateGetActor returns the actor at addr in the state after the tipset, the head when the key is empty.
+func (chainAPI *ChainAPI) StateGetActor(ctx context.Context, addr address.Address, key block.TipSetKey) (*types.Actor, error) {
+	if key.Empty() {
+		key = chainAPI.chain.ChainReader.GetHead()
+	}
+	return chainAPI.chain.State.GetActorAt(ctx, key, addr)
+}
+
+// StateReadState returns the actor at addr in the state after the tipset, the head when the key is
+// empty, with its state decoded with the state type of its builtin actor code and version.
+func (chainAPI *ChainAPI) StateReadState(ctx context.Context, addr address.Address, key block.TipSetKey) (*ActorState, error) {
+	_, view, err := chainAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	act, st, err := view.LoadActorState(ctx, addr)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to load the state of actor %s", addr)
+	}
+	return &ActorState{
+		Balance: act.Balance,
+		Code:    act.Code.Cid,
+		Name:    builtin.ActorNameByCode(act.Code.Cid),
+		State:   st,
+	}, nil
+}
+
 // ChainGetBlock gets a block by CID
 func (chainAPI *ChainAPI) ChainGetBlock(ctx context.Context, id cid.Cid) (*block.Block, error) {
 	return chainAPI.chain.State.GetBlock(ctx, id)
@@ -122,6 +256,16 @@ func (chainAPI *ChainAPI) ChainGetMessages(ctx context.Context, metaCid cid.Cid)
 	return bmsg, nil
 }
 
+// ChainGetMessage gets a message by CID
+func (chainAPI *ChainAPI) ChainGetMessage(ctx context.Context, id cid.Cid) (*types.UnsignedMessage, error) {
+	return chainAPI.chain.State.GetMessage(ctx, id)
+}
+
+// ChainReadObj reads the raw bytes of the ipld object at the CID
+func (chainAPI *ChainAPI) ChainReadObj(ctx context.Context, obj cid.Cid) ([]byte, error) {
+	return chainAPI.chain.State.ReadObj(ctx, obj)
+}
+
 // ChainGetReceipts gets a receipt collection by CID
 func (chainAPI *ChainAPI) ChainGetReceipts(ctx context.Context, id cid.Cid) ([]types.MessageReceipt, error) {
 	return chainAPI.chain.State.GetReceipts(ctx, id)
@@ -0,0 +1,29 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestStateUpgradeSchedule(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, _ := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+
+	upgrades := nd.Chain().API().StateUpgradeSchedule(ctx)
+	require.NotEmpty(t, upgrades)
+	for i, u := range upgrades {
+		if i > 0 {
+			assert.True(t, upgrades[i-1].Height <= u.Height, u.Name)
+		}
+		// No migration has a pre-migration yet.
+		assert.False(t, u.HasPreMigration, u.Name)
+		assert.Empty(t, u.PreMigrations, u.Name)
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/proofverification"
 	"github.com/filecoin-project/venus/pkg/config"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/venus/app/submodule/chain/cst"
 	"github.com/filecoin-project/venus/pkg/beacon"
@@ -18,13 +21,17 @@ import (
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/fork"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/slashing"
 	appstate "github.com/filecoin-project/venus/pkg/state"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
 	"github.com/filecoin-project/venus/pkg/vm/register"
 	"github.com/filecoin-project/venus/pkg/vmsupport"
 )
 
+var log = logging.Logger("chain_module") // nolint: deadcode
+
 // ChainSubmodule enhances the `Node` with chain capabilities.
 type ChainSubmodule struct { //nolint
 	ChainReader  *chain.Store
@@ -39,10 +46,15 @@ type ChainSubmodule struct { //nolint
 
 	Fork fork.IFork
 
+	CirculatingSupply *consensus.CirculatingSupplyCalculator
+
 	CheckPoint block.TipSetKey
 	Drand      beacon.Schedule
 
-	config chainConfig
+	config           chainConfig
+	checkPointConfig *config.ChainConfig
+	drandSchedule    map[abi.ChainEpoch]config.DrandEnum
+	gasPriceSchedule *gas.PricesSchedule
 }
 
 // xxx go back to using an interface here
@@ -55,6 +67,7 @@ type chainConfig interface {
 	GenesisCid() cid.Cid
 	BlockTime() time.Duration
 	Repo() repo.Repo
+	Journal() journal.Journal
 }
 
 // NewChainSubmodule creates a new chain submodule.
@@ -66,6 +79,7 @@ func NewChainSubmodule(config chainConfig,
 	// initialize chain store
 	chainStatusReporter := chain.NewStatusReporter()
 	chainStore := chain.NewStore(repo.ChainDatastore(), blockstore.CborStore, blockstore.Blockstore, chainStatusReporter, config.GenesisCid())
+	chainStore.SetJournal(config.Journal().Topic("chain"))
 	//drand
 	genBlk, err := chainStore.GetGenesisBlock(context.TODO())
 	if err != nil {
@@ -87,6 +101,7 @@ func NewChainSubmodule(config chainConfig,
 		return nil, err
 	}
 	processor := consensus.NewDefaultProcessor(syscalls, chainState)
+	circulatingSupply := consensus.NewCirculatingSupplyCalculator(blockstore.Blockstore, chainState, repo.Config().NetworkParams.ForkUpgradeParam)
 
 	return &ChainSubmodule{
 		ChainReader:    chainStore,
@@ -99,12 +114,53 @@ func NewChainSubmodule(config chainConfig,
 		Drand:          drand,
 		config:         config,
 		CheckPoint:     chainStore.GetCheckPoint(),
+
+		CirculatingSupply: circulatingSupply,
+		checkPointConfig:  repo.Config().Chain,
+		drandSchedule:     repo.Config().NetworkParams.DrandSchedule,
+		gasPriceSchedule:  gas.NewPricesSchedule(repo.Config().NetworkParams.ForkUpgradeParam),
 	}, nil
 }
 
 // Start loads the chain from disk.
 func (chain *ChainSubmodule) Start(ctx context.Context) error {
-	return chain.ChainReader.Load(ctx)
+	if err := chain.ChainReader.Load(ctx); err != nil {
+		return err
+	}
+
+	if chain.checkPointConfig != nil && chain.checkPointConfig.AutoCheckPointDistance > 0 {
+		distance := chain.checkPointConfig.AutoCheckPointDistance
+		onHeadChange(chain.ChainReader.SubHeadChanges(ctx), func(head *block.TipSet) {
+			if err := chain.ChainReader.AdvanceCheckPoint(ctx, head, distance); err != nil {
+				log.Warnf("failed to advance checkpoint: %s", err)
+			}
+		})
+	}
+
+	onHeadChange(chain.ChainReader.SubHeadChanges(ctx), func(head *block.TipSet) {
+		chain.recordSupply(ctx, head)
+	})
+
+	onHeadChange(chain.ChainReader.SubHeadChanges(ctx), func(head *block.TipSet) {
+		chain.Fork.PreMigrate(ctx, head)
+	})
+	return nil
+}
+
+// onHeadChange calls f with the new head after every change read from notifs, until notifs is closed.
+func onHeadChange(notifs <-chan []*chain.HeadChange, f func(head *block.TipSet)) {
+	go func() {
+		for changes := range notifs {
+			if len(changes) == 0 {
+				continue
+			}
+			// Reverted tipsets come first, then the applied ones by increasing height.
+			last := changes[len(changes)-1]
+			if last.Type != chain.HCRevert {
+				f(last.Val)
+			}
+		}
+	}()
 }
 
 // StateView loads the state view for a tipset, i.e. the state *after* the application of the tipset's messages.
@@ -116,6 +172,29 @@ func (chain *ChainSubmodule) StateView(baseKey block.TipSetKey) (*appstate.View,
 	return view, nil
 }
 
+// tipSetView returns the tipset at key, the head when key is empty, and the
+// state view after it.
+func (chain *ChainSubmodule) tipSetView(key block.TipSetKey) (*block.TipSet, *appstate.View, error) {
+	if key.Empty() {
+		key = chain.ChainReader.GetHead()
+	}
+	ts, err := chain.ChainReader.GetTipSet(key)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load tipset %s", key)
+	}
+	view, err := chain.State.StateView(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ts, view, nil
+}
+
 func (chain *ChainSubmodule) API() *ChainAPI {
-	return &ChainAPI{chain: chain}
+	return &ChainAPI{
+		MarketStateAPI:   MarketStateAPI{chain: chain},
+		MinerStateAPI:    MinerStateAPI{chain: chain},
+		PowerStateAPI:    PowerStateAPI{chain: chain},
+		VerifregStateAPI: VerifregStateAPI{chain: chain},
+		chain:            chain,
+	}
 }
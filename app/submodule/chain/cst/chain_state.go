@@ -188,6 +188,26 @@ func (chn *ChainStateReadWriter) GetMessages(ctx context.Context, metaCid cid.Ci
 	return bls, secp, nil
 }
 
+// GetMessage gets a message by CID. The message of a signed secp message is returned
+// if no unsigned message is found at the CID.
+func (chn *ChainStateReadWriter) GetMessage(ctx context.Context, id cid.Cid) (*types.UnsignedMessage, error) {
+	raw, err := chn.ReadObj(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get message %s", id)
+	}
+
+	msg := &types.UnsignedMessage{}
+	if err := encoding.Decode(raw, msg); err == nil {
+		return msg, nil
+	}
+
+	smsg := &types.SignedMessage{}
+	if err := encoding.Decode(raw, smsg); err != nil {
+		return nil, errors.Wrapf(err, "could not decode message %s", id)
+	}
+	return &smsg.Message, nil
+}
+
 // GetReceipts gets a receipt collection by CID.
 func (chn *ChainStateReadWriter) GetReceipts(ctx context.Context, id cid.Cid) ([]types.MessageReceipt, error) {
 	return chn.messageProvider.LoadReceipts(ctx, id)
@@ -314,11 +334,17 @@ func (chn *ChainStateReadWriter) LsActors(ctx context.Context) (map[address.Addr
 // The function signature is typically used to enable a caller to decode the
 // output of an actor method call (message).
 func (chn *ChainStateReadWriter) GetActorSignature(ctx context.Context, actorAddr address.Address, method abi.MethodNum) (vm.ActorMethodSignature, error) {
+	return chn.GetActorSignatureAt(ctx, chn.readWriter.GetHead(), actorAddr, method)
+}
+
+// GetActorSignatureAt returns the signature of the given actor's given method, resolving
+// the actor's code in the state after the tipset specified by tipKey.
+func (chn *ChainStateReadWriter) GetActorSignatureAt(ctx context.Context, tipKey block.TipSetKey, actorAddr address.Address, method abi.MethodNum) (vm.ActorMethodSignature, error) {
 	if method == builtin.MethodSend {
 		return nil, ErrNoMethod
 	}
 
-	actor, err := chn.GetActor(ctx, actorAddr)
+	actor, err := chn.GetActorAt(ctx, tipKey, actorAddr)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get actor")
 	} else if actor.Empty() {
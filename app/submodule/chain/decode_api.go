@@ -0,0 +1,216 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	xerrors "github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/venus/app/submodule/chain/cst"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/encoding"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm"
+)
+
+// DecodedMessage is a message with its params decoded with the method signature of its receiver.
+type DecodedMessage struct {
+	Cid     cid.Cid
+	Message *types.UnsignedMessage
+	// Params is nil for a plain value transfer, or when the receiver or its method is unknown.
+	Params interface{}
+	// Error is set when the params do not decode.
+	Error string `json:",omitempty"`
+}
+
+// DecodedReceipt is a receipt with its return value decoded with the method signature of the
+// receiver of its message.
+type DecodedReceipt struct {
+	MessageCid cid.Cid
+	Receipt    types.MessageReceipt
+	// Return is nil for an empty return value, or when the receiver or its method is unknown.
+	Return interface{}
+	// Error is set when the return value does not decode.
+	Error string `json:",omitempty"`
+}
+
+// signature returns the signature of the method of the actor at to in the state after the tipset,
+// the head when the key is empty. It returns nil without error when the method has no signature.
+func (chainAPI *ChainAPI) signature(ctx context.Context, to address.Address, method abi.MethodNum, key block.TipSetKey) (vm.ActorMethodSignature, error) {
+	if key.Empty() {
+		key = chainAPI.chain.ChainReader.GetHead()
+	}
+	signature, err := chainAPI.chain.State.GetActorSignatureAt(ctx, key, to, method)
+	if err == cst.ErrNoMethod {
+		return nil, nil
+	}
+	return signature, err
+}
+
+// StateDecodeParams decodes the params of a call to method of the actor at to, resolving the code
+// of the actor in the state after the tipset, the head when the key is empty.
+func (chainAPI *ChainAPI) StateDecodeParams(ctx context.Context, to address.Address, method abi.MethodNum, params []byte, key block.TipSetKey) (interface{}, error) {
+	signature, err := chainAPI.signature(ctx, to, method, key)
+	if err != nil || signature == nil {
+		return nil, err
+	}
+	return signature.ArgInterface(params)
+}
+
+// StateDecodeReturn decodes the return value of a call to method of the actor at to, resolving the
+// code of the actor in the state after the tipset, the head when the key is empty.
+func (chainAPI *ChainAPI) StateDecodeReturn(ctx context.Context, to address.Address, method abi.MethodNum, ret []byte, key block.TipSetKey) (interface{}, error) {
+	if len(ret) == 0 {
+		return nil, nil
+	}
+	signature, err := chainAPI.signature(ctx, to, method, key)
+	if err != nil || signature == nil {
+		return nil, err
+	}
+	return signature.ReturnInterface(ret)
+}
+
+// StateEncodeParams encodes params given as JSON into the params of a call to method of the actor
+// at to, resolving the code of the actor in the state after the tipset, the head when the key is
+// empty.
+func (chainAPI *ChainAPI) StateEncodeParams(ctx context.Context, to address.Address, method abi.MethodNum, params json.RawMessage, key block.TipSetKey) ([]byte, error) {
+	signature, err := chainAPI.signature(ctx, to, method, key)
+	if err != nil {
+		return nil, err
+	}
+	if signature == nil {
+		return nil, xerrors.Errorf("method %d of actor %s takes no params", method, to)
+	}
+
+	t := signature.ArgNil().Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(params, v.Interface()); err != nil {
+		return nil, xerrors.Wrapf(err, "failed to parse params as %s", t)
+	}
+
+	if m, ok := v.Interface().(cbg.CBORMarshaler); ok {
+		buf := new(bytes.Buffer)
+		if err := m.MarshalCBOR(buf); err != nil {
+			return nil, xerrors.Wrap(err, "failed to encode params")
+		}
+		return buf.Bytes(), nil
+	}
+	return encoding.Encode(v.Elem().Interface())
+}
+
+// ChainGetDecodedMessages returns the messages of a block with their params decoded, resolving the
+// code of the receivers in the parent state of the block, the state the messages are applied to.
+func (chainAPI *ChainAPI) ChainGetDecodedMessages(ctx context.Context, blockCid cid.Cid) ([]*DecodedMessage, error) {
+	blk, err := chainAPI.chain.State.GetBlock(ctx, blockCid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := chainAPI.chain.ChainReader.GetTipSet(blk.Parents); err != nil {
+		return nil, xerrors.Wrapf(err, "failed to load parent tipset %s", blk.Parents)
+	}
+	blsMsgs, secpMsgs, err := chainAPI.chain.State.GetMessages(ctx, blk.Messages.Cid)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]*types.UnsignedMessage, 0, len(blsMsgs)+len(secpMsgs))
+	msgs = append(msgs, blsMsgs...)
+	for _, smsg := range secpMsgs {
+		msgs = append(msgs, &smsg.Message)
+	}
+
+	out := make([]*DecodedMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		decoded, err := chainAPI.decodeMessage(ctx, msg, blk.Parents)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, decoded)
+	}
+	return out, nil
+}
+
+// StateDecodeMessage returns a message with its params decoded, resolving the code of the receiver
+// in the state after the tipset, the head when the key is empty.
+func (chainAPI *ChainAPI) StateDecodeMessage(ctx context.Context, msg *types.UnsignedMessage, key block.TipSetKey) (*DecodedMessage, error) {
+	return chainAPI.decodeMessage(ctx, msg, key)
+}
+
+func (chainAPI *ChainAPI) decodeMessage(ctx context.Context, msg *types.UnsignedMessage, key block.TipSetKey) (*DecodedMessage, error) {
+	c, err := msg.Cid()
+	if err != nil {
+		return nil, err
+	}
+	out := &DecodedMessage{Cid: c, Message: msg}
+	if out.Params, err = chainAPI.StateDecodeParams(ctx, msg.To, msg.Method, msg.Params, key); err != nil {
+		out.Error = err.Error()
+	}
+	return out, nil
+}
+
+// ChainGetDecodedReceipts returns the receipts of the messages of the parent tipset of a block with
+// their return values decoded, resolving the code of the receivers in the parent state of the block.
+func (chainAPI *ChainAPI) ChainGetDecodedReceipts(ctx context.Context, blockCid cid.Cid) ([]*DecodedReceipt, error) {
+	blk, err := chainAPI.chain.State.GetBlock(ctx, blockCid)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := chainAPI.chain.ChainReader.GetTipSet(blk.Parents)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to load parent tipset %s", blk.Parents)
+	}
+	receipts, err := chainAPI.chain.State.GetReceipts(ctx, blk.ParentMessageReceipts.Cid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Receipts follow the messages applied by the VM: the messages of each block, BLS then secp,
+	// with the messages already applied by a previous block skipped.
+	blockMsgs, err := chainAPI.chain.MessageStore.LoadTipSetMessage(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[cid.Cid]struct{})
+	var msgs []types.ChainMsg
+	for _, bm := range blockMsgs {
+		for _, msg := range append(bm.BlsMessages, bm.SecpkMessages...) {
+			c, err := msg.Cid()
+			if err != nil {
+				return nil, err
+			}
+			if _, found := seen[c]; found {
+				continue
+			}
+			seen[c] = struct{}{}
+			msgs = append(msgs, msg)
+		}
+	}
+	if len(msgs) != len(receipts) {
+		return nil, xerrors.Errorf("%d receipts for %d messages in parent tipset %s", len(receipts), len(msgs), parent.Key())
+	}
+
+	out := make([]*DecodedReceipt, len(receipts))
+	for i, receipt := range receipts {
+		msg := msgs[i].VMMessage()
+		c, err := msgs[i].Cid()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = &DecodedReceipt{MessageCid: c, Receipt: receipt}
+		if receipt.ExitCode.IsError() {
+			continue
+		}
+		if out[i].Return, err = chainAPI.StateDecodeReturn(ctx, msg.To, msg.Method, receipt.ReturnValue, parent.Key()); err != nil {
+			out[i].Error = err.Error()
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/enccid"
+	"github.com/filecoin-project/venus/pkg/encoding"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestDecodeMessages(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, maddr := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+	api := nd.Chain().API()
+	head := block.TipSetKey{}
+
+	newMessage := func(to address.Address, method abi.MethodNum, params []byte) *types.UnsignedMessage {
+		return &types.UnsignedMessage{
+			To:         to,
+			From:       maddr,
+			Value:      types.ZeroAttoFIL,
+			GasFeeCap:  types.ZeroAttoFIL,
+			GasPremium: types.ZeroAttoFIL,
+			Method:     method,
+			Params:     params,
+		}
+	}
+	params, err := encoding.Encode(&maddr)
+	require.NoError(t, err)
+	unknown, err := address.NewIDAddress(9999)
+	require.NoError(t, err)
+	addBalance := newMessage(market.Address, market.Methods.AddBalance, params)
+	badParams := newMessage(market.Address, market.Methods.AddBalance, []byte{0xff})
+	toUnknown := newMessage(unknown, market.Methods.AddBalance, params)
+	send := newMessage(market.Address, builtin.MethodSend, nil)
+
+	t.Run("decodes the params of a message", func(t *testing.T) {
+		decoded, err := api.StateDecodeMessage(ctx, addBalance, head)
+		require.NoError(t, err)
+		assert.Empty(t, decoded.Error)
+		assert.Equal(t, &maddr, decoded.Params)
+
+		decoded, err = api.StateDecodeMessage(ctx, send, head)
+		require.NoError(t, err)
+		assert.Empty(t, decoded.Error)
+		assert.Nil(t, decoded.Params)
+	})
+
+	t.Run("keeps the error of params that do not decode", func(t *testing.T) {
+		for _, msg := range []*types.UnsignedMessage{badParams, toUnknown} {
+			decoded, err := api.StateDecodeMessage(ctx, msg, head)
+			require.NoError(t, err)
+			assert.NotEmpty(t, decoded.Error)
+			assert.Nil(t, decoded.Params)
+		}
+	})
+
+	// newBlock stores a block on top of parents including the messages.
+	newBlock := func(t *testing.T, parents block.TipSetKey, msgs ...*types.UnsignedMessage) cid.Cid {
+		meta, err := nd.Chain().MessageStore.StoreMessages(ctx, nil, msgs)
+		require.NoError(t, err)
+		ts, err := nd.Chain().ChainReader.GetTipSet(nd.Chain().ChainReader.GetHead())
+		require.NoError(t, err)
+		blk := &block.Block{
+			Miner:                 maddr,
+			Parents:               parents,
+			ParentWeight:          ts.At(0).ParentWeight,
+			Height:                ts.EnsureHeight() + 1,
+			ParentStateRoot:       ts.At(0).ParentStateRoot,
+			ParentMessageReceipts: ts.At(0).ParentMessageReceipts,
+			Messages:              enccid.NewCid(meta),
+			ParentBaseFee:         ts.At(0).ParentBaseFee,
+		}
+		require.NoError(t, nd.Blockstore.Blockstore.Put(blk.ToNode()))
+		return blk.Cid()
+	}
+
+	t.Run("decodes the messages of a block in its parent state", func(t *testing.T) {
+		blockCid := newBlock(t, nd.Chain().ChainReader.GetHead(), addBalance, badParams, toUnknown)
+		decoded, err := api.ChainGetDecodedMessages(ctx, blockCid)
+		require.NoError(t, err)
+		require.Len(t, decoded, 3)
+
+		addBalanceCid, err := addBalance.Cid()
+		require.NoError(t, err)
+		assert.Equal(t, addBalanceCid, decoded[0].Cid)
+		assert.Empty(t, decoded[0].Error)
+		assert.Equal(t, &maddr, decoded[0].Params)
+		// One message not decoding does not fail the others.
+		assert.NotEmpty(t, decoded[1].Error)
+		assert.NotEmpty(t, decoded[2].Error)
+	})
+
+	t.Run("fails for a block whose parent tipset is unknown", func(t *testing.T) {
+		blockCid := newBlock(t, block.NewTipSetKey(types.EmptyMessagesCID), addBalance)
+		_, err := api.ChainGetDecodedMessages(ctx, blockCid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to load parent tipset")
+	})
+}
@@ -0,0 +1,210 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/state"
+)
+
+// errEnoughDeals stops the iteration of the deals once a page is full.
+var errEnoughDeals = xerrors.New("enough deals")
+
+// MarketStateAPI reads the state of the storage market actor at a tipset, the
+// head when the tipset key is empty.
+type MarketStateAPI struct {
+	chain *ChainSubmodule
+}
+
+// MarketBalance is the escrow of a market participant and the part of it locked in deals.
+type MarketBalance struct {
+	Escrow abi.TokenAmount
+	Locked abi.TokenAmount
+}
+
+// MarketDealFilter selects the deals listed by StateMarketDeals. Undefined addresses
+// and zero epochs match all deals.
+type MarketDealFilter struct {
+	Client   address.Address
+	Provider address.Address
+	// FromEpoch and ToEpoch select the deals whose duration overlaps the range.
+	FromEpoch abi.ChainEpoch
+	ToEpoch   abi.ChainEpoch
+	// Offset is the number of matching deals skipped, Limit the maximum number
+	// of deals returned, 0 for no limit.
+	Offset int
+	Limit  int
+}
+
+// MarketDealInfo is a deal with its id.
+type MarketDealInfo struct {
+	ID abi.DealID
+	state.MarketDeal
+}
+
+// StateMarketDeals returns the deals matching the filter, ordered by id.
+func (marketStateAPI *MarketStateAPI) StateMarketDeals(ctx context.Context, filter MarketDealFilter, key block.TipSetKey) ([]*MarketDealInfo, error) {
+	_, view, err := marketStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Proposals store the ID addresses of the participants.
+	if filter.Client != address.Undef {
+		if filter.Client, err = view.InitResolveAddress(ctx, filter.Client); err != nil {
+			return nil, xerrors.Wrapf(err, "failed to resolve client address")
+		}
+	}
+	if filter.Provider != address.Undef {
+		if filter.Provider, err = view.InitResolveAddress(ctx, filter.Provider); err != nil {
+			return nil, xerrors.Wrapf(err, "failed to resolve provider address")
+		}
+	}
+
+	marketState, err := view.LoadMarketActor(ctx)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to load market actor")
+	}
+	proposals, err := marketState.Proposals()
+	if err != nil {
+		return nil, err
+	}
+	states, err := marketState.States()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*MarketDealInfo
+	skip := filter.Offset
+	err = proposals.ForEach(func(id abi.DealID, proposal market.DealProposal) error {
+		if !filter.matches(&proposal) {
+			return nil
+		}
+		if skip > 0 {
+			skip--
+			return nil
+		}
+		if filter.Limit > 0 && len(out) == filter.Limit {
+			return errEnoughDeals
+		}
+
+		dealState, found, err := states.Get(id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			dealState = &market.DealState{
+				SectorStartEpoch: -1,
+				LastUpdatedEpoch: -1,
+				SlashEpoch:       -1,
+			}
+		}
+		out = append(out, &MarketDealInfo{
+			ID:         id,
+			MarketDeal: state.MarketDeal{Proposal: proposal, State: *dealState},
+		})
+		return nil
+	})
+	if err != nil && err != errEnoughDeals {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (filter *MarketDealFilter) matches(proposal *market.DealProposal) bool {
+	if filter.Client != address.Undef && proposal.Client != filter.Client {
+		return false
+	}
+	if filter.Provider != address.Undef && proposal.Provider != filter.Provider {
+		return false
+	}
+	if filter.FromEpoch != 0 && proposal.EndEpoch < filter.FromEpoch {
+		return false
+	}
+	if filter.ToEpoch != 0 && proposal.StartEpoch > filter.ToEpoch {
+		return false
+	}
+	return true
+}
+
+// StateMarketStorageDeal returns the proposal and the state of a deal.
+func (marketStateAPI *MarketStateAPI) StateMarketStorageDeal(ctx context.Context, dealID abi.DealID, key block.TipSetKey) (*state.MarketDeal, error) {
+	_, view, err := marketStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	return view.StateMarketStorageDeal(ctx, dealID)
+}
+
+// StateMarketBalance returns the escrow and the locked balance of a market participant.
+func (marketStateAPI *MarketStateAPI) StateMarketBalance(ctx context.Context, addr address.Address, key block.TipSetKey) (MarketBalance, error) {
+	_, view, err := marketStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return MarketBalance{}, err
+	}
+	idAddr, err := view.InitResolveAddress(ctx, addr)
+	if err != nil {
+		return MarketBalance{}, xerrors.Wrapf(err, "failed to resolve address %s", addr)
+	}
+
+	marketState, err := view.LoadMarketActor(ctx)
+	if err != nil {
+		return MarketBalance{}, xerrors.Wrap(err, "failed to load market actor")
+	}
+	escrow, err := marketState.EscrowTable()
+	if err != nil {
+		return MarketBalance{}, err
+	}
+	locked, err := marketState.LockedTable()
+	if err != nil {
+		return MarketBalance{}, err
+	}
+
+	var out MarketBalance
+	if out.Escrow, err = escrow.Get(idAddr); err != nil {
+		return MarketBalance{}, xerrors.Wrap(err, "failed to get escrow balance")
+	}
+	if out.Locked, err = locked.Get(idAddr); err != nil {
+		return MarketBalance{}, xerrors.Wrap(err, "failed to get locked balance")
+	}
+	return out, nil
+}
+
+// StateMarketParticipants returns the balances of all the market participants by ID address.
+func (marketStateAPI *MarketStateAPI) StateMarketParticipants(ctx context.Context, key block.TipSetKey) (map[string]MarketBalance, error) {
+	_, view, err := marketStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	marketState, err := view.LoadMarketActor(ctx)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to load market actor")
+	}
+	escrow, err := marketState.EscrowTable()
+	if err != nil {
+		return nil, err
+	}
+	locked, err := marketState.LockedTable()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]MarketBalance)
+	err = escrow.ForEach(func(a address.Address, es abi.TokenAmount) error {
+		lk, err := locked.Get(a)
+		if err != nil {
+			return err
+		}
+		out[a.String()] = MarketBalance{Escrow: es, Locked: lk}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/pkg/block"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestMarketStateAPI(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, maddr := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+	api := nd.Chain().API()
+	head := block.TipSetKey{}
+
+	// The deals of the two sectors of the miner.
+	deals, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{}, head)
+	require.NoError(t, err)
+	require.Len(t, deals, 2)
+	assert.True(t, deals[0].ID < deals[1].ID)
+
+	t.Run("filters the deals by participant", func(t *testing.T) {
+		byProvider, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{Provider: maddr}, head)
+		require.NoError(t, err)
+		assert.Len(t, byProvider, 2)
+
+		byClient, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{Client: deals[0].Proposal.Client}, head)
+		require.NoError(t, err)
+		assert.Len(t, byClient, 2)
+
+		other, err := address.NewIDAddress(9999)
+		require.NoError(t, err)
+		none, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{Provider: other}, head)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("filters the deals by epoch", func(t *testing.T) {
+		end := deals[0].Proposal.EndEpoch
+		after, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{FromEpoch: end + 1}, head)
+		require.NoError(t, err)
+		assert.Empty(t, after)
+
+		overlapping, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{FromEpoch: 1, ToEpoch: 10}, head)
+		require.NoError(t, err)
+		assert.Len(t, overlapping, 2)
+	})
+
+	t.Run("pages the deals", func(t *testing.T) {
+		first, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{Limit: 1}, head)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.Equal(t, deals[0].ID, first[0].ID)
+
+		second, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{Offset: 1, Limit: 1}, head)
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, deals[1].ID, second[0].ID)
+
+		past, err := api.StateMarketDeals(ctx, chain.MarketDealFilter{Offset: 2}, head)
+		require.NoError(t, err)
+		assert.Empty(t, past)
+	})
+
+	t.Run("storage deal", func(t *testing.T) {
+		deal, err := api.StateMarketStorageDeal(ctx, deals[0].ID, head)
+		require.NoError(t, err)
+		assert.Equal(t, maddr, deal.Proposal.Provider)
+		assert.Equal(t, abi.PaddedPieceSize(2048), deal.Proposal.PieceSize)
+		assert.Equal(t, deals[0].Proposal, deal.Proposal)
+
+		_, err = api.StateMarketStorageDeal(ctx, deals[1].ID+100, head)
+		assert.Error(t, err)
+	})
+
+	t.Run("balances", func(t *testing.T) {
+		balance, err := api.StateMarketBalance(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.True(t, balance.Escrow.GreaterThanEqual(balance.Locked))
+
+		participants, err := api.StateMarketParticipants(ctx, head)
+		require.NoError(t, err)
+		for _, p := range participants {
+			assert.True(t, p.Escrow.GreaterThanEqual(p.Locked))
+		}
+		if p, ok := participants[maddr.String()]; ok {
+			assert.Equal(t, balance, p)
+		}
+	})
+}
@@ -0,0 +1,403 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/dline"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	"github.com/filecoin-project/venus/pkg/state"
+)
+
+// The initial pledge and the pre-commit deposit estimates are raised by 10% to
+// absorb the changes of the network until the message lands on chain.
+var (
+	initialPledgeNum = big.NewInt(110)
+	initialPledgeDen = big.NewInt(100)
+)
+
+// MinerStateAPI reads the state of the miner actors at a tipset, the head when
+// the tipset key is empty.
+type MinerStateAPI struct {
+	chain *ChainSubmodule
+}
+
+// MinerSectors is the number of sectors of a miner.
+type MinerSectors struct {
+	// Live sectors are active, faulty or recovering sectors not terminated yet.
+	Live uint64
+	// Active sectors are live sectors proven in their last window PoSt.
+	Active uint64
+	// Faulty sectors are live sectors declared or detected faulty.
+	Faulty uint64
+}
+
+// Deadline is a window PoSt deadline of a miner.
+type Deadline struct {
+	// PostSubmissions are the partitions proven in the current proving period.
+	PostSubmissions bitfield.BitField
+}
+
+// Partition is a partition of the sectors of a miner deadline.
+type Partition struct {
+	AllSectors        bitfield.BitField
+	FaultySectors     bitfield.BitField
+	RecoveringSectors bitfield.BitField
+	LiveSectors       bitfield.BitField
+	ActiveSectors     bitfield.BitField
+}
+
+func (minerStateAPI *MinerStateAPI) minerState(ctx context.Context, maddr address.Address, key block.TipSetKey) (miner.State, error) {
+	_, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	mas, err := view.LoadMinerActor(ctx, maddr)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to load miner actor %s", maddr)
+	}
+	return mas, nil
+}
+
+// StateMinerInfo returns the owner, worker, control addresses, peer and sector size of a miner.
+func (minerStateAPI *MinerStateAPI) StateMinerInfo(ctx context.Context, maddr address.Address, key block.TipSetKey) (*miner.MinerInfo, error) {
+	_, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	return view.MinerInfo(ctx, maddr)
+}
+
+// StateMinerSectorCount returns the number of live, active and faulty sectors of a miner.
+func (minerStateAPI *MinerStateAPI) StateMinerSectorCount(ctx context.Context, maddr address.Address, key block.TipSetKey) (MinerSectors, error) {
+	mas, err := minerStateAPI.minerState(ctx, maddr, key)
+	if err != nil {
+		return MinerSectors{}, err
+	}
+
+	var live, active, faulty []bitfield.BitField
+	err = mas.ForEachDeadline(func(_ uint64, dl miner.Deadline) error {
+		return dl.ForEachPartition(func(_ uint64, part miner.Partition) error {
+			l, err := part.LiveSectors()
+			if err != nil {
+				return err
+			}
+			a, err := part.ActiveSectors()
+			if err != nil {
+				return err
+			}
+			f, err := part.FaultySectors()
+			if err != nil {
+				return err
+			}
+			live = append(live, l)
+			active = append(active, a)
+			faulty = append(faulty, f)
+			return nil
+		})
+	})
+	if err != nil {
+		return MinerSectors{}, err
+	}
+
+	var out MinerSectors
+	for _, c := range []struct {
+		bfs   []bitfield.BitField
+		count *uint64
+	}{{live, &out.Live}, {active, &out.Active}, {faulty, &out.Faulty}} {
+		if *c.count, err = countSectors(c.bfs); err != nil {
+			return MinerSectors{}, err
+		}
+	}
+	return out, nil
+}
+
+func countSectors(bfs []bitfield.BitField) (uint64, error) {
+	merged, err := bitfield.MultiMerge(bfs...)
+	if err != nil {
+		return 0, err
+	}
+	return merged.Count()
+}
+
+// StateSectorGetInfo returns the on chain info of a sector, nil if the sector does not exist.
+func (minerStateAPI *MinerStateAPI) StateSectorGetInfo(ctx context.Context, maddr address.Address, n abi.SectorNumber, key block.TipSetKey) (*miner.SectorOnChainInfo, error) {
+	_, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	info, found, err := view.MinerGetSector(ctx, maddr, n)
+	if err != nil || !found {
+		return nil, err
+	}
+	return info, nil
+}
+
+// StateSectorPreCommitInfo returns the pre-commit info of a sector not proven yet.
+func (minerStateAPI *MinerStateAPI) StateSectorPreCommitInfo(ctx context.Context, maddr address.Address, n abi.SectorNumber, key block.TipSetKey) (*miner.SectorPreCommitOnChainInfo, error) {
+	_, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	info, found, err := view.MinerGetPrecommittedSector(ctx, maddr, n)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, xerrors.Errorf("precommit info for sector %d of miner %s not found", n, maddr)
+	}
+	return info, nil
+}
+
+// StateMinerProvingDeadline returns the deadline of the miner open at the tipset.
+func (minerStateAPI *MinerStateAPI) StateMinerProvingDeadline(ctx context.Context, maddr address.Address, key block.TipSetKey) (*dline.Info, error) {
+	ts, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	return view.StateMinerProvingDeadline(ctx, maddr, ts)
+}
+
+// StateMinerDeadlines returns the window PoSt deadlines of a miner.
+func (minerStateAPI *MinerStateAPI) StateMinerDeadlines(ctx context.Context, maddr address.Address, key block.TipSetKey) ([]Deadline, error) {
+	mas, err := minerStateAPI.minerState(ctx, maddr, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Deadline
+	err = mas.ForEachDeadline(func(_ uint64, dl miner.Deadline) error {
+		ps, err := dl.PostSubmissions()
+		if err != nil {
+			return err
+		}
+		out = append(out, Deadline{PostSubmissions: ps})
+		return nil
+	})
+	return out, err
+}
+
+// StateMinerPartitions returns the partitions of a deadline of a miner.
+func (minerStateAPI *MinerStateAPI) StateMinerPartitions(ctx context.Context, maddr address.Address, dlIdx uint64, key block.TipSetKey) ([]Partition, error) {
+	mas, err := minerStateAPI.minerState(ctx, maddr, key)
+	if err != nil {
+		return nil, err
+	}
+	dl, err := mas.LoadDeadline(dlIdx)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to load deadline %d", dlIdx)
+	}
+
+	var out []Partition
+	err = dl.ForEachPartition(func(_ uint64, part miner.Partition) error {
+		var p Partition
+		var err error
+		if p.AllSectors, err = part.AllSectors(); err != nil {
+			return err
+		}
+		if p.FaultySectors, err = part.FaultySectors(); err != nil {
+			return err
+		}
+		if p.RecoveringSectors, err = part.RecoveringSectors(); err != nil {
+			return err
+		}
+		if p.LiveSectors, err = part.LiveSectors(); err != nil {
+			return err
+		}
+		if p.ActiveSectors, err = part.ActiveSectors(); err != nil {
+			return err
+		}
+		out = append(out, p)
+		return nil
+	})
+	return out, err
+}
+
+// collectSectors merges a bitfield of every partition of a miner.
+func (minerStateAPI *MinerStateAPI) collectSectors(ctx context.Context, maddr address.Address, key block.TipSetKey, get func(miner.Partition) (bitfield.BitField, error)) (miner.State, bitfield.BitField, error) {
+	mas, err := minerStateAPI.minerState(ctx, maddr, key)
+	if err != nil {
+		return nil, bitfield.BitField{}, err
+	}
+
+	var bfs []bitfield.BitField
+	err = mas.ForEachDeadline(func(_ uint64, dl miner.Deadline) error {
+		return dl.ForEachPartition(func(_ uint64, part miner.Partition) error {
+			bf, err := get(part)
+			if err != nil {
+				return err
+			}
+			bfs = append(bfs, bf)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, bitfield.BitField{}, err
+	}
+	merged, err := bitfield.MultiMerge(bfs...)
+	return mas, merged, err
+}
+
+// StateMinerFaults returns the faulty sectors of a miner.
+func (minerStateAPI *MinerStateAPI) StateMinerFaults(ctx context.Context, maddr address.Address, key block.TipSetKey) (bitfield.BitField, error) {
+	_, faults, err := minerStateAPI.collectSectors(ctx, maddr, key, miner.Partition.FaultySectors)
+	return faults, err
+}
+
+// StateMinerRecoveries returns the faulty sectors of a miner declared recovered, they
+// become active again with the next window PoSt of their deadline.
+func (minerStateAPI *MinerStateAPI) StateMinerRecoveries(ctx context.Context, maddr address.Address, key block.TipSetKey) (bitfield.BitField, error) {
+	_, recoveries, err := minerStateAPI.collectSectors(ctx, maddr, key, miner.Partition.RecoveringSectors)
+	return recoveries, err
+}
+
+// StateMinerSectors returns the on chain info of the sectors of a miner in the filter,
+// all its sectors when the filter is nil.
+func (minerStateAPI *MinerStateAPI) StateMinerSectors(ctx context.Context, maddr address.Address, filter *bitfield.BitField, key block.TipSetKey) ([]*miner.SectorOnChainInfo, error) {
+	mas, err := minerStateAPI.minerState(ctx, maddr, key)
+	if err != nil {
+		return nil, err
+	}
+	return mas.LoadSectors(filter)
+}
+
+// StateMinerActiveSectors returns the on chain info of the active sectors of a miner.
+func (minerStateAPI *MinerStateAPI) StateMinerActiveSectors(ctx context.Context, maddr address.Address, key block.TipSetKey) ([]*miner.SectorOnChainInfo, error) {
+	mas, active, err := minerStateAPI.collectSectors(ctx, maddr, key, miner.Partition.ActiveSectors)
+	if err != nil {
+		return nil, err
+	}
+	return mas.LoadSectors(&active)
+}
+
+// StateMinerAvailableBalance returns the balance a miner can withdraw, the funds not
+// locked for pledges, pre-commit deposits or vesting, plus the funds vested at the tipset.
+func (minerStateAPI *MinerStateAPI) StateMinerAvailableBalance(ctx context.Context, maddr address.Address, key block.TipSetKey) (abi.TokenAmount, error) {
+	ts, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	act, err := view.LoadActor(ctx, maddr)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrapf(err, "failed to load actor %s", maddr)
+	}
+	mas, err := view.LoadMinerActor(ctx, maddr)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrapf(err, "failed to load miner actor %s", maddr)
+	}
+
+	vested, err := mas.VestedFunds(ts.EnsureHeight())
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	available, err := mas.AvailableBalance(act.Balance)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	return big.Add(available, vested), nil
+}
+
+// sectorWeight returns the quality adjusted power of a sector of the miner with the
+// pre-commit info sealed at the tipset.
+func sectorWeight(ctx context.Context, view *state.View, ts *block.TipSet, maddr address.Address, pci miner.SectorPreCommitInfo) (abi.StoragePower, error) {
+	ssize, err := pci.SealProof.SectorSize()
+	if err != nil {
+		return big.Int{}, xerrors.Wrap(err, "failed to get sector size of seal proof")
+	}
+
+	marketState, err := view.LoadMarketActor(ctx)
+	if err != nil {
+		return big.Int{}, xerrors.Wrap(err, "failed to load market actor")
+	}
+	dealWeight, verifiedWeight, err := marketState.VerifyDealsForActivation(maddr, pci.DealIDs, ts.EnsureHeight(), pci.Expiration)
+	if err != nil {
+		return big.Int{}, xerrors.Wrap(err, "failed to verify the deals of the sector")
+	}
+
+	duration := pci.Expiration - ts.EnsureHeight()
+	return builtin.QAPowerForWeight(ssize, duration, dealWeight, verifiedWeight), nil
+}
+
+// StateMinerPreCommitDepositForPower returns the deposit a miner must lock to pre-commit
+// a sector with the pre-commit info.
+func (minerStateAPI *MinerStateAPI) StateMinerPreCommitDepositForPower(ctx context.Context, maddr address.Address, pci miner.SectorPreCommitInfo, key block.TipSetKey) (abi.TokenAmount, error) {
+	ts, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	weight, err := sectorWeight(ctx, view, ts, maddr, pci)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+
+	powerState, err := view.LoadPowerActor(ctx)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrap(err, "failed to load power actor")
+	}
+	rewardState, err := view.LoadRewardActor(ctx)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrap(err, "failed to load reward actor")
+	}
+	powerSmoothed, err := powerState.TotalPowerSmoothed()
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+
+	deposit, err := rewardState.PreCommitDepositForPower(powerSmoothed, weight)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrap(err, "failed to compute pre-commit deposit")
+	}
+	return big.Div(big.Mul(deposit, initialPledgeNum), initialPledgeDen), nil
+}
+
+// StateMinerInitialPledgeCollateral returns the initial pledge a miner must lock to prove
+// a sector with the pre-commit info.
+func (minerStateAPI *MinerStateAPI) StateMinerInitialPledgeCollateral(ctx context.Context, maddr address.Address, pci miner.SectorPreCommitInfo, key block.TipSetKey) (abi.TokenAmount, error) {
+	ts, view, err := minerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	weight, err := sectorWeight(ctx, view, ts, maddr, pci)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+
+	powerState, err := view.LoadPowerActor(ctx)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrap(err, "failed to load power actor")
+	}
+	rewardState, err := view.LoadRewardActor(ctx)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrap(err, "failed to load reward actor")
+	}
+	powerSmoothed, err := powerState.TotalPowerSmoothed()
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	pledgeCollateral, err := powerState.TotalLocked()
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+
+	st, err := minerStateAPI.chain.State.GetTipSetState(ctx, ts.Key())
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	circSupply, err := minerStateAPI.chain.CirculatingSupply.GetCirculatingSupplyDetailed(ctx, ts.EnsureHeight(), st)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrap(err, "failed to compute circulating supply")
+	}
+
+	pledge, err := rewardState.InitialPledgeForPower(weight, pledgeCollateral, &powerSmoothed, circSupply.FilCirculating)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Wrap(err, "failed to compute initial pledge")
+	}
+	return big.Div(big.Mul(pledge, initialPledgeNum), initialPledgeDen), nil
+}
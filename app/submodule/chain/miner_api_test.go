@@ -0,0 +1,149 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/node/test"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+// newMinerNode starts a node on the genesis of the setup fixture, whose first
+// miner has committed the sectors 3 and 4 with a deal each, and returns the
+// node and the address of this miner.
+func newMinerNode(ctx context.Context, t *testing.T) (*node.Node, address.Address) {
+	seed, cfg, chainClock := test.CreateBootstrapSetup(t)
+	nd := test.CreateBootstrapMiner(ctx, t, seed, chainClock, cfg)
+	maddr, _ := seed.GiveMiner(t, nd, 0)
+	return nd, maddr
+}
+
+func TestMinerStateAPI(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, maddr := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+	api := nd.Chain().API()
+	head := block.TipSetKey{}
+
+	t.Run("miner info", func(t *testing.T) {
+		info, err := api.StateMinerInfo(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.Equal(t, constants.DevSectorSize, info.SectorSize)
+
+		unknown, err := address.NewIDAddress(9999)
+		require.NoError(t, err)
+		_, err = api.StateMinerInfo(ctx, unknown, head)
+		assert.Error(t, err)
+	})
+
+	t.Run("sector count", func(t *testing.T) {
+		count, err := api.StateMinerSectorCount(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), count.Live)
+		assert.Equal(t, uint64(0), count.Faulty)
+		assert.True(t, count.Active <= count.Live)
+	})
+
+	t.Run("sector info", func(t *testing.T) {
+		info, err := api.StateSectorGetInfo(ctx, maddr, 3, head)
+		require.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Equal(t, abi.SectorNumber(3), info.SectorNumber)
+		assert.Len(t, info.DealIDs, 1)
+
+		info, err = api.StateSectorGetInfo(ctx, maddr, 99, head)
+		require.NoError(t, err)
+		assert.Nil(t, info)
+	})
+
+	t.Run("pre-commit info of a proven sector is not found", func(t *testing.T) {
+		_, err := api.StateSectorPreCommitInfo(ctx, maddr, 3, head)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("sectors", func(t *testing.T) {
+		sectors, err := api.StateMinerSectors(ctx, maddr, nil, head)
+		require.NoError(t, err)
+		assert.Len(t, sectors, 2)
+
+		filter := bitfield.NewFromSet([]uint64{4})
+		sectors, err = api.StateMinerSectors(ctx, maddr, &filter, head)
+		require.NoError(t, err)
+		require.Len(t, sectors, 1)
+		assert.Equal(t, abi.SectorNumber(4), sectors[0].SectorNumber)
+
+		active, err := api.StateMinerActiveSectors(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.True(t, len(active) <= 2)
+	})
+
+	t.Run("deadlines and partitions", func(t *testing.T) {
+		deadlines, err := api.StateMinerDeadlines(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.Len(t, deadlines, int(miner.WPoStPeriodDeadlines))
+
+		var all uint64
+		for i := range deadlines {
+			partitions, err := api.StateMinerPartitions(ctx, maddr, uint64(i), head)
+			require.NoError(t, err)
+			for _, p := range partitions {
+				n, err := p.AllSectors.Count()
+				require.NoError(t, err)
+				all += n
+			}
+		}
+		assert.Equal(t, uint64(2), all)
+
+		dl, err := api.StateMinerProvingDeadline(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.True(t, dl.Index < miner.WPoStPeriodDeadlines)
+	})
+
+	t.Run("no faults nor recoveries", func(t *testing.T) {
+		faults, err := api.StateMinerFaults(ctx, maddr, head)
+		require.NoError(t, err)
+		n, err := faults.Count()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), n)
+
+		recoveries, err := api.StateMinerRecoveries(ctx, maddr, head)
+		require.NoError(t, err)
+		n, err = recoveries.Count()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), n)
+	})
+
+	t.Run("available balance", func(t *testing.T) {
+		balance, err := api.StateMinerAvailableBalance(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.True(t, balance.GreaterThanEqual(big.Zero()))
+	})
+
+	t.Run("pledge estimates", func(t *testing.T) {
+		pci := miner.SectorPreCommitInfo{
+			SealProof:    constants.DevSealProofType,
+			SectorNumber: 10,
+			Expiration:   1000000,
+		}
+		deposit, err := api.StateMinerPreCommitDepositForPower(ctx, maddr, pci, head)
+		require.NoError(t, err)
+		assert.True(t, deposit.GreaterThan(big.Zero()))
+
+		pledge, err := api.StateMinerInitialPledgeCollateral(ctx, maddr, pci, head)
+		require.NoError(t, err)
+		assert.True(t, pledge.GreaterThan(big.Zero()))
+	})
+}
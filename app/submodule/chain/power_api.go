@@ -0,0 +1,199 @@
+package chain
+
+import (
+	"context"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
+	"github.com/filecoin-project/venus/pkg/state"
+)
+
+// maxPowerHistorySamples bounds the number of states read by StateMinerPowerHistory.
+const maxPowerHistorySamples = 2000
+
+// PowerStateAPI reads the state of the storage power actor at a tipset, the
+// head when the tipset key is empty.
+type PowerStateAPI struct {
+	chain *ChainSubmodule
+}
+
+// MinerPower is the power of a miner and of the network.
+type MinerPower struct {
+	MinerPower power.Claim
+	TotalPower power.Claim
+	// HasMinPower is set when the miner has the minimum power to win blocks.
+	HasMinPower bool
+}
+
+// MinerPowerSample is the power of a miner and of the network at an epoch.
+type MinerPowerSample struct {
+	Epoch abi.ChainEpoch
+	Power *MinerPower
+}
+
+// MinerClaim is the power claimed by a miner.
+type MinerClaim struct {
+	Address address.Address
+	power.Claim
+}
+
+// TopMinersPower is the power of the network and of the miners with the most quality
+// adjusted power.
+type TopMinersPower struct {
+	TotalPower power.Claim
+	// MinerCount is the number of miners with a power claim.
+	MinerCount int
+	// Miners are ordered by decreasing quality adjusted power.
+	Miners []MinerClaim
+}
+
+// StateListMiners returns the addresses of the miners with a power claim.
+func (powerStateAPI *PowerStateAPI) StateListMiners(ctx context.Context, key block.TipSetKey) ([]address.Address, error) {
+	_, view, err := powerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	powerState, err := view.LoadPowerActor(ctx)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to load power actor")
+	}
+	return powerState.ListAllMiners()
+}
+
+// StateMinerPower returns the raw and quality adjusted power of a miner and of the network.
+func (powerStateAPI *PowerStateAPI) StateMinerPower(ctx context.Context, maddr address.Address, key block.TipSetKey) (*MinerPower, error) {
+	_, view, err := powerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	// The power table is keyed by ID addresses.
+	idAddr, err := view.InitResolveAddress(ctx, maddr)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to resolve address %s", maddr)
+	}
+	return minerPower(ctx, view, idAddr)
+}
+
+// StateTopMiners returns the power of the network and the at most n miners with the most
+// quality adjusted power. The power table is read once.
+func (powerStateAPI *PowerStateAPI) StateTopMiners(ctx context.Context, n int, key block.TipSetKey) (*TopMinersPower, error) {
+	if n < 0 {
+		return nil, xerrors.Errorf("number of miners must not be negative, was %d", n)
+	}
+	_, view, err := powerStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	powerState, err := view.LoadPowerActor(ctx)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to load power actor")
+	}
+	total, err := powerState.TotalPower()
+	if err != nil {
+		return nil, err
+	}
+
+	var claims []MinerClaim
+	err = powerState.ForEachClaim(func(maddr address.Address, claim power.Claim) error {
+		claims = append(claims, MinerClaim{Address: maddr, Claim: claim})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if !claims[i].QualityAdjPower.Equals(claims[j].QualityAdjPower) {
+			return claims[i].QualityAdjPower.GreaterThan(claims[j].QualityAdjPower)
+		}
+		return claims[i].Address.String() < claims[j].Address.String()
+	})
+
+	out := &TopMinersPower{TotalPower: total, MinerCount: len(claims)}
+	if len(claims) > n {
+		claims = claims[:n]
+	}
+	out.Miners = claims
+	return out, nil
+}
+
+// StateMinerPowerHistory samples the power of a miner and of the network every step epochs from
+// fromEpoch to toEpoch of the current chain. A null round is sampled at the tipset before it, the
+// power of a miner is zero before it is created.
+func (powerStateAPI *PowerStateAPI) StateMinerPowerHistory(ctx context.Context, maddr address.Address, fromEpoch, toEpoch, step abi.ChainEpoch) ([]*MinerPowerSample, error) {
+	if step <= 0 {
+		return nil, xerrors.Errorf("step must be positive, was %d", step)
+	}
+	if fromEpoch < 0 || fromEpoch > toEpoch {
+		return nil, xerrors.Errorf("invalid epoch range %d to %d", fromEpoch, toEpoch)
+	}
+	if (toEpoch-fromEpoch)/step >= maxPowerHistorySamples {
+		return nil, xerrors.Errorf("more than %d samples, increase the step", maxPowerHistorySamples)
+	}
+
+	head, err := powerStateAPI.chain.ChainReader.GetTipSet(powerStateAPI.chain.ChainReader.GetHead())
+	if err != nil {
+		return nil, err
+	}
+	if toEpoch > head.EnsureHeight() {
+		return nil, xerrors.Errorf("epoch %d is above the head at %d", toEpoch, head.EnsureHeight())
+	}
+	// ID addresses do not change, resolve the miner once at the head.
+	headView, err := powerStateAPI.chain.State.StateView(head.Key())
+	if err != nil {
+		return nil, err
+	}
+	idAddr, err := headView.InitResolveAddress(ctx, maddr)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to resolve address %s", maddr)
+	}
+
+	var out []*MinerPowerSample
+	for epoch := fromEpoch; epoch <= toEpoch; epoch += step {
+		ts, err := powerStateAPI.chain.ChainReader.GetTipSetByHeight(ctx, head, epoch, true)
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "failed to load tipset at %d", epoch)
+		}
+		view, err := powerStateAPI.chain.State.StateView(ts.Key())
+		if err != nil {
+			return nil, err
+		}
+		mp, err := minerPower(ctx, view, idAddr)
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "failed to get power at %d", epoch)
+		}
+		out = append(out, &MinerPowerSample{Epoch: epoch, Power: mp})
+	}
+	return out, nil
+}
+
+// minerPower reads the power of the miner with the ID address idAddr, zero when it has no claim.
+func minerPower(ctx context.Context, view *state.View, idAddr address.Address) (*MinerPower, error) {
+	powerState, err := view.LoadPowerActor(ctx)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to load power actor")
+	}
+	total, err := powerState.TotalPower()
+	if err != nil {
+		return nil, err
+	}
+	claim, found, err := powerState.MinerPower(idAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return &MinerPower{
+			MinerPower: power.Claim{RawBytePower: abi.NewStoragePower(0), QualityAdjPower: abi.NewStoragePower(0)},
+			TotalPower: total,
+		}, nil
+	}
+	hasMinPower, err := powerState.MinerNominalPowerMeetsConsensusMinimum(idAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &MinerPower{MinerPower: claim, TotalPower: total, HasMinPower: hasMinPower}, nil
+}
@@ -0,0 +1,204 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	power2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/power"
+	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/enccid"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	vmstate "github.com/filecoin-project/venus/pkg/vm/state"
+)
+
+func TestStateTopMiners(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, maddr := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+	api := nd.Chain().API()
+	head := block.TipSetKey{}
+
+	all, err := api.StateTopMiners(ctx, 100, head)
+	require.NoError(t, err)
+	miners, err := api.StateListMiners(ctx, head)
+	require.NoError(t, err)
+	assert.Equal(t, len(miners), all.MinerCount)
+	require.Len(t, all.Miners, all.MinerCount)
+	for i := 1; i < len(all.Miners); i++ {
+		assert.True(t, all.Miners[i-1].QualityAdjPower.GreaterThanEqual(all.Miners[i].QualityAdjPower))
+	}
+
+	mp, err := api.StateMinerPower(ctx, maddr, head)
+	require.NoError(t, err)
+	assert.Equal(t, mp.TotalPower, all.TotalPower)
+	for _, mc := range all.Miners {
+		if mc.Address == maddr {
+			assert.Equal(t, mp.MinerPower, mc.Claim)
+		}
+	}
+
+	t.Run("truncates to the top miners", func(t *testing.T) {
+		top, err := api.StateTopMiners(ctx, 1, head)
+		require.NoError(t, err)
+		assert.Equal(t, all.MinerCount, top.MinerCount)
+		require.Len(t, top.Miners, 1)
+		assert.Equal(t, all.Miners[0], top.Miners[0])
+	})
+
+	t.Run("rejects a negative number of miners", func(t *testing.T) {
+		_, err := api.StateTopMiners(ctx, -1, head)
+		assert.Error(t, err)
+	})
+}
+
+// withoutClaim returns the state root with the power claim of the miner removed, as before the
+// miner was created.
+func withoutClaim(ctx context.Context, t *testing.T, nd *node.Node, root cid.Cid, idAddr address.Address) cid.Cid {
+	cst := nd.Blockstore.CborStore
+	tree, err := vmstate.LoadState(ctx, cst, root)
+	require.NoError(t, err)
+	act, found, err := tree.GetActor(ctx, power.Address)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	store := adt2.WrapStore(ctx, cst)
+	var st power2.State
+	require.NoError(t, store.Get(ctx, act.Head.Cid, &st))
+	claims, err := adt2.AsMap(store, st.Claims)
+	require.NoError(t, err)
+	require.NoError(t, claims.Delete(abi.AddrKey(idAddr)))
+	st.Claims, err = claims.Root()
+	require.NoError(t, err)
+	head, err := store.Put(ctx, &st)
+	require.NoError(t, err)
+
+	act.Head = enccid.NewCid(head)
+	require.NoError(t, tree.SetActor(ctx, power.Address, act))
+	root, err = tree.Flush(ctx)
+	require.NoError(t, err)
+	return root
+}
+
+// extendChain sets the head of nd to a chain on top of it with a tipset at each height of
+// stateRoots, the state after the tipset. The heights missing are null rounds.
+func extendChain(ctx context.Context, t *testing.T, nd *node.Node, stateRoots map[abi.ChainEpoch]cid.Cid, height abi.ChainEpoch) {
+	chainReader := nd.Chain().ChainReader
+	parent, err := chainReader.GetTipSet(chainReader.GetHead())
+	require.NoError(t, err)
+	parentRoot, err := chainReader.GetTipSetStateRoot(parent.Key())
+	require.NoError(t, err)
+	receipts, err := chainReader.GetTipSetReceiptsRoot(parent.Key())
+	require.NoError(t, err)
+
+	for h := parent.EnsureHeight() + 1; h <= height; h++ {
+		root, ok := stateRoots[h]
+		if !ok {
+			continue
+		}
+		blk := &block.Block{
+			Miner:                 parent.At(0).Miner,
+			Ticket:                block.Ticket{VRFProof: crypto.VRFPi{byte(h)}},
+			Parents:               parent.Key(),
+			ParentWeight:          parent.At(0).ParentWeight,
+			Height:                h,
+			ParentStateRoot:       enccid.NewCid(parentRoot),
+			ParentMessageReceipts: enccid.NewCid(receipts),
+			Messages:              parent.At(0).Messages,
+			ParentBaseFee:         parent.At(0).ParentBaseFee,
+		}
+		require.NoError(t, nd.Blockstore.Blockstore.Put(blk.ToNode()))
+		ts, err := block.NewTipSet(blk)
+		require.NoError(t, err)
+		require.NoError(t, chainReader.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+			TipSetStateRoot: root,
+			TipSet:          ts,
+			TipSetReceipts:  receipts,
+		}))
+		parent, parentRoot = ts, root
+	}
+	require.NoError(t, chainReader.SetHead(ctx, parent))
+}
+
+func TestStateMinerPowerHistory(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, maddr := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+	api := nd.Chain().API()
+
+	genesis := nd.Chain().ChainReader.GetHead()
+	current, err := api.StateMinerPower(ctx, maddr, genesis)
+	require.NoError(t, err)
+	require.False(t, current.MinerPower.QualityAdjPower.IsZero())
+	view, err := nd.Chain().State.StateView(genesis)
+	require.NoError(t, err)
+	idAddr, err := view.InitResolveAddress(ctx, maddr)
+	require.NoError(t, err)
+
+	// The miner has no claim until epoch 4, epoch 3 is a null round.
+	root, err := nd.Chain().ChainReader.GetTipSetStateRoot(genesis)
+	require.NoError(t, err)
+	before := withoutClaim(ctx, t, nd, root, idAddr)
+	extendChain(ctx, t, nd, map[abi.ChainEpoch]cid.Cid{1: before, 2: before, 4: root, 5: root}, 5)
+
+	t.Run("samples every step epochs", func(t *testing.T) {
+		samples, err := api.StateMinerPowerHistory(ctx, maddr, 1, 5, 2)
+		require.NoError(t, err)
+		require.Len(t, samples, 3)
+		for i, epoch := range []abi.ChainEpoch{1, 3, 5} {
+			assert.Equal(t, epoch, samples[i].Epoch)
+			assert.Equal(t, current.TotalPower, samples[i].Power.TotalPower)
+		}
+		assert.Equal(t, current, samples[2].Power)
+	})
+
+	t.Run("has a zero claim before the miner exists", func(t *testing.T) {
+		samples, err := api.StateMinerPowerHistory(ctx, maddr, 1, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, samples, 1)
+		assert.True(t, samples[0].Power.MinerPower.RawBytePower.IsZero())
+		assert.True(t, samples[0].Power.MinerPower.QualityAdjPower.IsZero())
+		assert.False(t, samples[0].Power.HasMinPower)
+	})
+
+	t.Run("samples a null round at the tipset before it", func(t *testing.T) {
+		samples, err := api.StateMinerPowerHistory(ctx, maddr, 2, 4, 1)
+		require.NoError(t, err)
+		require.Len(t, samples, 3)
+		assert.Equal(t, abi.ChainEpoch(3), samples[1].Epoch)
+		assert.Equal(t, samples[0].Power, samples[1].Power)
+		assert.True(t, samples[1].Power.MinerPower.QualityAdjPower.IsZero())
+		assert.Equal(t, current, samples[2].Power)
+	})
+
+	t.Run("rejects invalid ranges", func(t *testing.T) {
+		for _, tc := range []struct {
+			name           string
+			from, to, step abi.ChainEpoch
+			err            string
+		}{
+			{"zero step", 1, 5, 0, "step must be positive"},
+			{"negative step", 1, 5, -1, "step must be positive"},
+			{"negative from", -1, 5, 1, "invalid epoch range"},
+			{"from above to", 5, 1, 1, "invalid epoch range"},
+			{"too many samples", 0, 2000, 1, "more than 2000 samples"},
+			{"to above the head", 1, 6, 1, "above the head"},
+		} {
+			_, err := api.StateMinerPowerHistory(ctx, maddr, tc.from, tc.to, tc.step)
+			require.Error(t, err, tc.name)
+			assert.Contains(t, err.Error(), tc.err, tc.name)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/consensus"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/metrics"
+)
+
+// The supply gauges are in FIL, attoFIL amounts overflow int64.
+var (
+	filVestedGa      = metrics.NewFloat64Gauge("chain/fil_vested", "FIL vested at the head")
+	filMinedGa       = metrics.NewFloat64Gauge("chain/fil_mined", "FIL mined at the head")
+	filBurntGa       = metrics.NewFloat64Gauge("chain/fil_burnt", "FIL burnt at the head")
+	filLockedGa      = metrics.NewFloat64Gauge("chain/fil_locked", "FIL locked in pledges and deals at the head")
+	filCirculatingGa = metrics.NewFloat64Gauge("chain/fil_circulating", "FIL circulating at the head")
+)
+
+// circulatingSupply computes the supply in the state after ts.
+func (chain *ChainSubmodule) circulatingSupply(ctx context.Context, ts *block.TipSet) (consensus.CirculatingSupply, error) {
+	st, err := chain.State.GetTipSetState(ctx, ts.Key())
+	if err != nil {
+		return consensus.CirculatingSupply{}, xerrors.Wrapf(err, "failed to load state of %s", ts.Key())
+	}
+	return chain.CirculatingSupply.GetCirculatingSupplyDetailed(ctx, ts.EnsureHeight(), st)
+}
+
+// recordSupply updates the supply gauges with the supply after the new head.
+func (chain *ChainSubmodule) recordSupply(ctx context.Context, head *block.TipSet) {
+	cs, err := chain.circulatingSupply(ctx, head)
+	if err != nil {
+		log.Warnf("failed to compute circulating supply at %s: %s", head.Key(), err)
+		return
+	}
+	filVestedGa.Set(ctx, toFIL(cs.FilVested))
+	filMinedGa.Set(ctx, toFIL(cs.FilMined))
+	filBurntGa.Set(ctx, toFIL(cs.FilBurnt))
+	filLockedGa.Set(ctx, toFIL(cs.FilLocked))
+	filCirculatingGa.Set(ctx, toFIL(cs.FilCirculating))
+}
+
+func toFIL(amt abi.TokenAmount) float64 {
+	fil, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(amt.Int),
+		new(big.Float).SetUint64(constants.FilecoinPrecision),
+	).Float64()
+	return fil
+}
+
+// StateCirculatingSupply returns the FIL circulating in the state after the tipset, the head
+// when the key is empty.
+func (chainAPI *ChainAPI) StateCirculatingSupply(ctx context.Context, key block.TipSetKey) (abi.TokenAmount, error) {
+	ts, _, err := chainAPI.chain.tipSetView(key)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	cs, err := chainAPI.chain.circulatingSupply(ctx, ts)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	return cs.FilCirculating, nil
+}
+
+// StateVMCirculatingSupplyInternal returns the vested, mined, burnt, locked and circulating FIL
+// as computed by the VM in the state after the tipset, the head when the key is empty.
+func (chainAPI *ChainAPI) StateVMCirculatingSupplyInternal(ctx context.Context, key block.TipSetKey) (consensus.CirculatingSupply, error) {
+	ts, _, err := chainAPI.chain.tipSetView(key)
+	if err != nil {
+		return consensus.CirculatingSupply{}, err
+	}
+	return chainAPI.chain.circulatingSupply(ctx, ts)
+}
@@ -0,0 +1,54 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestCirculatingSupply(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, _ := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+	api := nd.Chain().API()
+
+	detailed, err := api.StateVMCirculatingSupplyInternal(ctx, block.TipSetKey{})
+	require.NoError(t, err)
+	for _, amt := range []big.Int{detailed.FilVested, detailed.FilMined, detailed.FilBurnt, detailed.FilLocked, detailed.FilCirculating} {
+		assert.True(t, amt.GreaterThanEqual(big.Zero()))
+	}
+	// The genesis miner locked its pledge.
+	assert.True(t, detailed.FilLocked.GreaterThan(big.Zero()))
+	assert.True(t, detailed.FilCirculating.LessThanEqual(big.Add(detailed.FilVested, detailed.FilMined)))
+
+	t.Run("circulating supply at the head", func(t *testing.T) {
+		circulating, err := api.StateCirculatingSupply(ctx, block.TipSetKey{})
+		require.NoError(t, err)
+		assert.Equal(t, detailed.FilCirculating, circulating)
+	})
+
+	t.Run("circulating supply at a tipset", func(t *testing.T) {
+		head := nd.Chain().ChainReader.GetHead()
+		circulating, err := api.StateCirculatingSupply(ctx, head)
+		require.NoError(t, err)
+		assert.Equal(t, detailed.FilCirculating, circulating)
+
+		atHead, err := api.StateVMCirculatingSupplyInternal(ctx, head)
+		require.NoError(t, err)
+		assert.Equal(t, detailed, atHead)
+	})
+
+	t.Run("unknown tipset", func(t *testing.T) {
+		_, err := api.StateCirculatingSupply(ctx, block.NewTipSetKey(types.EmptyMessagesCID))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to load tipset")
+	})
+}
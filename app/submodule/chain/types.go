@@ -2,6 +2,8 @@ package chain
 
 import (
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/filecoin-project/venus/pkg/fork"
 	"time"
 )
 
@@ -16,4 +18,34 @@ type ProtocolParams struct {
 	Network          string
 	BlockTime        time.Duration
 	SupportedSectors []SectorInfo
+	// BlockDelaySecs is the duration of an epoch in seconds
+	BlockDelaySecs uint64
+	// NetworkVersion is the network version at the head
+	NetworkVersion      network.Version
+	BeaconSchedule      []BeaconSchedulePoint
+	SupportedSealProofs []abi.RegisteredSealProof
+	// GasPricelistEpoch is the epoch from which the gas prices used at the head apply
+	GasPricelistEpoch abi.ChainEpoch
+}
+
+// BeaconSchedulePoint is a drand network used for randomness from an epoch on
+type BeaconSchedulePoint struct {
+	Start   abi.ChainEpoch
+	Network string
+	Servers []string
+}
+
+// NetworkUpgrade is an upgrade of the network to a new version at a height
+type NetworkUpgrade struct {
+	Height  abi.ChainEpoch
+	Network network.Version
+	Name    string
+	// HasMigration is set when the upgrade migrates the state
+	HasMigration bool
+	// Expensive is set when the migration takes long to run
+	Expensive bool
+	// HasPreMigration is set when the migration runs ahead of the upgrade in the background, in
+	// the PreMigrations windows. No migration has a pre-migration yet.
+	HasPreMigration bool
+	PreMigrations   []fork.PreMigration `json:",omitempty"`
 }
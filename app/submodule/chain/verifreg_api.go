@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/verifreg"
+)
+
+// VerifregStateAPI reads the state of the verified registry actor at a tipset, the
+// head when the tipset key is empty.
+type VerifregStateAPI struct {
+	chain *ChainSubmodule
+}
+
+// DataCap is the remaining datacap of a verifier or a verified client.
+type DataCap struct {
+	Address address.Address
+	DataCap abi.StoragePower
+}
+
+func (verifregStateAPI *VerifregStateAPI) loadVerifreg(ctx context.Context, key block.TipSetKey) (verifreg.State, error) {
+	_, view, err := verifregStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	verifregState, err := view.LoadVerifregActor(ctx)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to load verified registry actor")
+	}
+	return verifregState, nil
+}
+
+// StateVerifiedRegistryRootKey returns the address of the root key, the only
+// address allowed to add and remove verifiers.
+func (verifregStateAPI *VerifregStateAPI) StateVerifiedRegistryRootKey(ctx context.Context, key block.TipSetKey) (address.Address, error) {
+	verifregState, err := verifregStateAPI.loadVerifreg(ctx, key)
+	if err != nil {
+		return address.Undef, err
+	}
+	return verifregState.RootKey()
+}
+
+// StateVerifierStatus returns the datacap a verifier can still grant to clients,
+// nil when addr is not a verifier.
+func (verifregStateAPI *VerifregStateAPI) StateVerifierStatus(ctx context.Context, addr address.Address, key block.TipSetKey) (*abi.StoragePower, error) {
+	_, view, err := verifregStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	return view.StateVerifierStatus(ctx, addr)
+}
+
+// StateVerifiedClientStatus returns the datacap a verified client can still use in
+// deals, nil when addr is not a verified client.
+func (verifregStateAPI *VerifregStateAPI) StateVerifiedClientStatus(ctx context.Context, addr address.Address, key block.TipSetKey) (*abi.StoragePower, error) {
+	_, view, err := verifregStateAPI.chain.tipSetView(key)
+	if err != nil {
+		return nil, err
+	}
+	return view.StateVerifiedClientStatus(ctx, addr)
+}
+
+// StateListVerifiers returns the verifiers with their remaining datacap.
+func (verifregStateAPI *VerifregStateAPI) StateListVerifiers(ctx context.Context, key block.TipSetKey) ([]DataCap, error) {
+	verifregState, err := verifregStateAPI.loadVerifreg(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var out []DataCap
+	err = verifregState.ForEachVerifier(func(addr address.Address, dcap abi.StoragePower) error {
+		out = append(out, DataCap{Address: addr, DataCap: dcap})
+		return nil
+	})
+	return out, err
+}
+
+// StateListVerifiedClients returns the verified clients with their remaining datacap.
+func (verifregStateAPI *VerifregStateAPI) StateListVerifiedClients(ctx context.Context, key block.TipSetKey) ([]DataCap, error) {
+	verifregState, err := verifregStateAPI.loadVerifreg(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var out []DataCap
+	err = verifregState.ForEachClient(func(addr address.Address, dcap abi.StoragePower) error {
+		out = append(out, DataCap{Address: addr, DataCap: dcap})
+		return nil
+	})
+	return out, err
+}
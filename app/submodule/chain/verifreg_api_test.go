@@ -0,0 +1,57 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestVerifregStateAPI(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	nd, maddr := newMinerNode(ctx, t)
+	defer nd.Stop(ctx)
+	api := nd.Chain().API()
+	head := block.TipSetKey{}
+
+	t.Run("root key", func(t *testing.T) {
+		rootKey, err := api.StateVerifiedRegistryRootKey(ctx, head)
+		require.NoError(t, err)
+		// The genesis generator makes a secp256k1 key the root key.
+		assert.Equal(t, address.SECP256K1, rootKey.Protocol())
+	})
+
+	t.Run("genesis has no verifiers nor verified clients", func(t *testing.T) {
+		verifiers, err := api.StateListVerifiers(ctx, head)
+		require.NoError(t, err)
+		assert.Empty(t, verifiers)
+		clients, err := api.StateListVerifiedClients(ctx, head)
+		require.NoError(t, err)
+		assert.Empty(t, clients)
+	})
+
+	t.Run("datacap is nil for addresses not in the registry", func(t *testing.T) {
+		dcap, err := api.StateVerifierStatus(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.Nil(t, dcap)
+		dcap, err = api.StateVerifiedClientStatus(ctx, maddr, head)
+		require.NoError(t, err)
+		assert.Nil(t, dcap)
+	})
+
+	t.Run("fails for an address without an actor", func(t *testing.T) {
+		// The root key has never sent a message, it has no actor.
+		rootKey, err := api.StateVerifiedRegistryRootKey(ctx, head)
+		require.NoError(t, err)
+		_, err = api.StateVerifierStatus(ctx, rootKey, head)
+		assert.Error(t, err)
+		_, err = api.StateVerifiedClientStatus(ctx, rootKey, head)
+		assert.Error(t, err)
+	})
+}
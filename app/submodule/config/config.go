@@ -1,14 +1,22 @@
 package config
 
 import (
-	repo2 "github.com/filecoin-project/venus/pkg/repo"
+	"encoding/json"
 	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	repo2 "github.com/filecoin-project/venus/pkg/repo"
 )
 
 // ConfigModule is plumbing implementation for setting and retrieving values from local config.
 type ConfigModule struct { //nolint
 	repo repo2.Repo
 	lock sync.Mutex
+
+	validators []func(*config.Config) error
+	onChange   []func(*config.Config) error
 }
 
 // NewConfig returns a new ConfigModule.
@@ -16,17 +24,70 @@ func NewConfigModule(repo repo2.Repo) *ConfigModule {
 	return &ConfigModule{repo: repo}
 }
 
+// OnValidate registers a function checking a new config, the config is not
+// saved when it returns an error.
+func (s *ConfigModule) OnValidate(f func(*config.Config) error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.validators = append(s.validators, f)
+}
+
+// OnChange registers a function applying the config at runtime once a new
+// value is saved.
+func (s *ConfigModule) OnChange(f func(*config.Config) error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onChange = append(s.onChange, f)
+}
+
 // Set sets a value in config
 func (s *ConfigModule) Set(dottedKey string, jsonString string) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	// Check the value on a copy so a rejected value is not left in the live
+	// config.
 	cfg := s.repo.Config()
+	candidate, err := copyConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := candidate.Set(dottedKey, jsonString); err != nil {
+		return err
+	}
+	for _, f := range s.validators {
+		if err := f(candidate); err != nil {
+			return err
+		}
+	}
+
+	// The live config is updated in place, the submodules holding parts of it
+	// see the new value.
 	if err := cfg.Set(dottedKey, jsonString); err != nil {
 		return err
 	}
+	if err := s.repo.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+
+	for _, f := range s.onChange {
+		if err := f(cfg); err != nil {
+			return errors.Wrap(err, "config saved but not applied")
+		}
+	}
+	return nil
+}
 
-	return s.repo.ReplaceConfig(cfg)
+func copyConfig(cfg *config.Config) (*config.Config, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	out := &config.Config{}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 // Get gets a value from config
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+
 	repo2 "github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/types"
 	"testing"
@@ -115,3 +117,73 @@ func TestConfigSet(t *testing.T) {
 	})
 
 }
+
+func TestConfigOnChange(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("applies the saved config in place", func(t *testing.T) {
+		repo := repo2.NewInMemoryRepo()
+		cfgAPI := NewConfigModule(repo)
+		mpool := repo.Config().Mpool
+
+		var applied *config.Config
+		cfgAPI.OnChange(func(cfg *config.Config) error {
+			applied = cfg
+			return nil
+		})
+
+		require.NoError(t, cfgAPI.Set("mpool.maxPoolSize", "42"))
+		require.NotNil(t, applied)
+		assert.Equal(t, repo.Config(), applied)
+		// the holders of a part of the config see the new value
+		assert.Equal(t, 42, int(mpool.MaxPoolSize))
+	})
+
+	t.Run("a rejected config is neither saved nor applied", func(t *testing.T) {
+		repo := repo2.NewInMemoryRepo()
+		cfgAPI := NewConfigModule(repo)
+		before := repo.Config().Mpool.MaxPoolSize
+
+		cfgAPI.OnValidate(func(cfg *config.Config) error {
+			assert.Equal(t, 42, int(cfg.Mpool.MaxPoolSize))
+			return errors.New("rejected")
+		})
+		applied := false
+		cfgAPI.OnChange(func(cfg *config.Config) error {
+			applied = true
+			return nil
+		})
+
+		assert.EqualError(t, cfgAPI.Set("mpool.maxPoolSize", "42"), "rejected")
+		assert.False(t, applied)
+		assert.Equal(t, before, repo.Config().Mpool.MaxPoolSize)
+	})
+
+	t.Run("an invalid value is not applied", func(t *testing.T) {
+		repo := repo2.NewInMemoryRepo()
+		cfgAPI := NewConfigModule(repo)
+
+		applied := false
+		cfgAPI.OnChange(func(cfg *config.Config) error {
+			applied = true
+			return nil
+		})
+
+		assert.Error(t, cfgAPI.Set("mpool.maxPoolSize", `"many"`))
+		assert.False(t, applied)
+	})
+
+	t.Run("an apply failure is reported once saved", func(t *testing.T) {
+		repo := repo2.NewInMemoryRepo()
+		cfgAPI := NewConfigModule(repo)
+
+		cfgAPI.OnChange(func(cfg *config.Config) error {
+			return errors.New("apply failed")
+		})
+
+		err := cfgAPI.Set("mpool.maxPoolSize", "42")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "apply failed")
+		assert.Equal(t, 42, int(repo.Config().Mpool.MaxPoolSize))
+	})
+}
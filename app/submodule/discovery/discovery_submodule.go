@@ -4,6 +4,8 @@ import (
 	"context"
 	"github.com/filecoin-project/venus/app/submodule/network"
 	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/routing"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -34,7 +36,17 @@ type DiscoverySubmodule struct { //nolint
 	// HelloHandler handle peer connections for the "hello" protocol.
 	ExchangeHandler exchange.Server
 
+	// MDNS and Rendezvous find the local and DHT advertised nodes of the
+	// network when enabled in the bootstrap config, nil otherwise.
+	MDNS       *discovery.MDNSDiscovery
+	Rendezvous *discovery.RendezvousDiscovery
+
 	host                   host.Host
+	router                 routing.Routing
+	genesis                cid.Cid
+	networkName            string
+	bsConfig               *config.BootstrapConfig
+	cancelLocal            context.CancelFunc
 	PeerDiscoveryCallbacks []discovery.PeerDiscoveredCallback
 	TipSetLoader           discovery.GetTipSetFunc
 }
@@ -74,8 +86,12 @@ func NewDiscoverySubmodule(ctx context.Context,
 
 	bootStrapReady := moresync.NewLatch(uint(minPeerThreshold))
 
-	return &DiscoverySubmodule{
+	discoverySubmodule := &DiscoverySubmodule{
 		host:            network.Host,
+		router:          network.Router,
+		genesis:         config.GenesisCid(),
+		networkName:     network.NetworkName,
+		bsConfig:        bsConfig,
 		Bootstrapper:    bootstrapper,
 		BootstrapReady:  bootStrapReady,
 		PeerTracker:     peerTracker,
@@ -89,7 +105,9 @@ func NewDiscoverySubmodule(ctx context.Context,
 			head := chainStore.GetHead()
 			return chainStore.GetTipSet(head)
 		},
-	}, nil
+	}
+	network.Network.SetChainInfoSource(discoverySubmodule.PeerChainInfo)
+	return discoverySubmodule, nil
 }
 
 // Start starts the discovery submodule for a node.  It blocks until bootstrap
@@ -114,14 +132,82 @@ func (discovery *DiscoverySubmodule) Start() error {
 	//registre exchange protocol
 	discovery.ExchangeHandler.Register()
 
+	// The peers found are connected to, the hello protocol then tracks them.
+	var ctx context.Context
+	ctx, discovery.cancelLocal = context.WithCancel(context.Background())
+	md, rd, err := startLocalDiscovery(ctx, discovery.host, discovery.router, discovery.networkName, discovery.bsConfig)
+	if err != nil {
+		return err
+	}
+	discovery.MDNS, discovery.Rendezvous = md, rd
+
 	// Wait for bootstrap to be sufficient connected
 	discovery.BootstrapReady.Wait()
 	return nil
 }
 
+// startLocalDiscovery starts the mDNS and rendezvous discoveries enabled in
+// the bootstrap config.
+func startLocalDiscovery(ctx context.Context, h host.Host, router routing.Routing, networkName string, bsConfig *config.BootstrapConfig) (*discovery.MDNSDiscovery, *discovery.RendezvousDiscovery, error) {
+	var md *discovery.MDNSDiscovery
+	if bsConfig.MDNS {
+		interval := discovery.DefaultMDNSInterval
+		if bsConfig.MDNSInterval != "" {
+			var err error
+			interval, err = time.ParseDuration(bsConfig.MDNSInterval)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "couldn't parse mdns interval %s", bsConfig.MDNSInterval)
+			}
+		}
+		var err error
+		md, err = discovery.NewMDNSDiscovery(ctx, h, interval, networkName)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to start mdns discovery")
+		}
+	}
+
+	var rd *discovery.RendezvousDiscovery
+	if bsConfig.Rendezvous {
+		rd = discovery.NewRendezvousDiscovery(h, router, networkName, discovery.RendezvousPeriod)
+		rd.Start(ctx)
+	}
+	return md, rd, nil
+}
+
 // Stop stops the discovery submodule.
 func (discovery *DiscoverySubmodule) Stop() {
 	discovery.Bootstrapper.Stop()
+	if discovery.MDNS != nil {
+		if err := discovery.MDNS.Close(); err != nil {
+			log.Warnf("failed to stop mdns discovery: %s", err)
+		}
+	}
+	if discovery.Rendezvous != nil {
+		discovery.Rendezvous.Stop()
+	}
+	if discovery.cancelLocal != nil {
+		discovery.cancelLocal()
+	}
+}
+
+// PeerChainInfo returns the chain a peer claimed in the hello protocol, the
+// head is only known for the peers with our genesis.
+func (discovery *DiscoverySubmodule) PeerChainInfo(p peer.ID) (*net.PeerChainInfo, bool) {
+	genesis, hello := discovery.HelloHandler.PeerGenesis(p)
+	ci, tracked := discovery.PeerTracker.Get(p)
+	if !hello && !tracked {
+		return nil, false
+	}
+
+	// only the peers with our genesis are tracked
+	out := &net.PeerChainInfo{
+		GenesisMatch: tracked || genesis.Equals(discovery.genesis),
+	}
+	if tracked {
+		out.Head = ci.Head.ToSlice()
+		out.Height = ci.Height
+	}
+	return out, true
 }
 
 func (discovery *DiscoverySubmodule) API() *DiscoveryAPI {
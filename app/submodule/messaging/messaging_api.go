@@ -7,6 +7,10 @@ import (
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/message"
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/multisig"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/verifreg"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/util/moresync"
 	"github.com/ipfs/go-cid"
@@ -16,6 +20,17 @@ import (
 
 var MsgNotfound = xerrors.New("message not found")
 
+// InvocResult is the outcome of a message applied with StateCall.
+type InvocResult struct {
+	Msg            *types.UnsignedMessage
+	MsgRct         *types.MessageReceipt
+	Return         interface{}
+	GasUsed        types.Unit
+	ExecutionTrace types.ExecutionTrace
+	Error          string
+	Duration       time.Duration
+}
+
 type MessagingAPI struct { //nolint
 	messaging *MessagingSubmodule
 }
@@ -90,10 +105,85 @@ func (messagingAPI *MessagingAPI) MessagePoolRemove(cid cid.Cid) {
 	messagingAPI.messaging.MsgPool.Remove(cid)
 }
 
-// MessagePreview previews the Gas cost of a message by running it locally on the client and
-// recording the amount of Gas used.
-func (messagingAPI *MessagingAPI) MessagePreview(ctx context.Context, from, to address.Address, method abi.MethodNum, params ...interface{}) (types.Unit, error) {
-	return messagingAPI.messaging.Previewer.Preview(ctx, from, to, method, params...)
+// MessagePreview previews the Gas cost of a message with params already encoded, e.g. by
+// ChainAPI.StateEncodeParams, by applying it to the state of the head and recording the amount
+// of Gas used.
+func (messagingAPI *MessagingAPI) MessagePreview(ctx context.Context, from, to address.Address, value types.AttoFIL, method abi.MethodNum, params []byte) (types.Unit, error) {
+	res, err := messagingAPI.StateCall(ctx, &types.UnsignedMessage{
+		From:   from,
+		To:     to,
+		Value:  value,
+		Method: method,
+		Params: params,
+	}, block.TipSetKey{})
+	if err != nil {
+		return types.NewGas(0), err
+	}
+	if res.Error != "" {
+		return types.NewGas(0), xerrors.Errorf("message fails with exit code %s", res.Error)
+	}
+	return res.GasUsed, nil
+}
+
+// StateCall applies the message to the parent state of the tipset specified by tsk, or of the
+// head if tsk is empty, without checking its signature or nonce. The resulting state is discarded.
+// The return value is decoded with the method signature of the receiving actor where possible.
+func (messagingAPI *MessagingAPI) StateCall(ctx context.Context, msg *types.UnsignedMessage, tsk block.TipSetKey) (*InvocResult, error) {
+	if tsk.Empty() {
+		tsk = messagingAPI.messaging.chainReader.GetHead()
+	}
+	ts, err := messagingAPI.messaging.chainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	start := time.Now()
+	ret, err := messagingAPI.messaging.consensus.Call(ctx, msg, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("call message: %w", err)
+	}
+	duration := time.Since(start)
+
+	res := &InvocResult{
+		Msg:            msg,
+		MsgRct:         &ret.Receipt,
+		GasUsed:        ret.Receipt.GasUsed,
+		ExecutionTrace: ret.GasTracker.ExecutionTrace,
+		Duration:       duration,
+	}
+	res.ExecutionTrace.Msg = msg
+	res.ExecutionTrace.MsgRct = &ret.Receipt
+	res.ExecutionTrace.Duration = duration
+
+	if ret.Receipt.ExitCode.IsError() {
+		res.Error = ret.Receipt.ExitCode.String()
+		res.ExecutionTrace.Error = res.Error
+		return res, nil
+	}
+
+	if len(ret.Receipt.ReturnValue) > 0 {
+		res.Return = messagingAPI.decodeReturn(ctx, ts, msg, ret.Receipt.ReturnValue)
+	}
+	return res, nil
+}
+
+// decodeReturn decodes a method return value with the signature of the receiving actor in the
+// state the call was applied to, and falls back to the raw bytes if that is not possible.
+func (messagingAPI *MessagingAPI) decodeReturn(ctx context.Context, ts *block.TipSet, msg *types.UnsignedMessage, raw []byte) interface{} {
+	parents, err := ts.Parents()
+	if err != nil || parents.Empty() {
+		return raw
+	}
+
+	signature, err := messagingAPI.messaging.chainState.GetActorSignatureAt(ctx, parents, msg.To, msg.Method)
+	if err != nil {
+		return raw
+	}
+	decoded, err := signature.ReturnInterface(raw)
+	if err != nil {
+		return raw
+	}
+	return decoded
 }
 
 // MessageSend sends a message. It uses the default from address if none is given and signs the
@@ -110,6 +200,79 @@ func (messagingAPI *MessagingAPI) MessageSend(ctx context.Context, from, to addr
 	return msgCid, nil
 }
 
+// MessageSendEncoded sends a message with params already encoded, e.g. by ChainAPI.StateEncodeParams.
+func (messagingAPI *MessagingAPI) MessageSendEncoded(ctx context.Context, from, to address.Address, value types.AttoFIL, baseFee types.AttoFIL, gasPremium types.AttoFIL, gasLimit types.Unit, method abi.MethodNum, params []byte) (cid.Cid, error) {
+	msgCid, _, err := messagingAPI.messaging.Outbox.SendEncoded(ctx, from, to, value, baseFee, gasPremium, gasLimit, true, method, params)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return msgCid, nil
+}
+
+// MarketAddBalance sends a message from the wallet address from adding amt to the market escrow
+// of addr, a storage client or provider.
+func (messagingAPI *MessagingAPI) MarketAddBalance(ctx context.Context, from, addr address.Address, amt types.AttoFIL, baseFee types.AttoFIL, gasPremium types.AttoFIL, gasLimit types.Unit) (cid.Cid, error) {
+	return messagingAPI.MessageSend(ctx, from, market.Address, amt, baseFee, gasPremium, gasLimit, market.Methods.AddBalance, &addr)
+}
+
+// MarketWithdrawBalance sends a message from the wallet address from withdrawing amt from the
+// market escrow of addr to its owner. Only the part of the escrow not locked in deals is withdrawn.
+func (messagingAPI *MessagingAPI) MarketWithdrawBalance(ctx context.Context, from, addr address.Address, amt types.AttoFIL, baseFee types.AttoFIL, gasPremium types.AttoFIL, gasLimit types.Unit) (cid.Cid, error) {
+	params := &market.WithdrawBalanceParams{
+		ProviderOrClientAddress: addr,
+		Amount:                  amt,
+	}
+	return messagingAPI.MessageSend(ctx, from, market.Address, types.ZeroAttoFIL, baseFee, gasPremium, gasLimit, market.Methods.WithdrawBalance, params)
+}
+
+// VerifregAddVerifier proposes to the multisig root key of the verified registry, from one of
+// its signers, to make verifier a verifier able to grant allowance bytes of datacap. The
+// proposal is executed once approved by enough signers.
+func (messagingAPI *MessagingAPI) VerifregAddVerifier(ctx context.Context, from, verifier address.Address, allowance verifreg.DataCap, baseFee types.AttoFIL, gasPremium types.AttoFIL, gasLimit types.Unit) (cid.Cid, error) {
+	head, err := messagingAPI.messaging.chainReader.GetTipSet(messagingAPI.messaging.chainReader.GetHead())
+	if err != nil {
+		return cid.Undef, err
+	}
+	view, err := messagingAPI.messaging.chainState.StateView(head.Key())
+	if err != nil {
+		return cid.Undef, err
+	}
+	verifregState, err := view.LoadVerifregActor(ctx)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to load verified registry actor: %w", err)
+	}
+	rootKey, err := verifregState.RootKey()
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msg, err := verifreg.AddVerifier(rootKey, verifier, allowance)
+	if err != nil {
+		return cid.Undef, err
+	}
+	nv := messagingAPI.messaging.fork.GetNtwkVersion(ctx, head.EnsureHeight())
+	proposal, err := multisig.Message(specactors.VersionForNetwork(nv), from).Propose(rootKey, msg.To, msg.Value, msg.Method, msg.Params)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return messagingAPI.sendBuilt(ctx, proposal, baseFee, gasPremium, gasLimit)
+}
+
+// VerifregAddVerifiedClient sends a message from the verifier granting client allowance bytes of
+// datacap, taken from the datacap of the verifier.
+func (messagingAPI *MessagingAPI) VerifregAddVerifiedClient(ctx context.Context, verifier, client address.Address, allowance verifreg.DataCap, baseFee types.AttoFIL, gasPremium types.AttoFIL, gasLimit types.Unit) (cid.Cid, error) {
+	msg, err := verifreg.AddVerifiedClient(verifier, client, allowance)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return messagingAPI.sendBuilt(ctx, msg, baseFee, gasPremium, gasLimit)
+}
+
+// sendBuilt sends a message built by an actor message builder.
+func (messagingAPI *MessagingAPI) sendBuilt(ctx context.Context, msg *types.UnsignedMessage, baseFee types.AttoFIL, gasPremium types.AttoFIL, gasLimit types.Unit) (cid.Cid, error) {
+	return messagingAPI.MessageSendEncoded(ctx, msg.From, msg.To, msg.Value, baseFee, gasPremium, gasLimit, msg.Method, msg.Params)
+}
+
 //SignedMessageSend sends a siged message.
 func (messagingAPI *MessagingAPI) SignedMessageSend(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error) {
 	msgCid, pubCh, err := messagingAPI.messaging.Outbox.SignedSend(ctx, smsg, true)
@@ -0,0 +1,183 @@
+package messaging_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	multisig2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/multisig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node/test"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/encoding"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/account"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/multisig"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/verifreg"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestStateCall(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	seed, cfg, chainClock := test.CreateBootstrapSetup(t)
+	nd := test.CreateBootstrapMiner(ctx, t, seed, chainClock, cfg)
+	defer nd.Stop(ctx)
+	_, owner := seed.GiveMiner(t, nd, 0)
+	api := nd.Messaging.API()
+	chainReader := nd.Chain().ChainReader
+
+	head := chainReader.GetHead()
+	headRoot, err := chainReader.GetTipSetStateRoot(head)
+	require.NoError(t, err)
+	genesis, err := chainReader.GetTipSet(head)
+	require.NoError(t, err)
+
+	// The return value is decoded in the parent state of the tipset, so call on a child of genesis.
+	child := &block.Block{
+		Miner:                 genesis.At(0).Miner,
+		Parents:               head,
+		ParentWeight:          genesis.At(0).ParentWeight,
+		Height:                genesis.EnsureHeight() + 1,
+		ParentStateRoot:       genesis.At(0).ParentStateRoot,
+		ParentMessageReceipts: genesis.At(0).ParentMessageReceipts,
+		Messages:              genesis.At(0).Messages,
+		ParentBaseFee:         genesis.At(0).ParentBaseFee,
+	}
+	require.NoError(t, nd.Blockstore.Blockstore.Put(child.ToNode()))
+	childKey := block.NewTipSetKey(child.Cid())
+
+	pubkeyAddress := &types.UnsignedMessage{
+		From:   owner,
+		To:     owner,
+		Value:  types.ZeroAttoFIL,
+		Method: account.Methods.PubkeyAddress,
+	}
+
+	t.Run("decodes the return value on a tipset", func(t *testing.T) {
+		res, err := api.StateCall(ctx, pubkeyAddress, childKey)
+		require.NoError(t, err)
+		assert.Empty(t, res.Error)
+		assert.Equal(t, exitcode.Ok, res.MsgRct.ExitCode)
+		assert.True(t, res.GasUsed > 0)
+		assert.Equal(t, pubkeyAddress, res.ExecutionTrace.Msg)
+
+		ret, ok := res.Return.(**address.Address)
+		require.True(t, ok, "return value %T", res.Return)
+		assert.Equal(t, owner, **ret)
+	})
+
+	t.Run("calls on the head", func(t *testing.T) {
+		res, err := api.StateCall(ctx, pubkeyAddress, block.TipSetKey{})
+		require.NoError(t, err)
+		assert.Empty(t, res.Error)
+		// Genesis has no parent state to find the method signature in, the raw bytes are returned.
+		assert.Equal(t, res.MsgRct.ReturnValue, res.Return)
+	})
+
+	t.Run("returns the receipt of a failing call", func(t *testing.T) {
+		unknownMethod := *pubkeyAddress
+		unknownMethod.Method = 9999
+		res, err := api.StateCall(ctx, &unknownMethod, block.TipSetKey{})
+		require.NoError(t, err)
+		require.NotNil(t, res.MsgRct)
+		assert.Equal(t, exitcode.SysErrInvalidMethod, res.MsgRct.ExitCode)
+		assert.Equal(t, res.MsgRct.ExitCode.String(), res.Error)
+		assert.Equal(t, res.Error, res.ExecutionTrace.Error)
+		assert.Nil(t, res.Return)
+	})
+
+	t.Run("does not persist the state", func(t *testing.T) {
+		assert.Equal(t, head, chainReader.GetHead())
+		root, err := chainReader.GetTipSetStateRoot(head)
+		require.NoError(t, err)
+		assert.Equal(t, headRoot, root)
+
+		// The sender's nonce is unchanged, a second call applies on the same state.
+		first, err := api.StateCall(ctx, pubkeyAddress, block.TipSetKey{})
+		require.NoError(t, err)
+		second, err := api.StateCall(ctx, pubkeyAddress, block.TipSetKey{})
+		require.NoError(t, err)
+		assert.Equal(t, first.MsgRct, second.MsgRct)
+	})
+
+	t.Run("fails for an unknown tipset", func(t *testing.T) {
+		_, err := api.StateCall(ctx, pubkeyAddress, block.NewTipSetKey(types.EmptyMessagesCID))
+		assert.Error(t, err)
+	})
+}
+
+func TestMessagePreview(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	seed, cfg, chainClock := test.CreateBootstrapSetup(t)
+	nd := test.CreateBootstrapMiner(ctx, t, seed, chainClock, cfg)
+	defer nd.Stop(ctx)
+	maddr, owner := seed.GiveMiner(t, nd, 0)
+	api := nd.Messaging.API()
+
+	t.Run("previews the gas used by the message with its params", func(t *testing.T) {
+		params, err := encoding.Encode(&maddr)
+		require.NoError(t, err)
+		gas, err := api.MessagePreview(ctx, owner, market.Address, types.NewAttoFILFromFIL(1), market.Methods.AddBalance, params)
+		require.NoError(t, err)
+		assert.True(t, gas > 0)
+	})
+
+	t.Run("fails when the message fails with its params", func(t *testing.T) {
+		_, err := api.MessagePreview(ctx, owner, market.Address, types.NewAttoFILFromFIL(1), market.Methods.AddBalance, []byte{0xff})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit code")
+	})
+}
+
+func TestVerifregAddVerifier(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	seed, cfg, chainClock := test.CreateBootstrapSetup(t)
+	nd := test.CreateBootstrapMiner(ctx, t, seed, chainClock, cfg)
+	defer nd.Stop(ctx)
+	_, owner := seed.GiveMiner(t, nd, 0)
+	api := nd.Messaging.API()
+
+	verifier, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	allowance := abi.NewStoragePower(1 << 30)
+	rootKey, err := nd.Chain().API().StateVerifiedRegistryRootKey(ctx, block.TipSetKey{})
+	require.NoError(t, err)
+
+	t.Run("proposes adding the verifier to the root key", func(t *testing.T) {
+		_, err := api.VerifregAddVerifier(ctx, owner, verifier, allowance, types.NewGasFeeCap(1), types.NewGasPremium(1), types.NewGas(1000000))
+		require.NoError(t, err)
+		queued := api.OutboxQueueLs(owner)
+		require.Len(t, queued, 1)
+		proposal := queued[0].Msg.Message
+		assert.Equal(t, owner, proposal.From)
+		assert.Equal(t, rootKey, proposal.To)
+		assert.Equal(t, multisig.Methods.Propose, proposal.Method)
+		assert.True(t, proposal.Value.IsZero())
+
+		var params multisig2.ProposeParams
+		require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(proposal.Params)))
+		assert.Equal(t, verifreg.Address, params.To)
+		assert.Equal(t, verifreg.Methods.AddVerifier, params.Method)
+		assert.True(t, params.Value.IsZero())
+
+		var addVerifier verifreg.AddVerifierParams
+		require.NoError(t, addVerifier.UnmarshalCBOR(bytes.NewReader(params.Params)))
+		assert.Equal(t, verifier, addVerifier.Address)
+		assert.True(t, allowance.Equals(addVerifier.Allowance))
+	})
+
+	t.Run("rejects a non-positive allowance", func(t *testing.T) {
+		_, err := api.VerifregAddVerifier(ctx, owner, verifier, abi.NewStoragePower(0), types.NewGasFeeCap(1), types.NewGasPremium(1), types.NewGas(1000000))
+		assert.Error(t, err)
+		assert.Len(t, api.OutboxQueueLs(owner), 1)
+	})
+}
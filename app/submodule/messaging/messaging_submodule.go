@@ -5,6 +5,7 @@ import (
 
 	"github.com/filecoin-project/venus/app/submodule/blockstore"
 	chainModule "github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/app/submodule/chain/cst"
 	"github.com/filecoin-project/venus/app/submodule/messaging/msg"
 	"github.com/filecoin-project/venus/app/submodule/network"
 	"github.com/filecoin-project/venus/app/submodule/syncer"
@@ -13,6 +14,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/consensus"
+	"github.com/filecoin-project/venus/pkg/fork"
 	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/message"
 	"github.com/filecoin-project/venus/pkg/net/msgsub"
@@ -43,6 +45,9 @@ type MessagingSubmodule struct { //nolint
 	MsgSigVal *consensus.MessageSignatureValidator
 
 	chainReader chainReader
+	chainState  *cst.ChainStateReadWriter
+	consensus   consensus.Protocol
+	fork        fork.IFork
 }
 
 type messagingConfig interface {
@@ -79,7 +84,8 @@ func NewMessagingSubmodule(ctx context.Context,
 	// setup messaging topic.
 	// register block validation on pubsub
 	mtv := msgsub.NewMessageTopicValidator(msgSyntaxValidator, msgSignatureValidator)
-	if err := network.Pubsub.RegisterTopicValidator(mtv.Topic(network.NetworkName), mtv.Validator(), mtv.Opts()...); err != nil {
+	msgTopic := mtv.Topic(network.NetworkName)
+	if err := network.Pubsub.RegisterTopicValidator(msgTopic, network.ScoreKeeper.Validator(msgTopic, mtv.Validator()), mtv.Opts()...); err != nil {
 		return nil, errors.Wrap(err, "failed to register message validator")
 	}
 	topic, err := network.Pubsub.Join(msgsub.Topic(network.NetworkName))
@@ -104,6 +110,9 @@ func NewMessagingSubmodule(ctx context.Context,
 		MsgPool:     msgPool,
 		MsgSigVal:   msgSignatureValidator,
 		chainReader: chain.ChainReader,
+		chainState:  chain.State,
+		consensus:   syncer.Consensus,
+		fork:        chain.Fork,
 		Waiter:      waiter,
 		Previewer:   previewer,
 	}, nil
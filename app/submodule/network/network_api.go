@@ -2,6 +2,9 @@ package network
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"github.com/filecoin-project/venus/pkg/net"
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p-core/metrics"
@@ -52,3 +55,95 @@ func (networkAPI *NetworkAPI) NetworkConnect(ctx context.Context, addrs []string
 func (networkAPI *NetworkAPI) NetworkPeers(ctx context.Context, verbose, latency, streams bool) (*net.SwarmConnInfos, error) {
 	return networkAPI.network.Network.Peers(ctx, verbose, latency, streams)
 }
+
+// NetworkProtect protects the peers from having their connections trimmed by
+// the connection manager.
+func (networkAPI *NetworkAPI) NetworkProtect(peers []peer.ID) error {
+	cm, err := networkAPI.connManager()
+	if err != nil {
+		return err
+	}
+	for _, p := range peers {
+		cm.Protect(p, net.ProtectedTag)
+	}
+	return nil
+}
+
+// NetworkUnprotect removes the protection of the peers set by NetworkProtect or
+// the swarm config.
+func (networkAPI *NetworkAPI) NetworkUnprotect(peers []peer.ID) error {
+	cm, err := networkAPI.connManager()
+	if err != nil {
+		return err
+	}
+	for _, p := range peers {
+		cm.Unprotect(p, net.ProtectedTag)
+	}
+	return nil
+}
+
+// NetworkProtected lists the protected peers.
+func (networkAPI *NetworkAPI) NetworkProtected() ([]peer.ID, error) {
+	cm, err := networkAPI.connManager()
+	if err != nil {
+		return nil, err
+	}
+	return cm.Protected(), nil
+}
+
+func (networkAPI *NetworkAPI) connManager() (*net.ConnManager, error) {
+	if networkAPI.network.ConnMgr == nil {
+		return nil, errors.New("connection manager is not running in offline mode")
+	}
+	return networkAPI.network.ConnMgr, nil
+}
+
+// NetworkBan bans the peers for the duration, their connections are closed and
+// refused until the ban expires.
+func (networkAPI *NetworkAPI) NetworkBan(peers []peer.ID, d time.Duration, reason string) error {
+	if d <= 0 {
+		return errors.New("ban duration must be positive")
+	}
+	for _, p := range peers {
+		networkAPI.network.Bans.Ban(p, d, reason)
+	}
+	return nil
+}
+
+// NetworkUnban lifts the ban of the peers.
+func (networkAPI *NetworkAPI) NetworkUnban(peers []peer.ID) error {
+	for _, p := range peers {
+		networkAPI.network.Bans.Unban(p)
+	}
+	return nil
+}
+
+// NetworkBans lists the banned peers.
+func (networkAPI *NetworkAPI) NetworkBans() []net.BanEntry {
+	return networkAPI.network.Bans.List()
+}
+
+// NetworkScores returns the gossip scores and penalties of the peers, lowest first.
+func (networkAPI *NetworkAPI) NetworkScores() []net.PeerScore {
+	return networkAPI.network.ScoreKeeper.Scores()
+}
+
+// NetworkPeerInfo returns what the node knows about a peer: its peerstore
+// entries, bandwidth, claimed chain, chain exchange statistics and reputation.
+func (networkAPI *NetworkAPI) NetworkPeerInfo(p peer.ID) (*net.PeerInfo, error) {
+	info, err := networkAPI.network.Network.PeerInfo(p)
+	if err != nil {
+		return nil, err
+	}
+	if cm := networkAPI.network.ConnMgr; cm != nil {
+		info.Protected = cm.IsProtected(p, "")
+	}
+	info.Banned = networkAPI.network.Bans.IsBanned(p)
+	for _, score := range networkAPI.network.ScoreKeeper.Scores() {
+		if score.ID == p {
+			info.GossipScore = score.Score
+			break
+		}
+	}
+	return info, nil
+}
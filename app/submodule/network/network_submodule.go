@@ -22,6 +22,8 @@ import (
 	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/discovery"
 	"github.com/filecoin-project/venus/pkg/net"
+	"github.com/filecoin-project/venus/pkg/net/blocksub"
+	"github.com/filecoin-project/venus/pkg/net/msgsub"
 	appstate "github.com/filecoin-project/venus/pkg/state"
 	"github.com/ipfs/go-bitswap"
 	bsnet "github.com/ipfs/go-bitswap/network"
@@ -36,10 +38,13 @@ import (
 	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/libp2p/go-libp2p"
 	circuit "github.com/libp2p/go-libp2p-circuit"
+	libp2pdisc "github.com/libp2p/go-libp2p-core/discovery"
 	"github.com/libp2p/go-libp2p-core/host"
 	p2pmetrics "github.com/libp2p/go-libp2p-core/metrics"
 	smux "github.com/libp2p/go-libp2p-core/mux"
+	"github.com/libp2p/go-libp2p-core/pnet"
 	"github.com/libp2p/go-libp2p-core/routing"
+	libp2pdiscovery "github.com/libp2p/go-libp2p-discovery"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	mplex "github.com/libp2p/go-libp2p-mplex"
 	libp2pps "github.com/libp2p/go-libp2p-pubsub"
@@ -70,6 +75,14 @@ type NetworkSubmodule struct { //nolint
 	GraphExchange graphsync.GraphExchange
 
 	PeerMgr net.IPeerMgr
+	// ConnMgr trims the connections, nil in offline mode.
+	ConnMgr *net.ConnManager
+	// Bans are the peers the node refuses to talk to.
+	Bans *net.BanList
+	// ScoreKeeper keeps the gossip scores and penalties of the peers.
+	ScoreKeeper *net.ScoreKeeper
+	// Gater refuses the connections not allowed by the swarm config, nil in offline mode.
+	Gater *net.ConnGater
 	//data transfer
 	DataTransfer     datatransfer.Manager
 	DataTransferHost dtnet.DataTransferNetwork
@@ -109,6 +122,10 @@ func NewNetworkSubmodule(ctx context.Context, config networkConfig, repo network
 	validator := blankValidator{}
 	var pubsubMessageSigning bool
 	var peerMgr net.IPeerMgr
+	var connMgr *net.ConnManager
+	var gater *net.ConnGater
+	bans := net.NewBanList()
+	scoreKeeper := net.NewScoreKeeper(bans)
 	if !config.OfflineMode() {
 		makeDHT := func(h host.Host) (routing.Routing, error) {
 			mode := dht.ModeServer
@@ -118,8 +135,12 @@ func NewNetworkSubmodule(ctx context.Context, config networkConfig, repo network
 				dht.ProtocolPrefix(net.FilecoinDHT(networkName)),
 				dht.QueryFilter(dht.PublicQueryFilter),
 				dht.RoutingTableFilter(dht.PublicRoutingTableFilter),
-				dht.DisableProviders(),
 				dht.DisableValues()}
+			// Provider records are only needed to advertise the node for the
+			// rendezvous discovery.
+			if !repo.Config().Bootstrap.Rendezvous {
+				opts = append(opts, dht.DisableProviders())
+			}
 			r, err := dht.New(
 				ctx, h, opts...,
 			)
@@ -131,7 +152,26 @@ func NewNetworkSubmodule(ctx context.Context, config networkConfig, repo network
 			return r, err
 		}
 
-		var err error
+		connMgr, err = buildConnManager(repo.Config().Swarm)
+		if err != nil {
+			return nil, err
+		}
+
+		rules, err := buildGaterRules(repo.Config().Swarm)
+		if err != nil {
+			return nil, err
+		}
+		gater = net.NewConnGater(rules, bans)
+		libP2pOpts = append(libP2pOpts, libp2p.ConnectionManager(connMgr), libp2p.ConnectionGater(gater))
+
+		pnetOpt, err := buildPrivateNetwork(repo)
+		if err != nil {
+			return nil, err
+		}
+		if pnetOpt != nil {
+			libP2pOpts = append(libP2pOpts, pnetOpt)
+		}
+
 		peerHost, err = buildHost(ctx, config, libP2pOpts, repo, makeDHT)
 		if err != nil {
 			return nil, err
@@ -149,7 +189,7 @@ func NewNetworkSubmodule(ctx context.Context, config networkConfig, repo network
 			return nil, err
 		}
 
-		peerMgr, err = net.NewPeerMgr(peerHost, router.(*dht.IpfsDHT), period, bootNodes)
+		peerMgr, err = net.NewPeerMgr(peerHost, router.(*dht.IpfsDHT), period, bootNodes, bans)
 		if err != nil {
 			return nil, err
 		}
@@ -179,7 +219,14 @@ func NewNetworkSubmodule(ctx context.Context, config networkConfig, repo network
 		libp2pps.WithValidateThrottle(16 << 10),
 
 		libp2pps.WithMessageSigning(pubsubMessageSigning),
-		libp2pps.WithDiscovery(&discovery.NoopDiscovery{}),
+		libp2pps.WithDiscovery(pubsubDiscovery(config, repo, router)),
+
+		// Peers sending invalid blocks and messages lose score until graylisted.
+		libp2pps.WithPeerScore(
+			net.PeerScoreParams(blocksub.Topic(networkName), msgsub.Topic(networkName), scoreKeeper.AppSpecificScore),
+			net.PeerScoreThresholds(),
+		),
+		libp2pps.WithPeerScoreInspect(scoreKeeper.Update, net.ScoreInspectInterval),
 	}
 	gsub, err := libp2pps.NewGossipSub(ctx, peerHost, options...)
 	if err != nil {
@@ -229,9 +276,121 @@ func NewNetworkSubmodule(ctx context.Context, config networkConfig, repo network
 		DataTransfer:     dt,
 		DataTransferHost: dtNet,
 		PeerMgr:          peerMgr,
+		ConnMgr:          connMgr,
+		Bans:             bans,
+		ScoreKeeper:      scoreKeeper,
+		Gater:            gater,
 	}, nil
 }
 
+// pubsubDiscovery finds the peers of the pubsub topics in the DHT when the
+// rendezvous discovery is enabled.
+func pubsubDiscovery(config networkConfig, repo networkRepo, router routing.Routing) libp2pdisc.Discovery {
+	if config.OfflineMode() || !repo.Config().Bootstrap.Rendezvous {
+		return &discovery.NoopDiscovery{}
+	}
+	return libp2pdiscovery.NewRoutingDiscovery(router)
+}
+
+// buildGaterRules parses the allowed and denied peers and networks of the swarm config.
+func buildGaterRules(cfg *config.SwarmConfig) (*net.GaterRules, error) {
+	rules, err := net.NewGaterRules(cfg.AllowedPeers, cfg.AllowedCIDRs, cfg.DeniedCIDRs)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid swarm peer filters")
+	}
+	return rules, nil
+}
+
+// buildPrivateNetwork reads the pre-shared key of the swarm config, it returns
+// nil when the node is on the public network.
+func buildPrivateNetwork(repo networkRepo) (libp2p.Option, error) {
+	keyPath := repo.Config().Swarm.PrivateNetworkKey
+	if keyPath == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(keyPath) {
+		repoPath, err := repo.Path()
+		if err != nil {
+			return nil, err
+		}
+		keyPath = filepath.Join(repoPath, keyPath)
+	}
+
+	f, err := os.Open(keyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open private network key")
+	}
+	defer f.Close() // nolint: errcheck
+
+	psk, err := pnet.DecodeV1PSK(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid private network key %s", keyPath)
+	}
+	networkLogger.Infof("joining the private network of key %s", keyPath)
+	return libp2p.PrivateNetwork(psk), nil
+}
+
+// ValidateConfig checks the peer filters of a new config before it is saved.
+func (network *NetworkSubmodule) ValidateConfig(cfg *config.Config) error {
+	_, err := buildGaterRules(cfg.Swarm)
+	return err
+}
+
+// ApplyConfig applies the peer filters of a new config, the connections no
+// longer allowed are closed.
+func (network *NetworkSubmodule) ApplyConfig(cfg *config.Config) error {
+	rules, err := buildGaterRules(cfg.Swarm)
+	if err != nil {
+		return err
+	}
+	if network.Gater == nil {
+		return nil
+	}
+
+	network.Gater.SetRules(rules)
+	for _, c := range network.Host.Network().Conns() {
+		if network.Gater.AllowsConn(c) {
+			continue
+		}
+		networkLogger.Infof("closing connection to %s, no longer allowed by the swarm config", c.RemotePeer())
+		if err := c.Close(); err != nil {
+			networkLogger.Warnf("failed to close connection to %s: %s", c.RemotePeer(), err)
+		}
+	}
+	return nil
+}
+
+// buildConnManager creates the connection manager from the swarm config and
+// protects the configured peers.
+func buildConnManager(cfg *config.SwarmConfig) (*net.ConnManager, error) {
+	grace := net.DefaultConnMgrGrace
+	if cfg.ConnMgrGrace != "" {
+		var err error
+		grace, err = time.ParseDuration(cfg.ConnMgrGrace)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid swarm connMgrGrace")
+		}
+	}
+	low, high := cfg.ConnMgrLow, cfg.ConnMgrHigh
+	if low <= 0 || high <= 0 {
+		low, high = net.DefaultConnMgrLow, net.DefaultConnMgrHigh
+	}
+	if low > high {
+		return nil, errors.Errorf("swarm connMgrLow %d is greater than connMgrHigh %d", low, high)
+	}
+
+	protected, err := net.ParsePeerIDs(cfg.ProtectedPeers)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid swarm protectedPeers")
+	}
+
+	cm := net.NewConnManager(low, high, grace, cfg.ConnMgrMaxPerPeer)
+	for _, p := range protected {
+		cm.Protect(p, net.ProtectedTag)
+	}
+	return cm, nil
+}
+
 func retrieveNetworkName(ctx context.Context, genCid cid.Cid, cborStore cbor.IpldStore) (string, error) {
 	var genesis block.Block
 	err := cborStore.Get(ctx, genCid, &genesis)
@@ -1,6 +1,8 @@
 package syncer
 
 import (
+	"github.com/ipfs/go-cid"
+
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chainsync"
 	"github.com/filecoin-project/venus/pkg/chainsync/status"
@@ -9,6 +11,12 @@ import (
 type chainSync interface {
 	BlockProposer() chainsync.BlockProposer
 	Status() status.Status
+	SyncState() status.SyncState
+	MarkBad(c cid.Cid)
+	UnmarkBad(c cid.Cid)
+	UnmarkAllBad()
+	CheckBad(c cid.Cid) string
+	SetTrustedCheckpoint(key block.TipSetKey, stateRoot cid.Cid, backFill bool)
 }
 
 // ChainSyncProvider provides access to chain sync operations and their status.
@@ -29,6 +37,11 @@ func (chs *ChainSyncProvider) Status() status.Status {
 	return chs.sync.Status()
 }
 
+// SyncState returns the progress of the active, queued and recently finished sync targets.
+func (chs *ChainSyncProvider) SyncState() status.SyncState {
+	return chs.sync.SyncState()
+}
+
 // HandleNewTipSet extends the Syncer's chain store with the given tipset if they
 // represent a valid extension. It limits the length of new chains it will
 // attempt to validate and caches invalid blocks it has encountered to
@@ -36,3 +49,28 @@ func (chs *ChainSyncProvider) Status() status.Status {
 func (chs *ChainSyncProvider) HandleNewTipSet(ci *block.ChainInfo) error {
 	return chs.sync.BlockProposer().SendOwnBlock(ci)
 }
+
+// MarkBad marks a block bad, the syncer refuses the chains including it.
+func (chs *ChainSyncProvider) MarkBad(c cid.Cid) {
+	chs.sync.MarkBad(c)
+}
+
+// UnmarkBad unmarks a bad block.
+func (chs *ChainSyncProvider) UnmarkBad(c cid.Cid) {
+	chs.sync.UnmarkBad(c)
+}
+
+// UnmarkAllBad unmarks all bad blocks.
+func (chs *ChainSyncProvider) UnmarkAllBad() {
+	chs.sync.UnmarkAllBad()
+}
+
+// CheckBad returns the reason a block is bad, an empty string if it isn't.
+func (chs *ChainSyncProvider) CheckBad(c cid.Cid) string {
+	return chs.sync.CheckBad(c)
+}
+
+// SetTrustedCheckpoint makes the next sync start from a trusted checkpoint.
+func (chs *ChainSyncProvider) SetTrustedCheckpoint(key block.TipSetKey, stateRoot cid.Cid, backFill bool) {
+	chs.sync.SetTrustedCheckpoint(key, stateRoot, backFill)
+}
@@ -1,8 +1,15 @@
 package syncer
 
 import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chainsync/status"
+	"github.com/filecoin-project/venus/pkg/net/blocksub"
+	"github.com/filecoin-project/venus/pkg/types"
 )
 
 type SyncerAPI struct { //nolint
@@ -14,7 +21,95 @@ func (syncerAPI *SyncerAPI) SyncerStatus() status.Status {
 	return syncerAPI.syncer.SyncProvider.Status()
 }
 
+// SyncState returns the progress of each sync target: the active ones with their stage
+// and heights, the ones queued in the dispatcher and the recently finished ones.
+func (syncerAPI *SyncerAPI) SyncState() status.SyncState {
+	return syncerAPI.syncer.SyncProvider.SyncState()
+}
+
 // ChainSyncHandleNewTipSet submits a chain head to the syncer for processing.
 func (syncerAPI *SyncerAPI) ChainSyncHandleNewTipSet(ci *block.ChainInfo) error {
 	return syncerAPI.syncer.SyncProvider.HandleNewTipSet(ci)
 }
+
+// SyncSubmitBlock accepts a block produced outside of the node. The block is validated,
+// stored with its messages, published on the blocks topic and synced as an own block.
+func (syncerAPI *SyncerAPI) SyncSubmitBlock(ctx context.Context, blk *block.BlockMsg) error {
+	syncer := syncerAPI.syncer
+	header := blk.Header
+	if header == nil {
+		return errors.New("block header is missing")
+	}
+
+	if err := syncer.BlockValidator.ValidateSyntax(ctx, header); err != nil {
+		return errors.Wrapf(err, "block %s failed syntax validation", header.Cid())
+	}
+
+	msgMeta, err := syncer.MessageStore.StoreMessages(ctx, blk.SecpkMessages, blk.BlsMessages)
+	if err != nil {
+		return errors.Wrap(err, "failed to store block messages")
+	}
+	if !msgMeta.Equals(header.Messages.Cid) {
+		return errors.Errorf("block messages %s do not match header messages %s", msgMeta, header.Messages.Cid)
+	}
+
+	if _, err := syncer.CborStore.Put(ctx, header); err != nil {
+		return errors.Wrap(err, "failed to store block header")
+	}
+
+	if err := syncer.ChainSyncManager.ValidateBlock(ctx, header); err != nil {
+		return errors.Wrapf(err, "block %s failed validation", header.Cid())
+	}
+
+	blsMsgs := make([]*types.SignedMessage, len(blk.BlsMessages))
+	for i, m := range blk.BlsMessages {
+		blsMsgs[i] = &types.SignedMessage{Message: *m}
+	}
+	payload, err := blocksub.MakePayload(header, blsMsgs, blk.SecpkMessages)
+	if err != nil {
+		return err
+	}
+	if err := syncer.BlockTopic.Publish(ctx, payload); err != nil {
+		return errors.Wrapf(err, "failed to publish block %s", header.Cid())
+	}
+
+	ci := block.NewChainInfo(syncer.PeerID, syncer.PeerID, block.NewTipSetKey(header.Cid()), header.Height)
+	return syncer.ChainSyncManager.BlockProposer().SendOwnBlock(ci)
+}
+
+// SyncMarkBad marks a block bad, the syncer refuses the chains including it. It is
+// meant for manual recovery from consensus bugs.
+func (syncerAPI *SyncerAPI) SyncMarkBad(c cid.Cid) error {
+	syncerAPI.syncer.SyncProvider.MarkBad(c)
+	return nil
+}
+
+// SyncUnmarkBad unmarks a block marked bad manually or by the syncer.
+func (syncerAPI *SyncerAPI) SyncUnmarkBad(c cid.Cid) error {
+	syncerAPI.syncer.SyncProvider.UnmarkBad(c)
+	return nil
+}
+
+// SyncUnmarkAllBad unmarks all bad blocks.
+func (syncerAPI *SyncerAPI) SyncUnmarkAllBad() error {
+	syncerAPI.syncer.SyncProvider.UnmarkAllBad()
+	return nil
+}
+
+// SyncCheckBad returns the reason a block is bad, an empty string if it isn't.
+func (syncerAPI *SyncerAPI) SyncCheckBad(c cid.Cid) (string, error) {
+	return syncerAPI.syncer.SyncProvider.CheckBad(c), nil
+}
+
+// SyncTrustedCheckpoint makes the next sync start from the tipset key when the head is
+// below it. The state root its blocks were built on and the states within a finality
+// below it are fetched instead of executing the chain, the tipsets above the checkpoint
+// are validated normally. With backFill the messages below the checkpoint are fetched
+// too.
+func (syncerAPI *SyncerAPI) SyncTrustedCheckpoint(key block.TipSetKey, stateRoot cid.Cid, backFill bool) error {
+	if key.Empty() {
+		return errors.New("trusted checkpoint key is empty")
+	}
+	syncerAPI.syncer.SyncProvider.SetTrustedCheckpoint(key, stateRoot, backFill)
+	return nil
+}
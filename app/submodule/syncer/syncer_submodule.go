@@ -20,12 +20,15 @@ import (
 
 	"github.com/filecoin-project/venus/pkg/beacon"
 	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/cborutil"
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/chainsync"
 	"github.com/filecoin-project/venus/pkg/chainsync/exchange"
 	"github.com/filecoin-project/venus/pkg/chainsync/fetcher"
 	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/consensus"
+	"github.com/filecoin-project/venus/pkg/net"
 	"github.com/filecoin-project/venus/pkg/net/blocksub"
 	"github.com/filecoin-project/venus/pkg/net/pubsub"
 	"github.com/filecoin-project/venus/pkg/slashing"
@@ -39,6 +42,10 @@ var log = logging.Logger("sync_moduel") // nolint: deadcode
 type SyncerSubmodule struct { //nolint
 	BlockTopic       *pubsub.Topic
 	BlockSub         pubsub.Subscription
+	BlockValidator   consensus.BlockSyntaxValidator
+	MessageStore     *chain.MessageStore
+	CborStore        *cborutil.IpldStore
+	PeerID           peer.ID
 	ChainSelector    nodeChainSelector
 	Consensus        consensus.Protocol
 	FaultDetector    slashing.ConsensusFaultDetector
@@ -82,7 +89,8 @@ func NewSyncerSubmodule(ctx context.Context,
 
 	// register block validation on pubsub
 	btv := blocksub.NewBlockTopicValidator(blkValid)
-	if err := network.Pubsub.RegisterTopicValidator(btv.Topic(network.NetworkName), btv.Validator(), btv.Opts()...); err != nil {
+	blockTopic := btv.Topic(network.NetworkName)
+	if err := network.Pubsub.RegisterTopicValidator(blockTopic, network.ScoreKeeper.Validator(blockTopic, btv.Validator()), btv.Opts()...); err != nil {
 		return nil, errors.Wrap(err, "failed to register block validator")
 	}
 
@@ -139,6 +147,37 @@ func NewSyncerSubmodule(ctx context.Context,
 		return nil, err
 	}
 
+	if cp := config.Repo().Config().Chain.TrustedCheckpoint; cp != nil {
+		key, stateRoot, err := parseTrustedCheckpoint(cp)
+		if err != nil {
+			return nil, err
+		}
+		chainSyncManager.SetTrustedCheckpoint(key, stateRoot, cp.BackFill)
+	}
+
+	network.Network.SetExchangeStatsSource(func(p peer.ID) (*net.PeerExchangeStats, bool) {
+		for _, score := range exchangeClient.PeerScores() {
+			if score.ID == p {
+				return &net.PeerExchangeStats{
+					Successes:        score.Successes,
+					Failures:         score.Failures,
+					Invalid:          score.Invalid,
+					AverageTime:      score.AverageTime,
+					Throughput:       score.Throughput,
+					BlacklistedUntil: score.BlacklistedUntil,
+				}, true
+			}
+		}
+		return nil, false
+	})
+
+	// The peers which sent us a block that the syncer validated are useful to keep.
+	chainSyncManager.OnGossipSynced(func(ci block.ChainInfo) {
+		if chn.ChainReader.HasTipSetAndState(ctx, ci.Head) {
+			network.Host.ConnManager().TagPeer(ci.Sender, net.NewBlockTag, net.NewBlockTagValue)
+		}
+	})
+
 	discovery.PeerDiscoveryCallbacks = append(discovery.PeerDiscoveryCallbacks, func(ci *block.ChainInfo) {
 		err := chainSyncManager.BlockProposer().SendHello(ci)
 		if err != nil {
@@ -150,15 +189,39 @@ func NewSyncerSubmodule(ctx context.Context,
 	return &SyncerSubmodule{
 		BlockTopic: pubsub.NewTopic(topic),
 		// BlockSub: nil,
+		BlockValidator:   blkValid,
+		MessageStore:     chn.MessageStore,
+		CborStore:        blockstore.CborStore,
+		PeerID:           network.Host.ID(),
 		Consensus:        nodeConsensus,
 		ChainSelector:    nodeChainSelector,
 		ChainSyncManager: &chainSyncManager,
 		Drand:            chn.Drand,
+		SyncProvider:     *NewChainSyncProvider(&chainSyncManager),
 		// cancelChainSync: nil,
 		faultCh: faultCh,
 	}, nil
 }
 
+func parseTrustedCheckpoint(cp *config.TrustedCheckpointConfig) (block.TipSetKey, cid.Cid, error) {
+	cids := make([]cid.Cid, len(cp.Key))
+	for i, s := range cp.Key {
+		c, err := cid.Decode(s)
+		if err != nil {
+			return block.TipSetKey{}, cid.Undef, errors.Wrapf(err, "invalid trusted checkpoint block %s", s)
+		}
+		cids[i] = c
+	}
+	if len(cids) == 0 {
+		return block.TipSetKey{}, cid.Undef, errors.New("trusted checkpoint key is empty")
+	}
+	stateRoot, err := cid.Decode(cp.StateRoot)
+	if err != nil {
+		return block.TipSetKey{}, cid.Undef, errors.Wrapf(err, "invalid trusted checkpoint state root %s", cp.StateRoot)
+	}
+	return block.NewTipSetKey(cids...), stateRoot, nil
+}
+
 type syncerNode interface {
 }
 
@@ -9,6 +9,8 @@ import (
 	"github.com/ipfs/go-cid"
 	cmds "github.com/ipfs/go-ipfs-cmds"
 
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
 	"github.com/filecoin-project/venus/pkg/types"
 )
 
@@ -19,6 +21,11 @@ type ActorView struct {
 	Nonce   uint64        `json:"nonce"`
 	Balance types.AttoFIL `json:"balance"`
 	Head    cid.Cid       `json:"head,omitempty"`
+	// Name and State are the builtin actor name and the decoded state, set with --decode.
+	// DecodeError is set instead of State when the state does not decode.
+	Name        string      `json:"name,omitempty"`
+	State       interface{} `json:"state,omitempty"`
+	DecodeError string      `json:"decodeError,omitempty"`
 }
 
 var actorCmd = &cmds.Command{
@@ -31,14 +38,28 @@ var actorCmd = &cmds.Command{
 }
 
 var actorLsCmd = &cmds.Command{
+	Options: []cmds.Option{
+		decodeStateOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		results, err := env.(*node.Env).ChainAPI.ListActor(req.Context)
+		chainAPI := env.(*node.Env).ChainAPI
+		results, err := chainAPI.ListActor(req.Context)
 		if err != nil {
 			return err
 		}
 
+		decode, _ := req.Options["decode"].(bool)
 		for addr, actor := range results {
 			output := makeActorView(actor, addr)
+			if decode {
+				// Keep listing when an actor has no state decoder, like the system actor.
+				st, err := chainAPI.StateReadState(req.Context, addr, block.TipSetKey{})
+				if err != nil {
+					output.Name, output.DecodeError = builtin.ActorNameByCode(actor.Code.Cid), err.Error()
+				} else {
+					output.Name, output.State = st.Name, st.State
+				}
+			}
 			if err := re.Emit(output); err != nil {
 				return err
 			}
@@ -62,6 +83,8 @@ var actorLsCmd = &cmds.Command{
 	},
 }
 
+var decodeStateOption = cmds.BoolOption("decode", "Decode the state of builtin actors")
+
 func makeActorView(act *types.Actor, addr address.Address) *ActorView {
 	return &ActorView{
 		Address: addr.String(),
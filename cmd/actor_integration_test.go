@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/venus/app/node/test"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
 	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
 )
 
@@ -37,4 +38,32 @@ func TestActorDaemon(t *testing.T) {
 
 		assert.NotZero(t, len(avs))
 	})
+
+	t.Run("actor ls --decode lists every actor and reports the states that do not decode", func(t *testing.T) {
+		builder := test.NewNodeBuilder(t)
+
+		_, cmdClient, done := builder.BuildAndStartAPI(ctx)
+		defer done()
+
+		all := cmdClient.RunSuccess(ctx, "actor", "ls", "--enc", "json").ReadStdoutTrimNewlines()
+		op := cmdClient.RunSuccess(ctx, "actor", "ls", "--decode", "--enc", "json")
+		lines := bytes.Split([]byte(op.ReadStdoutTrimNewlines()), []byte{'\n'})
+		assert.Len(t, lines, len(bytes.Split([]byte(all), []byte{'\n'})))
+
+		var system *cmd.ActorView
+		for _, line := range lines {
+			var av cmd.ActorView
+			require.NoError(t, json.Unmarshal(line, &av))
+			assert.NotEmpty(t, av.Name)
+			if av.Address == builtin.SystemActorAddr.String() {
+				system = &av
+				continue
+			}
+			assert.Empty(t, av.DecodeError, "actor %s", av.Address)
+			assert.NotNil(t, av.State, "actor %s", av.Address)
+		}
+		require.NotNil(t, system)
+		assert.Nil(t, system.State)
+		assert.Contains(t, system.DecodeError, "unknown actor code")
+	})
 }
@@ -2,17 +2,28 @@
 package cmd
 
 import (
-	"github.com/filecoin-project/venus/app/node"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
 	cmds "github.com/ipfs/go-ipfs-cmds"
+	files "github.com/ipfs/go-ipfs-files"
 	"github.com/libp2p/go-libp2p-core/peer"
 
+	"github.com/filecoin-project/venus/app/node"
+	chainapi "github.com/filecoin-project/venus/app/submodule/chain"
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/chainsync/status"
+	"github.com/filecoin-project/venus/pkg/types"
 )
 
 var chainCmd = &cmds.Command{
@@ -20,12 +31,20 @@ var chainCmd = &cmds.Command{
 		Tagline: "Inspect the filecoin blockchain",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"export":   storeExportCmd,
-		"head":     storeHeadCmd,
-		"ls":       storeLsCmd,
-		"status":   storeStatusCmd,
-		"set-head": storeSetHeadCmd,
-		"sync":     storeSyncCmd,
+		"checkpoint":       storeCheckPointCmd,
+		"export":           storeExportCmd,
+		"get-block":        storeGetBlockCmd,
+		"get-message":      storeGetMessageCmd,
+		"get-tipset":       storeGetTipSetCmd,
+		"head":             storeHeadCmd,
+		"ls":               storeLsCmd,
+		"read-obj":         storeReadObjCmd,
+		"reorgs":           storeReorgsCmd,
+		"status":           storeStatusCmd,
+		"set-head":         storeSetHeadCmd,
+		"sync":             storeSyncCmd,
+		"sync-wait":        storeSyncWaitCmd,
+		"upgrade-schedule": storeUpgradeScheduleCmd,
 	},
 }
 
@@ -62,24 +81,38 @@ var storeHeadCmd = &cmds.Command{
 
 var storeLsCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
-		Tagline:          "List blocks in the blockchain",
-		ShortDescription: `Provides a list of blocks in order from head to genesis. By default, only CIDs are returned for each block.`,
+		Tagline: "List blocks in the blockchain",
+		ShortDescription: `Provides a list of blocks in order from head to genesis. By default, only CIDs are returned for each block.
+With --from-height and --to-height every tipset in the range is listed, newest first, and --count is ignored.
+With --enc=text one line is printed per tipset with its height, block CIDs and miners.`,
 	},
 	Options: []cmds.Option{
 		cmds.Int64Option("height", "Start height of the query").WithDefault(-1),
 		cmds.UintOption("count", "Number of queries").WithDefault(10),
+		cmds.Int64Option("from-height", "Lowest height of the listed range").WithDefault(-1),
+		cmds.Int64Option("to-height", "Highest height of the listed range, defaults to the head").WithDefault(-1),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		count, _ := req.Options["count"].(uint)
-		if count < 1 {
+		fromHeight, _ := req.Options["from-height"].(int64)
+		toHeight, _ := req.Options["to-height"].(int64)
+		if toHeight < 0 {
+			// kept for compatibility with --height
+			toHeight, _ = req.Options["height"].(int64)
+		}
+		if fromHeight >= 0 {
+			count = 0
+			if toHeight >= 0 && toHeight < fromHeight {
+				return fmt.Errorf("to-height %d is below from-height %d", toHeight, fromHeight)
+			}
+		} else if count < 1 {
 			return nil
 		}
 
 		var iter *chain.TipsetIterator
 		var err error
-		height, _ := req.Options["height"].(int64)
-		if height >= 0 {
-			ts, err := env.(*node.Env).ChainAPI.ChainGetTipSetByHeight(req.Context, nil, abi.ChainEpoch(height), true)
+		if toHeight >= 0 {
+			ts, err := env.(*node.Env).ChainAPI.ChainGetTipSetByHeight(req.Context, nil, abi.ChainEpoch(toHeight), true)
 			if err != nil {
 				return err
 			}
@@ -103,18 +136,144 @@ var storeLsCmd = &cmds.Command{
 			if !iter.Value().Defined() {
 				panic("tipsets from this iterator should have at least one member")
 			}
+			if fromHeight >= 0 && iter.Value().EnsureHeight() < abi.ChainEpoch(fromHeight) {
+				break
+			}
 			if err := re.Emit(iter.Value().ToSlice()); err != nil {
 				return err
 			}
 
 			number++
-			if number >= count {
+			if count > 0 && number >= count {
 				break
 			}
 		}
 		return nil
 	},
 	Type: []block.Block{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, blks []block.Block) error {
+			if len(blks) == 0 {
+				return nil
+			}
+			cids := make([]string, len(blks))
+			miners := make([]string, len(blks))
+			for i := range blks {
+				cids[i] = blks[i].Cid().String()
+				miners[i] = blks[i].Miner.String()
+			}
+			_, err := fmt.Fprintf(w, "%d\t%s\t%s\n", blks[0].Height, strings.Join(cids, ","), strings.Join(miners, ","))
+			return err
+		}),
+	},
+}
+
+var storeGetTipSetCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline:          "Get a tipset by its block CIDs or by height",
+		ShortDescription: `Gets the tipset of the given blocks, or with --height the tipset of the current chain at that height. The previous non null tipset is returned if the height is a null round.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cids", false, true, "CID's of the blocks of the tipset"),
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("height", "Height of the tipset on the current chain").WithDefault(-1),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		chainAPI := env.(*node.Env).ChainAPI
+
+		height, _ := req.Options["height"].(int64)
+		if height >= 0 {
+			if len(req.Arguments) > 0 {
+				return errors.New("either block cids or --height can be given")
+			}
+			ts, err := chainAPI.ChainGetTipSetByHeight(req.Context, nil, abi.ChainEpoch(height), true)
+			if err != nil {
+				return err
+			}
+			return re.Emit(ts)
+		}
+
+		if len(req.Arguments) == 0 {
+			return errors.New("block cids or --height required")
+		}
+		tsCids, err := cidsFromSlice(req.Arguments)
+		if err != nil {
+			return err
+		}
+		ts, err := chainAPI.ChainTipSet(block.NewTipSetKey(tsCids...))
+		if err != nil {
+			return err
+		}
+		return re.Emit(ts)
+	},
+	Type: block.TipSet{},
+}
+
+var storeGetBlockCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Get a block header by its CID",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cid", true, false, "CID of the block"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		bcid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		blk, err := env.(*node.Env).ChainAPI.ChainGetBlock(req.Context, bcid)
+		if err != nil {
+			return err
+		}
+		return re.Emit(blk)
+	},
+	Type: block.Block{},
+}
+
+var storeGetMessageCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Get a message by its CID",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cid", true, false, "CID of the message"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		mcid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		msg, err := env.(*node.Env).ChainAPI.ChainGetMessage(req.Context, mcid)
+		if err != nil {
+			return err
+		}
+		return re.Emit(msg)
+	},
+	Type: types.UnsignedMessage{},
+}
+
+var storeReadObjCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Read the raw bytes of an object, hex encoded",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cid", true, false, "CID of the object"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ocid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		raw, err := env.(*node.Env).ChainAPI.ChainReadObj(req.Context, ocid)
+		if err != nil {
+			return err
+		}
+		return re.Emit(hex.EncodeToString(raw))
+	},
+	Type: "",
 }
 
 var storeStatusCmd = &cmds.Command{
@@ -130,6 +289,133 @@ var storeStatusCmd = &cmds.Command{
 	},
 }
 
+// SyncWaitResult is the progress of the chain sync emitted by sync-wait.
+type SyncWaitResult struct {
+	Head       block.TipSetKey
+	HeadHeight abi.ChainEpoch
+	State      status.SyncState
+	Done       bool
+}
+
+var storeSyncWaitCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Wait for the chain to be synced",
+		ShortDescription: `Prints the progress of the active and queued sync targets every second until no target is
+syncing and the head is less than a block time old. With --enc=text the progress is printed as text, redrawn
+in place on a terminal.`,
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("watch", "Keep printing the progress once the chain is synced"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		chainAPI := env.(*node.Env).ChainAPI
+		syncerAPI := env.(*node.Env).SyncerAPI
+		watch, _ := req.Options["watch"].(bool)
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			head, err := chainAPI.ChainHead()
+			if err != nil {
+				return err
+			}
+
+			state := syncerAPI.SyncState()
+			age := time.Since(time.Unix(int64(head.MinTimestamp()), 0))
+			done := len(state.Active) == 0 && len(state.Queued) == 0 && age < chainAPI.BlockTime()
+
+			if err := re.Emit(&SyncWaitResult{
+				Head:       head.Key(),
+				HeadHeight: head.EnsureHeight(),
+				State:      state,
+				Done:       done,
+			}); err != nil {
+				return err
+			}
+			if done && !watch {
+				return nil
+			}
+
+			select {
+			case <-req.Context.Done():
+				return req.Context.Err()
+			case <-ticker.C:
+			}
+		}
+	},
+	Type: SyncWaitResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: func(req *cmds.Request) func(io.Writer) cmds.Encoder {
+			return func(w io.Writer) cmds.Encoder {
+				return &syncWaitEncoder{w: w, tty: isTerminal(w)}
+			}
+		},
+	},
+}
+
+// syncWaitEncoder prints the sync progress as text, redrawn in place when writing to a terminal.
+type syncWaitEncoder struct {
+	w       io.Writer
+	tty     bool
+	printed int
+}
+
+func (e *syncWaitEncoder) Encode(v interface{}) error {
+	var result *SyncWaitResult
+	switch val := v.(type) {
+	case *SyncWaitResult:
+		result = val
+	case SyncWaitResult:
+		result = &val
+	default:
+		return fmt.Errorf("unexpected type %T", v)
+	}
+
+	if e.tty && e.printed > 0 {
+		// move up to the previous progress and clear it
+		if _, err := fmt.Fprintf(e.w, "\r\x1b[%dA\x1b[J", e.printed); err != nil {
+			return err
+		}
+	}
+	lines := syncWaitLines(result)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(e.w, line); err != nil {
+			return err
+		}
+	}
+	e.printed = len(lines)
+	return nil
+}
+
+// isTerminal returns whether w is a character device, such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+func syncWaitLines(result *SyncWaitResult) []string {
+	lines := []string{fmt.Sprintf("head: %d %s", result.HeadHeight, result.Head)}
+	for _, target := range result.State.Active {
+		lines = append(lines, fmt.Sprintf("  target %d: %s %d/%d (base %d, validated %d) since %s",
+			target.TargetHeight, target.Stage, target.Height, target.TargetHeight, target.BaseHeight,
+			target.ValidatedHeight, time.Since(target.Start).Truncate(time.Second)))
+	}
+	if len(result.State.Queued) > 0 {
+		lines = append(lines, fmt.Sprintf("  queued: %d targets, highest %d", len(result.State.Queued), result.State.Queued[0].Height))
+	}
+	if len(result.State.Recent) > 0 && result.State.Recent[0].Stage == status.StageError {
+		lines = append(lines, fmt.Sprintf("  last error: target %d: %s", result.State.Recent[0].TargetHeight, result.State.Recent[0].Err))
+	}
+	if result.Done {
+		lines = append(lines, "done")
+	}
+	return lines
+}
+
 var storeSetHeadCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Set the chain head to a specific tipset key.",
@@ -147,6 +433,88 @@ var storeSetHeadCmd = &cmds.Command{
 	},
 }
 
+var storeCheckPointCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline:          "Manage the chain checkpoint",
+		ShortDescription: `The node never syncs a fork whose common ancestor with the current chain is below the checkpoint.`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"get":   storeCheckPointGetCmd,
+		"set":   storeCheckPointSetCmd,
+		"clear": storeCheckPointClearCmd,
+	},
+}
+
+var storeCheckPointGetCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the current checkpoint tipset",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ts, err := env.(*node.Env).ChainAPI.ChainGetCheckPoint()
+		if err != nil {
+			return err
+		}
+
+		h, err := ts.Height()
+		if err != nil {
+			return err
+		}
+
+		pw, err := ts.ParentWeight()
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&ChainHeadResult{Height: h, ParentWeight: pw, Cids: ts.Key().ToSlice()})
+	},
+	Type: &ChainHeadResult{},
+}
+
+var storeCheckPointSetCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Set the checkpoint to a tipset of the current chain",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cids", true, true, "CID's of the blocks of the tipset to set the checkpoint to."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cpCids, err := cidsFromSlice(req.Arguments)
+		if err != nil {
+			return err
+		}
+		return env.(*node.Env).ChainAPI.ChainSetCheckPoint(req.Context, block.NewTipSetKey(cpCids...))
+	},
+}
+
+var storeCheckPointClearCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Reset the checkpoint to the genesis tipset",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		return env.(*node.Env).ChainAPI.ChainClearCheckPoint(req.Context)
+	},
+}
+
+var storeReorgsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline:          "List recent reorgs of the chain head",
+		ShortDescription: `Lists the most recent reorgs, newest first, with the blocks each of them dropped and applied.`,
+	},
+	Options: []cmds.Option{
+		cmds.IntOption("count", "Number of reorgs to list, 0 for all kept reorgs").WithDefault(10),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		count, _ := req.Options["count"].(int)
+		for _, event := range env.(*node.Env).ChainAPI.ChainReorgs(count) {
+			if err := re.Emit(event); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Type: chain.ReorgEvent{},
+}
+
 var storeSyncCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Instruct the chain syncer to sync a specific chain head, going to network if required.",
@@ -156,6 +524,13 @@ var storeSyncCmd = &cmds.Command{
 		cmds.StringArg("cids", true, true, "CID's of the blocks of the tipset to sync."),
 	},
 	Options: []cmds.Option{},
+	Subcommands: map[string]*cmds.Command{
+		"check-bad":  storeSyncCheckBadCmd,
+		"fast":       storeSyncFastCmd,
+		"mark-bad":   storeSyncMarkBadCmd,
+		"submit":     storeSyncSubmitCmd,
+		"unmark-bad": storeSyncUnmarkBadCmd,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		syncPid, err := peer.Decode(req.Arguments[0])
 		if err != nil {
@@ -178,6 +553,141 @@ var storeSyncCmd = &cmds.Command{
 	},
 }
 
+var storeSyncSubmitCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline:          "Submit a block produced outside of the node",
+		ShortDescription: `Reads a JSON block message, the header with its BLS and secp messages. The block is validated, published to the network and synced.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.FileArg("blockFile", true, false, "File containing the JSON block message").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		iter := req.Files.Entries()
+		if !iter.Next() {
+			return fmt.Errorf("no file given: %s", iter.Err())
+		}
+
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return fmt.Errorf("given file was not a files.File")
+		}
+
+		var blk block.BlockMsg
+		if err := json.NewDecoder(fi).Decode(&blk); err != nil {
+			return err
+		}
+
+		if err := env.(*node.Env).SyncerAPI.SyncSubmitBlock(req.Context, &blk); err != nil {
+			return err
+		}
+		return re.Emit(blk.Header.Cid())
+	},
+	Type: cid.Cid{},
+}
+
+var storeSyncFastCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Fast sync from a trusted checkpoint",
+		ShortDescription: `When the head is below the checkpoint, the next sync fetches the headers down to the head and the
+states the checkpoint and the finality below it were built on, then syncs normally from the parent of the
+checkpoint without executing the chain in between.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cids", true, true, "CID's of the blocks of the checkpoint tipset."),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("state-root", "Parent state root of the checkpoint blocks"),
+		cmds.BoolOption("backfill", "Fetch the messages below the checkpoint in the background"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cpCids, err := cidsFromSlice(req.Arguments)
+		if err != nil {
+			return err
+		}
+
+		rootStr, _ := req.Options["state-root"].(string)
+		if rootStr == "" {
+			return errors.New("the state root of the checkpoint is required")
+		}
+		stateRoot, err := cid.Decode(rootStr)
+		if err != nil {
+			return err
+		}
+
+		backFill, _ := req.Options["backfill"].(bool)
+		return env.(*node.Env).SyncerAPI.SyncTrustedCheckpoint(block.NewTipSetKey(cpCids...), stateRoot, backFill)
+	},
+}
+
+var storeSyncMarkBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline:          "Mark a block bad, the syncer refuses the chains including it",
+		ShortDescription: `Meant for manual recovery from consensus bugs. The mark is only kept in memory until the node restarts.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cid", true, false, "CID of the block to mark bad."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		c, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		return env.(*node.Env).SyncerAPI.SyncMarkBad(c)
+	},
+}
+
+var storeSyncUnmarkBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Unmark a bad block",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cid", false, false, "CID of the block to unmark."),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("all", "Unmark all bad blocks"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		syncerAPI := env.(*node.Env).SyncerAPI
+		if all, _ := req.Options["all"].(bool); all {
+			return syncerAPI.SyncUnmarkAllBad()
+		}
+		if len(req.Arguments) != 1 {
+			return errors.New("expected a block cid or --all")
+		}
+
+		c, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		return syncerAPI.SyncUnmarkBad(c)
+	},
+}
+
+var storeSyncCheckBadCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the reason a block is marked bad",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cid", true, false, "CID of the block to check."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		c, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		reason, err := env.(*node.Env).SyncerAPI.SyncCheckBad(c)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			reason = "block is not marked bad"
+		}
+		return re.Emit(reason)
+	},
+	Type: "",
+}
+
 var storeExportCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Export the chain store to a car file.",
@@ -205,3 +715,19 @@ var storeExportCmd = &cmds.Command{
 		return nil
 	},
 }
+
+var storeUpgradeScheduleCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the upgrades of the network",
+		ShortDescription: `Prints the enabled upgrades loaded at startup, from the upgrade schedule in the
+config when one is declared, ordered by height.
+
+HasPreMigration tells whether the migration of an upgrade runs ahead of the upgrade in
+the background. No migration has a pre-migration yet: every migration runs whole at
+the upgrade epoch, and the chain stalls while an expensive one runs.`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		return re.Emit(env.(*node.Env).ChainAPI.StateUpgradeSchedule(req.Context))
+	},
+	Type: []chainapi.NetworkUpgrade{},
+}
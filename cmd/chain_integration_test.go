@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/filecoin-project/venus/cmd"
 	"testing"
 
@@ -71,4 +72,17 @@ func TestChainLs(t *testing.T) {
 
 		assert.True(t, b[0].Parents.Empty())
 	})
+	t.Run("chain ls with text encoding prints a line per tipset", func(t *testing.T) {
+		builder := test.NewNodeBuilder(t)
+
+		_, cmdClient, done := builder.BuildAndStartAPI(ctx)
+		defer done()
+
+		genesis := cmdClient.RunSuccess(ctx, "chain", "ls", "--enc", "json").ReadStdoutTrimNewlines()
+		var b []block.Block
+		require.NoError(t, json.Unmarshal([]byte(genesis), &b))
+
+		result := cmdClient.RunSuccess(ctx, "chain", "ls", "--enc", "text").ReadStdoutTrimNewlines()
+		assert.Equal(t, fmt.Sprintf("0\t%s\t%s", b[0].Cid(), b[0].Miner), result)
+	})
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestSyncWaitEncoder(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("prints the progress without terminal escapes when not writing to a terminal", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := &syncWaitEncoder{w: &buf, tty: isTerminal(&buf)}
+
+		require.NoError(t, enc.Encode(&SyncWaitResult{HeadHeight: 1}))
+		require.NoError(t, enc.Encode(SyncWaitResult{HeadHeight: 2, Done: true}))
+
+		assert.NotContains(t, buf.String(), "\x1b")
+		assert.Contains(t, buf.String(), "head: 1 ")
+		assert.Contains(t, buf.String(), "head: 2 ")
+		assert.Contains(t, buf.String(), "done\n")
+	})
+
+	t.Run("redraws the previous progress on a terminal", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := &syncWaitEncoder{w: &buf, tty: true}
+
+		require.NoError(t, enc.Encode(&SyncWaitResult{HeadHeight: 1}))
+		assert.NotContains(t, buf.String(), "\x1b")
+		require.NoError(t, enc.Encode(&SyncWaitResult{HeadHeight: 2}))
+		assert.Contains(t, buf.String(), "\r\x1b[1A\x1b[J")
+	})
+
+	t.Run("rejects other types", func(t *testing.T) {
+		enc := &syncWaitEncoder{w: &bytes.Buffer{}}
+		assert.Error(t, enc.Encode("head"))
+	})
+}
@@ -112,6 +112,7 @@ VIEW DATA STRUCTURES
   venus chain                  - Inspect the filecoin blockchain
   venus dag                    - Interact with IPLD DAG objects
   venus show                   - Get human-readable representations of filecoin objects
+  venus state                  - Inspect the state of the chain
 
 NETWORK COMMANDS
   venus bootstrap              - Interact with bootstrap addresses
@@ -123,6 +124,7 @@ NETWORK COMMANDS
 
 ACTOR COMMANDS
   venus actor                  - Interact with actors. Actors are built-in smart contracts
+  venus verifreg               - Interact with the verified registry actor
 
 MESSAGE COMMANDS
   venus message                - Manage messages
@@ -184,8 +186,10 @@ var rootSubcmdsDaemon = map[string]*cmds.Command{
 	"outbox":   outboxCmd,
 	"protocol": protocolCmd,
 	"show":     showCmd,
+	"state":    stateCmd,
 	"stats":    statsCmd,
 	"swarm":    swarmCmd,
+	"verifreg": verifregCmd,
 	"wallet":   walletCmd,
 	"version":  versionCmd,
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/filecoin-project/venus/app/node"
 	"github.com/filecoin-project/venus/pkg/constants"
+	"strconv"
 	"time"
 
 	"github.com/filecoin-project/go-address"
@@ -58,6 +59,7 @@ var msgSendCmd = &cmds.Command{
 		premiumOption,
 		limitOption,
 		previewOption,
+		cmds.StringOption("params-json", "Params of the method as JSON, encoded with the method signature of the target actor"),
 		// TODO: (per dignifiedquire) add an option to set the nonce and method explicitly
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -86,17 +88,30 @@ var msgSendCmd = &cmds.Command{
 		}
 
 		methodID := builtin.MethodSend
-		methodInput, ok := req.Options["method"].(uint64)
-		if ok {
+		if len(req.Arguments) > 1 {
+			methodInput, err := strconv.ParseUint(req.Arguments[1], 10, 64)
+			if err != nil {
+				return errors.Wrap(err, "invalid method "+req.Arguments[1])
+			}
 			methodID = abi.MethodNum(methodInput)
 		}
 
+		params := []byte{}
+		if paramsJSON, _ := req.Options["params-json"].(string); paramsJSON != "" {
+			params, err = env.(*node.Env).ChainAPI.StateEncodeParams(req.Context, target, methodID, json.RawMessage(paramsJSON), block.TipSetKey{})
+			if err != nil {
+				return err
+			}
+		}
+
 		if preview {
 			usedGas, err := env.(*node.Env).MessagingAPI.MessagePreview(
 				req.Context,
 				fromAddr,
 				target,
+				val,
 				methodID,
+				params,
 			)
 			if err != nil {
 				return err
@@ -108,7 +123,7 @@ var msgSendCmd = &cmds.Command{
 			})
 		}
 
-		c, err := env.(*node.Env).MessagingAPI.MessageSend(
+		c, err := env.(*node.Env).MessagingAPI.MessageSendEncoded(
 			req.Context,
 			fromAddr,
 			target,
@@ -117,7 +132,7 @@ var msgSendCmd = &cmds.Command{
 			premium,
 			gasLimit,
 			methodID,
-			[]byte{},
+			params,
 		)
 		if err != nil {
 			return err
@@ -245,6 +260,11 @@ type MessageStatusResult struct {
 	InOutbox  bool // Whether the message is found in the outbox
 	OutboxMsg *message.Queued
 	ChainMsg  *msg.ChainMessage
+	// Params of the messages decoded with --decode, or the errors when they do not decode
+	PoolMsgParams        interface{} `json:",omitempty"`
+	PoolMsgDecodeError   string      `json:",omitempty"`
+	OutboxMsgParams      interface{} `json:",omitempty"`
+	OutboxMsgDecodeError string      `json:",omitempty"`
 }
 
 var msgStatusCmd = &cmds.Command{
@@ -254,7 +274,7 @@ var msgStatusCmd = &cmds.Command{
 	Arguments: []cmds.Argument{
 		cmds.StringArg("cid", true, false, "CID of the message to inspect"),
 	},
-	Options: []cmds.Option{},
+	Options: []cmds.Option{decodeOption},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		msgCid, err := cid.Parse(req.Arguments[0])
 		if err != nil {
@@ -282,7 +302,31 @@ var msgStatusCmd = &cmds.Command{
 			}
 		}
 
+		if decode, _ := req.Options["decode"].(bool); decode {
+			if result.PoolMsg != nil {
+				if result.PoolMsgParams, result.PoolMsgDecodeError, err = decodeMessageParams(req, env, &result.PoolMsg.Message); err != nil {
+					return err
+				}
+			}
+			if result.OutboxMsg != nil {
+				if result.OutboxMsgParams, result.OutboxMsgDecodeError, err = decodeMessageParams(req, env, &result.OutboxMsg.Msg.Message); err != nil {
+					return err
+				}
+			}
+		}
+
 		return re.Emit(&result)
 	},
 	Type: &MessageStatusResult{},
 }
+
+// decodeMessageParams decodes the params of a message with the method signature of its receiver at
+// the head. The error of params that do not decode is returned as a string, so that one message
+// does not fail the whole listing.
+func decodeMessageParams(req *cmds.Request, env cmds.Environment, msg *types.UnsignedMessage) (interface{}, string, error) {
+	decoded, err := env.(*node.Env).ChainAPI.StateDecodeMessage(req.Context, msg, block.TipSetKey{})
+	if err != nil {
+		return nil, "", err
+	}
+	return decoded.Params, decoded.Error, nil
+}
@@ -21,24 +21,44 @@ var mpoolCmd = &cmds.Command{
 	},
 }
 
+// MpoolMessage is a message of the pool, with its params decoded with --decode, or the error
+// when they do not decode.
+type MpoolMessage struct {
+	*types.SignedMessage
+	DecodedParams interface{} `json:",omitempty"`
+	DecodeError   string      `json:",omitempty"`
+}
+
 var mpoolLsCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "View the pool of outstanding messages",
 	},
 	Options: []cmds.Option{
 		cmds.UintOption("wait-for-count", "Block until this number of messages are in the pool").WithDefault(0),
+		decodeOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		messageCount, _ := req.Options["wait-for-count"].(uint)
+		decode, _ := req.Options["decode"].(bool)
 
 		pending, err := env.(*node.Env).MessagingAPI.MessagePoolWait(req.Context, messageCount)
 		if err != nil {
 			return err
 		}
 
-		return re.Emit(pending)
+		out := make([]*MpoolMessage, len(pending))
+		for i, smsg := range pending {
+			out[i] = &MpoolMessage{SignedMessage: smsg}
+			if !decode {
+				continue
+			}
+			if out[i].DecodedParams, out[i].DecodeError, err = decodeMessageParams(req, env, &smsg.Message); err != nil {
+				return err
+			}
+		}
+		return re.Emit(out)
 	},
-	Type: []*types.SignedMessage{},
+	Type: []*MpoolMessage{},
 }
 
 var mpoolShowCmd = &cmds.Command{
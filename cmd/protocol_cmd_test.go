@@ -31,4 +31,7 @@ func TestProtocol(t *testing.T) {
 
 	out := cmd.RunSuccess(ctx, "protocol").ReadStdout()
 	assert.Contains(t, out, "\"Network\": \"gfctest\"")
+	assert.Contains(t, out, "\"NetworkVersion\"")
+	assert.Contains(t, out, "\"BeaconSchedule\"")
+	assert.Contains(t, out, "\"SupportedSealProofs\"")
 }
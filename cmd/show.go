@@ -8,6 +8,7 @@ import (
 
 	"github.com/ipfs/go-cid"
 	cmds "github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
 )
 
 var showCmd = &cmds.Command{
@@ -77,30 +78,63 @@ all other block properties will be included as well.`,
 	Type: block.Block{},
 }
 
+// ShowMessagesResult is a message collection, or its messages with their params decoded with --decode.
+type ShowMessagesResult struct {
+	*chain.BlockMessage
+	Decoded []*chain.DecodedMessage `json:",omitempty"`
+}
+
 var showMessagesCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Show a filecoin message collection by txmeta CID",
 		ShortDescription: `Prints info for all messages in a collection,
 at the given CID.  This CID is found in the "Messages" field of
-the filecoin block header.`,
+the filecoin block header. Decoding the params with --decode needs the block
+including the collection, given with --block: the params are decoded with the
+method signatures of the receivers in the parent state of this block.`,
 	},
 	Arguments: []cmds.Argument{
 		cmds.StringArg("cid", true, false, "CID of message collection to show"),
 	},
+	Options: []cmds.Option{
+		decodeOption,
+		cmds.StringOption("block", "CID of a block with the message collection as its messages, required by --decode"),
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		cid, err := cid.Decode(req.Arguments[0])
 		if err != nil {
 			return err
 		}
 
-		bmsg, err := env.(*node.Env).ChainAPI.ChainGetMessages(req.Context, cid)
+		chainAPI := env.(*node.Env).ChainAPI
+		if decode, _ := req.Options["decode"].(bool); decode {
+			blockCid, err := includingBlock(req, env, func(blk *block.Block) bool { return blk.Messages.Cid.Equals(cid) })
+			if err != nil {
+				return err
+			}
+			decoded, err := chainAPI.ChainGetDecodedMessages(req.Context, blockCid)
+			if err != nil {
+				return err
+			}
+			return re.Emit(&ShowMessagesResult{Decoded: decoded})
+		}
+
+		bmsg, err := chainAPI.ChainGetMessages(req.Context, cid)
 		if err != nil {
 			return err
 		}
 
-		return re.Emit(bmsg)
+		return re.Emit(&ShowMessagesResult{BlockMessage: bmsg})
 	},
-	Type: &chain.BlockMessage{},
+	Type: &ShowMessagesResult{},
+}
+
+// ReceiptView is a receipt, with --decode the CID of its message and its decoded return value.
+type ReceiptView struct {
+	types.MessageReceipt
+	MessageCid    *cid.Cid    `json:"messageCid,omitempty"`
+	DecodedReturn interface{} `json:"decodedReturn,omitempty"`
+	DecodeError   string      `json:"decodeError,omitempty"`
 }
 
 var showReceiptsCmd = &cmds.Command{
@@ -108,23 +142,79 @@ var showReceiptsCmd = &cmds.Command{
 		Tagline: "Show a filecoin receipt collection by its CID",
 		ShortDescription: `Prints info for all receipts in a collection,
 at the given CID.  MessageReceipt collection CIDs are found in the "ParentMessageReceipts"
-field of the filecoin block header.`,
+field of the filecoin block header. Decoding the return values with --decode needs the
+messages, found from the block given with --block.`,
 	},
 	Arguments: []cmds.Argument{
 		cmds.StringArg("cid", true, false, "CID of receipt collection to show"),
 	},
+	Options: []cmds.Option{
+		decodeOption,
+		cmds.StringOption("block", "CID of a block with the receipt collection as its parent message receipts, required by --decode"),
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		cid, err := cid.Decode(req.Arguments[0])
+		receiptsCid, err := cid.Decode(req.Arguments[0])
 		if err != nil {
 			return err
 		}
 
-		receipts, err := env.(*node.Env).ChainAPI.ChainGetReceipts(req.Context, cid)
+		chainAPI := env.(*node.Env).ChainAPI
+		if decode, _ := req.Options["decode"].(bool); decode {
+			blockCid, err := includingBlock(req, env, func(blk *block.Block) bool { return blk.ParentMessageReceipts.Cid.Equals(receiptsCid) })
+			if err != nil {
+				return err
+			}
+
+			decoded, err := chainAPI.ChainGetDecodedReceipts(req.Context, blockCid)
+			if err != nil {
+				return err
+			}
+			views := make([]ReceiptView, len(decoded))
+			for i, d := range decoded {
+				msgCid := d.MessageCid
+				views[i] = ReceiptView{
+					MessageReceipt: d.Receipt,
+					MessageCid:     &msgCid,
+					DecodedReturn:  d.Return,
+					DecodeError:    d.Error,
+				}
+			}
+			return re.Emit(views)
+		}
+
+		receipts, err := chainAPI.ChainGetReceipts(req.Context, receiptsCid)
 		if err != nil {
 			return err
 		}
 
-		return re.Emit(receipts)
+		views := make([]ReceiptView, len(receipts))
+		for i, receipt := range receipts {
+			views[i] = ReceiptView{MessageReceipt: receipt}
+		}
+		return re.Emit(views)
 	},
-	Type: []types.MessageReceipt{},
+	Type: []ReceiptView{},
+}
+
+// includingBlock returns the CID of the block given with --block, checking that it includes the
+// collection shown.
+func includingBlock(req *cmds.Request, env cmds.Environment, includes func(*block.Block) bool) (cid.Cid, error) {
+	blockOpt, _ := req.Options["block"].(string)
+	if blockOpt == "" {
+		return cid.Undef, errors.New("--decode requires --block")
+	}
+	blockCid, err := cid.Decode(blockOpt)
+	if err != nil {
+		return cid.Undef, err
+	}
+	blk, err := env.(*node.Env).ChainAPI.ChainGetBlock(req.Context, blockCid)
+	if err != nil {
+		return cid.Undef, err
+	}
+	if !includes(blk) {
+		return cid.Undef, errors.Errorf("block %s does not include collection %s", blockCid, req.Arguments[0])
+	}
+	return blockCid, nil
 }
+
+var decodeOption = cmds.BoolOption("decode", "Decode params and return values with the method signatures of the receiving actors")
@@ -0,0 +1,917 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/dline"
+	"github.com/filecoin-project/go-state-types/network"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
+	"github.com/filecoin-project/venus/pkg/state"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var stateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Inspect the state of the chain",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"diff":            stateDiffCmd,
+		"get-actor":       stateGetActorCmd,
+		"market":          stateMarketCmd,
+		"miner":           stateMinerCmd,
+		"network-version": stateNetworkVersionCmd,
+		"power":           statePowerCmd,
+		"supply":          stateSupplyCmd,
+	},
+}
+
+var stateDiffCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the actors changed between the states of two tipsets",
+		ShortDescription: `Lists the actors added, removed and modified between the states of the tipsets
+<pre> and <cur>, with their balance and nonce deltas. Tipsets are given as comma separated
+block CIDs, <cur> defaults to the head.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("pre", true, false, "Comma separated CIDs of the blocks of the first tipset"),
+		cmds.StringArg("cur", false, false, "Comma separated CIDs of the blocks of the second tipset"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("drill", "Include the changes of miner sectors, market deals and power claims"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		chainAPI := env.(*node.Env).ChainAPI
+
+		preKey, err := tipSetKeyFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		var curKey block.TipSetKey
+		if len(req.Arguments) > 1 {
+			curKey, err = tipSetKeyFromString(req.Arguments[1])
+			if err != nil {
+				return err
+			}
+		} else {
+			head, err := chainAPI.ChainHead()
+			if err != nil {
+				return err
+			}
+			curKey = head.Key()
+		}
+
+		drill, _ := req.Options["drill"].(bool)
+		diff, err := chainAPI.StateDiff(req.Context, preKey, curKey, drill)
+		if err != nil {
+			return err
+		}
+		return re.Emit(diff)
+	},
+	Type: state.StateDiff{},
+}
+
+// SupplyResult is the circulating supply of a state in FIL.
+type SupplyResult struct {
+	FilVested      types.FIL
+	FilMined       types.FIL
+	FilBurnt       types.FIL
+	FilLocked      types.FIL
+	FilCirculating types.FIL
+}
+
+var stateSupplyCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the vested, mined, burnt, locked and circulating FIL",
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		cs, err := env.(*node.Env).ChainAPI.StateVMCirculatingSupplyInternal(req.Context, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&SupplyResult{
+			FilVested:      types.FIL(cs.FilVested),
+			FilMined:       types.FIL(cs.FilMined),
+			FilBurnt:       types.FIL(cs.FilBurnt),
+			FilLocked:      types.FIL(cs.FilLocked),
+			FilCirculating: types.FIL(cs.FilCirculating),
+		})
+	},
+	Type: SupplyResult{},
+}
+
+var stateNetworkVersionCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the network version at a tipset, the head by default",
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		nv, err := env.(*node.Env).ChainAPI.StateNetworkVersion(req.Context, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(nv)
+	},
+	Type: network.Version(0),
+}
+
+var stateGetActorCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show an actor and, with --decode, its decoded state",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "Address of the actor"),
+	},
+	Options: []cmds.Option{
+		tipSetOption,
+		decodeStateOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+
+		chainAPI := env.(*node.Env).ChainAPI
+		act, err := chainAPI.StateGetActor(req.Context, addr, key)
+		if err != nil {
+			return err
+		}
+		output := makeActorView(act, addr)
+		if decode, _ := req.Options["decode"].(bool); decode {
+			st, err := chainAPI.StateReadState(req.Context, addr, key)
+			if err != nil {
+				return err
+			}
+			output.Name, output.State = st.Name, st.State
+		}
+		return re.Emit(output)
+	},
+	Type: ActorView{},
+}
+
+// tipSetKeyFromString parses a tipset key given as comma separated block CIDs.
+func tipSetKeyFromString(s string) (block.TipSetKey, error) {
+	cids, err := cidsFromSlice(strings.Split(s, ","))
+	if err != nil {
+		return block.TipSetKey{}, err
+	}
+	return block.NewTipSetKey(cids...), nil
+}
+
+// tipSetOption is the option selecting the tipset a state command reads, the head by default.
+var tipSetOption = cmds.StringOption("tipset", "Comma separated CIDs of the blocks of the tipset to read the state at, the head by default")
+
+// tipSetKeyFromOption parses the tipset option, the empty key selects the head.
+func tipSetKeyFromOption(req *cmds.Request) (block.TipSetKey, error) {
+	s, _ := req.Options["tipset"].(string)
+	if s == "" {
+		return block.TipSetKey{}, nil
+	}
+	return tipSetKeyFromString(s)
+}
+
+// addressArgs parses the address argument and the tipset option.
+func addressArgs(req *cmds.Request) (address.Address, block.TipSetKey, error) {
+	maddr, err := address.NewFromString(req.Arguments[0])
+	if err != nil {
+		return address.Undef, block.TipSetKey{}, err
+	}
+	key, err := tipSetKeyFromOption(req)
+	if err != nil {
+		return address.Undef, block.TipSetKey{}, err
+	}
+	return maddr, key, nil
+}
+
+var minerArg = cmds.StringArg("miner", true, false, "Address of the miner")
+
+var stateMinerCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Inspect the state of a miner",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"info":              stateMinerInfoCmd,
+		"sector-count":      stateMinerSectorCountCmd,
+		"sector":            stateMinerSectorCmd,
+		"precommit":         stateMinerPreCommitCmd,
+		"sectors":           stateMinerSectorsCmd,
+		"proving-deadline":  stateMinerProvingDeadlineCmd,
+		"deadlines":         stateMinerDeadlinesCmd,
+		"partitions":        stateMinerPartitionsCmd,
+		"faults":            stateMinerFaultsCmd,
+		"recoveries":        stateMinerRecoveriesCmd,
+		"available-balance": stateMinerAvailableBalanceCmd,
+		"pledge":            stateMinerPledgeCmd,
+		"power":             stateMinerPowerCmd,
+		"power-history":     stateMinerPowerHistoryCmd,
+	},
+}
+
+var stateMinerInfoCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the owner, worker, control addresses, peer and sector size of a miner",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		info, err := env.(*node.Env).ChainAPI.StateMinerInfo(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(info)
+	},
+	Type: miner.MinerInfo{},
+}
+
+var stateMinerSectorCountCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the number of live, active and faulty sectors of a miner",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		count, err := env.(*node.Env).ChainAPI.StateMinerSectorCount(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(count)
+	},
+	Type: chain.MinerSectors{},
+}
+
+func sectorNumberArg(s string) (abi.SectorNumber, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sector number %s: %s", s, err)
+	}
+	return abi.SectorNumber(n), nil
+}
+
+var stateMinerSectorCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the on chain info of a sector",
+	},
+	Arguments: []cmds.Argument{
+		minerArg,
+		cmds.StringArg("sector", true, false, "Number of the sector"),
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		n, err := sectorNumberArg(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+		info, err := env.(*node.Env).ChainAPI.StateSectorGetInfo(req.Context, maddr, n, key)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return fmt.Errorf("sector %d of miner %s not found", n, maddr)
+		}
+		return re.Emit(info)
+	},
+	Type: miner.SectorOnChainInfo{},
+}
+
+var stateMinerPreCommitCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the pre-commit info of a sector not proven yet",
+	},
+	Arguments: []cmds.Argument{
+		minerArg,
+		cmds.StringArg("sector", true, false, "Number of the sector"),
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		n, err := sectorNumberArg(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+		info, err := env.(*node.Env).ChainAPI.StateSectorPreCommitInfo(req.Context, maddr, n, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(info)
+	},
+	Type: miner.SectorPreCommitOnChainInfo{},
+}
+
+var stateMinerSectorsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the on chain info of the sectors of a miner",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options: []cmds.Option{
+		tipSetOption,
+		cmds.BoolOption("active", "Only list the active sectors"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+
+		chainAPI := env.(*node.Env).ChainAPI
+		var sectors []*miner.SectorOnChainInfo
+		if active, _ := req.Options["active"].(bool); active {
+			sectors, err = chainAPI.StateMinerActiveSectors(req.Context, maddr, key)
+		} else {
+			sectors, err = chainAPI.StateMinerSectors(req.Context, maddr, nil, key)
+		}
+		if err != nil {
+			return err
+		}
+		for _, sector := range sectors {
+			if err := re.Emit(sector); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Type: miner.SectorOnChainInfo{},
+}
+
+var stateMinerProvingDeadlineCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the window PoSt deadline of a miner open at the tipset",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		info, err := env.(*node.Env).ChainAPI.StateMinerProvingDeadline(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(info)
+	},
+	Type: dline.Info{},
+}
+
+var stateMinerDeadlinesCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the window PoSt deadlines of a miner with their proven partitions",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		deadlines, err := env.(*node.Env).ChainAPI.StateMinerDeadlines(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(deadlines)
+	},
+	Type: []chain.Deadline{},
+}
+
+var stateMinerPartitionsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the partitions of a deadline of a miner",
+	},
+	Arguments: []cmds.Argument{
+		minerArg,
+		cmds.StringArg("deadline", true, false, "Index of the deadline"),
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		dlIdx, err := strconv.ParseUint(req.Arguments[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid deadline index %s: %s", req.Arguments[1], err)
+		}
+		partitions, err := env.(*node.Env).ChainAPI.StateMinerPartitions(req.Context, maddr, dlIdx, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(partitions)
+	},
+	Type: []chain.Partition{},
+}
+
+// SectorNumbers is a list of sector numbers.
+type SectorNumbers struct {
+	Count   uint64
+	Sectors []uint64
+}
+
+func sectorNumbers(bf bitfield.BitField) (*SectorNumbers, error) {
+	sectors, err := bf.All(bitfield.MaxEncodedSize)
+	if err != nil {
+		return nil, err
+	}
+	return &SectorNumbers{Count: uint64(len(sectors)), Sectors: sectors}, nil
+}
+
+var stateMinerFaultsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the faulty sectors of a miner",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		faults, err := env.(*node.Env).ChainAPI.StateMinerFaults(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		out, err := sectorNumbers(faults)
+		if err != nil {
+			return err
+		}
+		return re.Emit(out)
+	},
+	Type: SectorNumbers{},
+}
+
+var stateMinerRecoveriesCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the faulty sectors of a miner declared recovered",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		recoveries, err := env.(*node.Env).ChainAPI.StateMinerRecoveries(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		out, err := sectorNumbers(recoveries)
+		if err != nil {
+			return err
+		}
+		return re.Emit(out)
+	},
+	Type: SectorNumbers{},
+}
+
+var stateMinerAvailableBalanceCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the balance a miner can withdraw",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		balance, err := env.(*node.Env).ChainAPI.StateMinerAvailableBalance(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(types.FIL(balance))
+	},
+	Type: types.FIL{},
+}
+
+// MinerPledgeResult is the collateral a miner must lock to seal a sector.
+type MinerPledgeResult struct {
+	PreCommitDeposit types.FIL
+	InitialPledge    types.FIL
+}
+
+var stateMinerPledgeCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Estimate the pre-commit deposit and the initial pledge of a new sector",
+		ShortDescription: `The sector uses the seal proof of the miner, the estimates include a 10% margin for the
+changes of the network until the messages land on chain.`,
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options: []cmds.Option{
+		tipSetOption,
+		cmds.Int64Option("expiration", "Epoch the sector expires at"),
+		cmds.StringOption("deals", "Comma separated IDs of the deals in the sector"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		expiration, ok := req.Options["expiration"].(int64)
+		if !ok {
+			return fmt.Errorf("the expiration of the sector is required")
+		}
+
+		var dealIDs []abi.DealID
+		if deals, _ := req.Options["deals"].(string); deals != "" {
+			for _, s := range strings.Split(deals, ",") {
+				id, err := strconv.ParseUint(s, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid deal id %s: %s", s, err)
+				}
+				dealIDs = append(dealIDs, abi.DealID(id))
+			}
+		}
+
+		chainAPI := env.(*node.Env).ChainAPI
+		info, err := chainAPI.StateMinerInfo(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		pci := miner.SectorPreCommitInfo{
+			SealProof:  info.SealProofType,
+			DealIDs:    dealIDs,
+			Expiration: abi.ChainEpoch(expiration),
+		}
+
+		deposit, err := chainAPI.StateMinerPreCommitDepositForPower(req.Context, maddr, pci, key)
+		if err != nil {
+			return err
+		}
+		pledge, err := chainAPI.StateMinerInitialPledgeCollateral(req.Context, maddr, pci, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&MinerPledgeResult{PreCommitDeposit: types.FIL(deposit), InitialPledge: types.FIL(pledge)})
+	},
+	Type: MinerPledgeResult{},
+}
+
+var stateMarketCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Inspect the state of the storage market and manage escrow",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"deals":        stateMarketDealsCmd,
+		"deal":         stateMarketDealCmd,
+		"balance":      stateMarketBalanceCmd,
+		"participants": stateMarketParticipantsCmd,
+		"add-funds":    stateMarketAddFundsCmd,
+		"withdraw":     stateMarketWithdrawCmd,
+	},
+}
+
+var stateMarketDealsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the storage deals, ordered by id",
+		ShortDescription: `Lists the deals matching the client, provider and epoch range options. The epoch
+range selects the deals active at some epoch within it. Use --offset and --limit to page
+through the deals.`,
+	},
+	Options: []cmds.Option{
+		tipSetOption,
+		cmds.StringOption("client", "Only list the deals of this client"),
+		cmds.StringOption("provider", "Only list the deals of this provider"),
+		cmds.Int64Option("from-epoch", "Only list the deals ending at or after this epoch"),
+		cmds.Int64Option("to-epoch", "Only list the deals starting at or before this epoch"),
+		cmds.IntOption("offset", "Number of matching deals to skip"),
+		cmds.IntOption("limit", "Maximum number of deals to list, 0 for all"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+
+		var filter chain.MarketDealFilter
+		if filter.Client, err = optionalAddr(req.Options["client"]); err != nil {
+			return err
+		}
+		if filter.Provider, err = optionalAddr(req.Options["provider"]); err != nil {
+			return err
+		}
+		from, _ := req.Options["from-epoch"].(int64)
+		filter.FromEpoch = abi.ChainEpoch(from)
+		to, _ := req.Options["to-epoch"].(int64)
+		filter.ToEpoch = abi.ChainEpoch(to)
+		filter.Offset, _ = req.Options["offset"].(int)
+		filter.Limit, _ = req.Options["limit"].(int)
+		if filter.Offset < 0 || filter.Limit < 0 {
+			return fmt.Errorf("offset and limit must not be negative")
+		}
+
+		deals, err := env.(*node.Env).ChainAPI.StateMarketDeals(req.Context, filter, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(deals)
+	},
+	Type: []*chain.MarketDealInfo{},
+}
+
+var stateMarketDealCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the proposal and the state of a storage deal",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("deal", true, false, "Id of the deal"),
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		id, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid deal id %s: %s", req.Arguments[0], err)
+		}
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		deal, err := env.(*node.Env).ChainAPI.StateMarketStorageDeal(req.Context, abi.DealID(id), key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(deal)
+	},
+	Type: state.MarketDeal{},
+}
+
+var stateMarketBalanceCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the escrow and the locked balance of a market participant",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "Address of the client or provider"),
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		balance, err := env.(*node.Env).ChainAPI.StateMarketBalance(req.Context, addr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(balance)
+	},
+	Type: chain.MarketBalance{},
+}
+
+var stateMarketParticipantsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the balances of all the market participants by ID address",
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		participants, err := env.(*node.Env).ChainAPI.StateMarketParticipants(req.Context, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(participants)
+	},
+	Type: map[string]chain.MarketBalance{},
+}
+
+// marketEscrowArgs parses the amount argument and the address, from and gas options of the
+// escrow commands. The escrow address defaults to the sender.
+func marketEscrowArgs(req *cmds.Request, env cmds.Environment) (from, addr address.Address, amt, feecap, premium types.AttoFIL, gasLimit types.Unit, err error) {
+	var ok bool
+	amt, ok = types.NewAttoFILFromFILString(req.Arguments[0])
+	if !ok {
+		err = fmt.Errorf("mal-formed amount %s", req.Arguments[0])
+		return
+	}
+	if from, err = fromAddrOrDefault(req, env); err != nil {
+		return
+	}
+	if addr, err = optionalAddr(req.Options["address"]); err != nil {
+		return
+	}
+	if addr.Empty() {
+		addr = from
+	}
+	feecap, premium, gasLimit, _, err = parseGasOptions(req)
+	return
+}
+
+var marketEscrowOptions = []cmds.Option{
+	cmds.StringOption("from", "Address to send the message from, the default wallet address by default"),
+	cmds.StringOption("address", "Client or provider address of the escrow, the sender by default"),
+	feecapOption,
+	premiumOption,
+	limitOption,
+}
+
+var stateMarketAddFundsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Add funds to the market escrow of a client or provider",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("amount", true, false, "Amount to add in FIL"),
+	},
+	Options: marketEscrowOptions,
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		from, addr, amt, feecap, premium, gasLimit, err := marketEscrowArgs(req, env)
+		if err != nil {
+			return err
+		}
+		c, err := env.(*node.Env).MessagingAPI.MarketAddBalance(req.Context, from, addr, amt, feecap, premium, gasLimit)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&MessageSendResult{
+			Cid:     c,
+			GasUsed: types.NewGas(0),
+		})
+	},
+	Type: &MessageSendResult{},
+}
+
+var stateMarketWithdrawCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Withdraw funds not locked in deals from the market escrow of a client or provider",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("amount", true, false, "Amount to withdraw in FIL"),
+	},
+	Options: marketEscrowOptions,
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		from, addr, amt, feecap, premium, gasLimit, err := marketEscrowArgs(req, env)
+		if err != nil {
+			return err
+		}
+		c, err := env.(*node.Env).MessagingAPI.MarketWithdrawBalance(req.Context, from, addr, amt, feecap, premium, gasLimit)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&MessageSendResult{
+			Cid:     c,
+			GasUsed: types.NewGas(0),
+		})
+	},
+	Type: &MessageSendResult{},
+}
+
+// MinerPowerShare is the power of a miner and its share of the network quality adjusted power.
+type MinerPowerShare struct {
+	Address         address.Address
+	RawBytePower    abi.StoragePower
+	QualityAdjPower abi.StoragePower
+	// Share is the percentage of the network quality adjusted power.
+	Share float64
+}
+
+// PowerResult is the power of the network and of its largest miners.
+type PowerResult struct {
+	TotalPower power.Claim
+	MinerCount int
+	Miners     []*MinerPowerShare
+}
+
+var statePowerCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the network power and the miners with the most quality adjusted power",
+	},
+	Options: []cmds.Option{
+		tipSetOption,
+		cmds.IntOption("top", "Number of miners to list").WithDefault(10),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		top, _ := req.Options["top"].(int)
+		if top < 0 {
+			return fmt.Errorf("top must not be negative")
+		}
+
+		tm, err := env.(*node.Env).ChainAPI.StateTopMiners(req.Context, top, key)
+		if err != nil {
+			return err
+		}
+		out := &PowerResult{TotalPower: tm.TotalPower, MinerCount: tm.MinerCount}
+		shares := make([]*MinerPowerShare, 0, len(tm.Miners))
+		for _, mc := range tm.Miners {
+			shares = append(shares, &MinerPowerShare{
+				Address:         mc.Address,
+				RawBytePower:    mc.RawBytePower,
+				QualityAdjPower: mc.QualityAdjPower,
+				Share:           powerShare(mc.QualityAdjPower, tm.TotalPower.QualityAdjPower),
+			})
+		}
+		out.Miners = shares
+		return re.Emit(out)
+	},
+	Type: PowerResult{},
+}
+
+// powerShare returns qa as a percentage of total.
+func powerShare(qa, total abi.StoragePower) float64 {
+	if total.Sign() <= 0 {
+		return 0
+	}
+	share, _ := new(big.Float).Quo(new(big.Float).SetInt(qa.Int), new(big.Float).SetInt(total.Int)).Float64()
+	return share * 100
+}
+
+var stateMinerPowerCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the raw and quality adjusted power of a miner and of the network",
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options:   []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		mp, err := env.(*node.Env).ChainAPI.StateMinerPower(req.Context, maddr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(mp)
+	},
+	Type: chain.MinerPower{},
+}
+
+var stateMinerPowerHistoryCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the power of a miner and of the network over an epoch range",
+		ShortDescription: `Samples the power every --step epochs from --from to --to of the current chain,
+--to defaults to the head.`,
+	},
+	Arguments: []cmds.Argument{minerArg},
+	Options: []cmds.Option{
+		cmds.Int64Option("from", "First epoch sampled"),
+		cmds.Int64Option("to", "Last epoch sampled, the head by default"),
+		cmds.Int64Option("step", "Number of epochs between samples").WithDefault(int64(2880)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		chainAPI := env.(*node.Env).ChainAPI
+		from, _ := req.Options["from"].(int64)
+		to, ok := req.Options["to"].(int64)
+		if !ok {
+			head, err := chainAPI.ChainHead()
+			if err != nil {
+				return err
+			}
+			to = int64(head.EnsureHeight())
+		}
+		step, _ := req.Options["step"].(int64)
+
+		samples, err := chainAPI.StateMinerPowerHistory(req.Context, maddr, abi.ChainEpoch(from), abi.ChainEpoch(to), abi.ChainEpoch(step))
+		if err != nil {
+			return err
+		}
+		return re.Emit(samples)
+	},
+	Type: []*chain.MinerPowerSample{},
+}
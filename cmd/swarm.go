@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/filecoin-project/venus/app/node"
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	"github.com/libp2p/go-libp2p-core/peer"
@@ -19,8 +21,14 @@ libp2p peers on the internet.
 `,
 	},
 	Subcommands: map[string]*cmds.Command{
-		"connect": swarmConnectCmd,
-		"peers":   swarmPeersCmd,
+		"ban":       swarmBanCmd,
+		"connect":   swarmConnectCmd,
+		"peer-info": swarmPeerInfoCmd,
+		"peers":     swarmPeersCmd,
+		"protect":   swarmProtectCmd,
+		"scores":    swarmScoresCmd,
+		"unban":     swarmUnbanCmd,
+		"unprotect": swarmUnprotectCmd,
 	},
 }
 
@@ -51,6 +59,32 @@ var swarmPeersCmd = &cmds.Command{
 	Type: net.SwarmConnInfos{},
 }
 
+var swarmPeerInfoCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show what the node knows about a peer.",
+		ShortDescription: `
+'venus swarm peer-info' shows the addresses, agent, protocols, latency and
+bandwidth of a peer, the chain it claimed in the hello protocol and the
+statistics of the chain exchange requests sent to it.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("peer", true, false, "Peer id or p2p multiaddr of the peer."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		peers, err := net.ParsePeerIDs(req.Arguments)
+		if err != nil {
+			return err
+		}
+		info, err := env.(*node.Env).NetworkAPI.NetworkPeerInfo(peers[0])
+		if err != nil {
+			return err
+		}
+		return re.Emit(info)
+	},
+	Type: net.PeerInfo{},
+}
+
 var swarmConnectCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Open connection to a given address.",
@@ -84,3 +118,134 @@ venus swarm connect /ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUEnRQAwe
 	},
 	Type: peer.ID(""),
 }
+
+var swarmProtectCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Protect peers from having their connections trimmed.",
+		ShortDescription: `
+'venus swarm protect' protects peers from the connection manager, which closes
+connections of the least useful peers when there are too many. Without
+arguments, lists the protected peers.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("peers", false, true, "Peer ids or p2p multiaddrs of the peers to protect."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		networkAPI := env.(*node.Env).NetworkAPI
+		if len(req.Arguments) > 0 {
+			peers, err := net.ParsePeerIDs(req.Arguments)
+			if err != nil {
+				return err
+			}
+			if err := networkAPI.NetworkProtect(peers); err != nil {
+				return err
+			}
+		}
+
+		protected, err := networkAPI.NetworkProtected()
+		if err != nil {
+			return err
+		}
+		for _, p := range protected {
+			if err := re.Emit(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Type: peer.ID(""),
+}
+
+var swarmUnprotectCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Remove the protection of peers.",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("peers", true, true, "Peer ids or p2p multiaddrs of the peers to unprotect."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		peers, err := net.ParsePeerIDs(req.Arguments)
+		if err != nil {
+			return err
+		}
+		return env.(*node.Env).NetworkAPI.NetworkUnprotect(peers)
+	},
+}
+
+var swarmBanCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Ban peers.",
+		ShortDescription: `
+'venus swarm ban' closes the connections to peers and refuses them until the
+ban expires. Peers are also banned automatically when they send too many invalid
+blocks or messages. Without arguments, lists the banned peers.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("peers", false, true, "Peer ids or p2p multiaddrs of the peers to ban."),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("duration", "How long the peers are banned, must be positive").WithDefault("1h"),
+		cmds.StringOption("reason", "Why the peers are banned").WithDefault("banned by the operator"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		networkAPI := env.(*node.Env).NetworkAPI
+		if len(req.Arguments) > 0 {
+			peers, err := net.ParsePeerIDs(req.Arguments)
+			if err != nil {
+				return err
+			}
+			d, err := time.ParseDuration(req.Options["duration"].(string))
+			if err != nil {
+				return err
+			}
+			if err := networkAPI.NetworkBan(peers, d, req.Options["reason"].(string)); err != nil {
+				return err
+			}
+		}
+
+		for _, entry := range networkAPI.NetworkBans() {
+			if err := re.Emit(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Type: net.BanEntry{},
+}
+
+var swarmUnbanCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Lift the ban of peers.",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("peers", true, true, "Peer ids or p2p multiaddrs of the peers to unban."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		peers, err := net.ParsePeerIDs(req.Arguments)
+		if err != nil {
+			return err
+		}
+		return env.(*node.Env).NetworkAPI.NetworkUnban(peers)
+	},
+}
+
+var swarmScoresCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the gossip scores of peers.",
+		ShortDescription: `
+'venus swarm scores' lists the gossipsub score of each peer, lowest first, with
+the penalties recorded for the invalid blocks and messages it sent.
+`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		for _, score := range env.(*node.Env).NetworkAPI.NetworkScores() {
+			if err := re.Emit(score); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Type: net.PeerScore{},
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/filecoin-project/venus/app/node"
 	"github.com/filecoin-project/venus/app/node/test"
 	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
@@ -36,3 +38,22 @@ func TestSwarmConnectPeersInvalid(t *testing.T) {
 		"swarm", "connect", "/ip4/hello",
 	)
 }
+
+func TestSwarmBanInvalidDuration(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	ctx := context.Background()
+	builder := test.NewNodeBuilder(t)
+
+	_, cmdClient, done := builder.BuildAndStartAPI(ctx)
+	defer done()
+
+	for _, d := range []string{"0s", "-1h"} {
+		cmdClient.RunFail(ctx, "ban duration must be positive",
+			"swarm", "ban", "--duration="+d, "QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt",
+		)
+	}
+	// Nothing was banned.
+	out := cmdClient.RunSuccess(ctx, "swarm", "ban").ReadStdoutTrimNewlines()
+	assert.NotContains(t, out, "QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/submodule/chain"
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var verifregCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Interact with the verified registry actor",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"add-verifier":        verifregAddVerifierCmd,
+		"add-verified-client": verifregAddVerifiedClientCmd,
+		"check-verifier":      verifregCheckVerifierCmd,
+		"check-client":        verifregCheckClientCmd,
+		"list-verifiers":      verifregListVerifiersCmd,
+		"list-clients":        verifregListClientsCmd,
+		"root-key":            verifregRootKeyCmd,
+	},
+}
+
+// DataCapResult is the datacap of a verifier or a verified client, nil when the
+// address is neither.
+type DataCapResult struct {
+	Address address.Address
+	DataCap *abi.StoragePower
+}
+
+func dataCapArgs(req *cmds.Request) (address.Address, abi.StoragePower, error) {
+	addr, err := address.NewFromString(req.Arguments[0])
+	if err != nil {
+		return address.Undef, abi.StoragePower{}, err
+	}
+	allowance, err := big.FromString(req.Arguments[1])
+	if err != nil {
+		return address.Undef, abi.StoragePower{}, fmt.Errorf("invalid allowance %s: %s", req.Arguments[1], err)
+	}
+	return addr, allowance, nil
+}
+
+var verifregAddVerifierCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Propose to the root key to make an address a verifier",
+		ShortDescription: `The root key of the verified registry is a multisig. The command sends, from one
+of its signers, a proposal to make <verifier> a verifier able to grant <allowance> bytes of
+datacap. The proposal is executed once approved by enough signers.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("verifier", true, false, "Address of the verifier"),
+		cmds.StringArg("allowance", true, false, "Datacap in bytes the verifier can grant"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("from", "Signer of the root key to send the proposal from, the default wallet address by default"),
+		feecapOption,
+		premiumOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		verifier, allowance, err := dataCapArgs(req)
+		if err != nil {
+			return err
+		}
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+		feecap, premium, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := env.(*node.Env).MessagingAPI.VerifregAddVerifier(req.Context, from, verifier, allowance, feecap, premium, gasLimit)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&MessageSendResult{
+			Cid:     c,
+			GasUsed: types.NewGas(0),
+		})
+	},
+	Type: &MessageSendResult{},
+}
+
+var verifregAddVerifiedClientCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Grant datacap to a client from the datacap of a verifier",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("client", true, false, "Address of the client"),
+		cmds.StringArg("allowance", true, false, "Datacap in bytes to grant"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("from", "Verifier to send the message from, the default wallet address by default"),
+		feecapOption,
+		premiumOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		client, allowance, err := dataCapArgs(req)
+		if err != nil {
+			return err
+		}
+		verifier, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+		feecap, premium, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		// Fail early rather than on chain when the verifier cannot cover the allowance.
+		dcap, err := env.(*node.Env).ChainAPI.StateVerifierStatus(req.Context, verifier, block.TipSetKey{})
+		if err != nil {
+			return err
+		}
+		if dcap == nil {
+			return fmt.Errorf("%s is not a verifier", verifier)
+		}
+		if dcap.LessThan(allowance) {
+			return fmt.Errorf("verifier %s has %s bytes of datacap left, less than %s", verifier, dcap, allowance)
+		}
+
+		c, err := env.(*node.Env).MessagingAPI.VerifregAddVerifiedClient(req.Context, verifier, client, allowance, feecap, premium, gasLimit)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&MessageSendResult{
+			Cid:     c,
+			GasUsed: types.NewGas(0),
+		})
+	},
+	Type: &MessageSendResult{},
+}
+
+var verifregCheckVerifierCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the datacap a verifier can still grant",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("verifier", true, false, "Address of the verifier"),
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		dcap, err := env.(*node.Env).ChainAPI.StateVerifierStatus(req.Context, addr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&DataCapResult{Address: addr, DataCap: dcap})
+	},
+	Type: DataCapResult{},
+}
+
+var verifregCheckClientCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the datacap a verified client can still use in deals",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("client", true, false, "Address of the client"),
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, key, err := addressArgs(req)
+		if err != nil {
+			return err
+		}
+		dcap, err := env.(*node.Env).ChainAPI.StateVerifiedClientStatus(req.Context, addr, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&DataCapResult{Address: addr, DataCap: dcap})
+	},
+	Type: DataCapResult{},
+}
+
+var verifregListVerifiersCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the verifiers and their remaining datacap",
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		verifiers, err := env.(*node.Env).ChainAPI.StateListVerifiers(req.Context, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(verifiers)
+	},
+	Type: []chain.DataCap{},
+}
+
+var verifregListClientsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List the verified clients and their remaining datacap",
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		clients, err := env.(*node.Env).ChainAPI.StateListVerifiedClients(req.Context, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(clients)
+	},
+	Type: []chain.DataCap{},
+}
+
+var verifregRootKeyCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Show the root key of the verified registry",
+	},
+	Options: []cmds.Option{tipSetOption},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		key, err := tipSetKeyFromOption(req)
+		if err != nil {
+			return err
+		}
+		rootKey, err := env.(*node.Env).ChainAPI.StateVerifiedRegistryRootKey(req.Context, key)
+		if err != nil {
+			return err
+		}
+		return re.Emit(rootKey)
+	},
+	Type: address.Address{},
+}
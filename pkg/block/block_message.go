@@ -8,3 +8,10 @@ type BlockMessagesInfo struct { //nolint
 	SecpkMessages []types.ChainMsg
 	Block         *Block
 }
+
+// BlockMsg is a block header with its messages, as produced by a block producer.
+type BlockMsg struct { //nolint
+	Header        *Block
+	BlsMessages   []*types.UnsignedMessage
+	SecpkMessages []*types.SignedMessage
+}
@@ -0,0 +1,112 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+)
+
+var (
+	// ErrForkBelowCheckPoint is returned when a chain forks from the current chain below the checkpoint.
+	ErrForkBelowCheckPoint = errors.New("fork below checkpoint")
+	// ErrCheckPointNotInChain is returned when setting a checkpoint which is not an ancestor of the head.
+	ErrCheckPointNotInChain = errors.New("checkpoint is not an ancestor of the current head")
+)
+
+// UpdateCheckPoint sets the checkpoint to the tipset specified by key and persists it.
+// The tipset must be the head or one of its ancestors, a checkpoint is never used to
+// switch to another chain.
+func (store *Store) UpdateCheckPoint(ctx context.Context, key block.TipSetKey) error {
+	ts, err := store.GetTipSet(key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load checkpoint tipset %s", key)
+	}
+
+	head, err := store.GetTipSet(store.GetHead())
+	if err != nil {
+		return err
+	}
+	inChain, err := store.GetTipSetByHeight(ctx, head, ts.EnsureHeight(), false)
+	if err != nil {
+		return err
+	}
+	if !inChain.Equals(ts) {
+		return ErrCheckPointNotInChain
+	}
+
+	if err := store.WriteCheckPoint(ctx, key); err != nil {
+		return err
+	}
+	store.SetCheckPoint(key)
+	return nil
+}
+
+// ClearCheckPoint removes the persisted checkpoint and resets it to the genesis tipset.
+func (store *Store) ClearCheckPoint(ctx context.Context) error {
+	logStore.Infof("ClearCheckPoint")
+	if err := store.ds.Delete(CheckPoint); err != nil {
+		return err
+	}
+	store.SetCheckPoint(block.NewTipSetKey(store.genesis))
+	return nil
+}
+
+// GetCheckPointTipSet returns the checkpoint tipset.
+func (store *Store) GetCheckPointTipSet() (*block.TipSet, error) {
+	return store.GetTipSet(store.GetCheckPoint())
+}
+
+// CheckFork returns ErrForkBelowCheckPoint if the common ancestor of cur and candidate
+// is below the checkpoint, i.e. if switching from cur to candidate would revert it.
+func (store *Store) CheckFork(ctx context.Context, cur, candidate *block.TipSet) error {
+	cpTs, err := store.GetCheckPointTipSet()
+	if err != nil {
+		return err
+	}
+	if cpTs.EnsureHeight() == 0 {
+		return nil
+	}
+
+	ancestor, err := FindCommonAncestor(IterAncestors(ctx, store, cur), IterAncestors(ctx, store, candidate))
+	if err != nil {
+		return err
+	}
+	if ancestor.EnsureHeight() < cpTs.EnsureHeight() {
+		return errors.Wrapf(ErrForkBelowCheckPoint, "common ancestor %d, checkpoint %d", ancestor.EnsureHeight(), cpTs.EnsureHeight())
+	}
+	return nil
+}
+
+// AdvanceCheckPoint moves the checkpoint to the tipset `distance` epochs behind head on
+// head's chain, if that tipset is higher than the current checkpoint.
+func (store *Store) AdvanceCheckPoint(ctx context.Context, head *block.TipSet, distance abi.ChainEpoch) error {
+	target := head.EnsureHeight() - distance
+	if target <= 0 {
+		return nil
+	}
+
+	cpTs, err := store.GetCheckPointTipSet()
+	if err != nil {
+		return err
+	}
+	if target <= cpTs.EnsureHeight() {
+		return nil
+	}
+
+	ts, err := store.GetTipSetByHeight(ctx, head, target, true)
+	if err != nil {
+		return err
+	}
+	if ts.EnsureHeight() <= cpTs.EnsureHeight() {
+		return nil
+	}
+
+	if err := store.WriteCheckPoint(ctx, ts.Key()); err != nil {
+		return err
+	}
+	store.SetCheckPoint(ts.Key())
+	return nil
+}
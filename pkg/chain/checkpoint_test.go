@@ -0,0 +1,71 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestCheckPoint(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	genTS := builder.Genesis()
+	r := builder.Repo()
+	cs := chain.NewStore(r.Datastore(), builder.Cstore(), builder.BlockStore(), chain.NewStatusReporter(), genTS.At(0).Cid())
+
+	// genesis -> link1 -> link2 -> link3
+	//         \-> fork1 -> fork2
+	link1 := builder.AppendOn(genTS, 1)
+	link2 := builder.AppendOn(link1, 1)
+	link3 := builder.AppendOn(link2, 2)
+	fork1 := builder.AppendOn(genTS, 1)
+	fork2 := builder.AppendOn(fork1, 1)
+	require.NoError(t, cs.SetHead(ctx, link3))
+
+	t.Run("defaults to genesis", func(t *testing.T) {
+		cpTs, err := cs.GetCheckPointTipSet()
+		require.NoError(t, err)
+		assert.True(t, cpTs.Equals(genTS))
+		assert.NoError(t, cs.CheckFork(ctx, link3, fork2))
+	})
+
+	t.Run("refuses tipsets off the current chain", func(t *testing.T) {
+		err := cs.UpdateCheckPoint(ctx, fork1.Key())
+		assert.Equal(t, chain.ErrCheckPointNotInChain, err)
+	})
+
+	t.Run("refuses forks below the checkpoint", func(t *testing.T) {
+		require.NoError(t, cs.UpdateCheckPoint(ctx, link2.Key()))
+		assert.Equal(t, link2.Key(), cs.GetCheckPoint())
+
+		err := cs.CheckFork(ctx, link3, fork2)
+		assert.Equal(t, chain.ErrForkBelowCheckPoint, errors.Cause(err))
+
+		link4 := builder.AppendOn(link3, 1)
+		assert.NoError(t, cs.CheckFork(ctx, link3, link4))
+	})
+
+	t.Run("clear resets to genesis", func(t *testing.T) {
+		require.NoError(t, cs.ClearCheckPoint(ctx))
+		assert.Equal(t, genTS.Key(), cs.GetCheckPoint())
+		assert.NoError(t, cs.CheckFork(ctx, link3, fork2))
+	})
+
+	t.Run("advances behind the head", func(t *testing.T) {
+		require.NoError(t, cs.AdvanceCheckPoint(ctx, link3, 1))
+		assert.Equal(t, link2.Key(), cs.GetCheckPoint())
+
+		// never moves backwards
+		require.NoError(t, cs.AdvanceCheckPoint(ctx, link3, 2))
+		assert.Equal(t, link2.Key(), cs.GetCheckPoint())
+	})
+}
@@ -0,0 +1,198 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/journal"
+	"github.com/filecoin-project/venus/pkg/metrics"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+// ReorgHistoryKey is the key at which the recent reorgs are written in the datastore.
+var ReorgHistoryKey = datastore.NewKey("/chain/reorgs")
+
+// DefaultReorgHistoryLimit is the number of reorgs kept in the history.
+const DefaultReorgHistoryLimit = 100
+
+var (
+	reorgDepth         *metrics.Int64Histogram
+	reorgDroppedBlocks *metrics.Int64Histogram
+)
+
+func init() {
+	bounds := []float64{1, 2, 3, 5, 10, 20, 50, 100, 200, 500, 900}
+	reorgDepth = metrics.NewInt64Histogram("chain/reorg_depth", "The number of epochs reverted by a reorg.", bounds)
+	reorgDroppedBlocks = metrics.NewInt64Histogram("chain/reorg_dropped_blocks", "The number of blocks dropped by a reorg.", bounds)
+}
+
+// ReorgBlock identifies a block dropped or applied by a reorg.
+type ReorgBlock struct {
+	Cid    cid.Cid
+	Miner  address.Address
+	Height abi.ChainEpoch
+}
+
+// ReorgEvent describes a change of the head to a tipset which does not descend from the
+// previous head.
+type ReorgEvent struct {
+	Time           time.Time
+	OldHead        block.TipSetKey
+	OldHeight      abi.ChainEpoch
+	NewHead        block.TipSetKey
+	NewHeight      abi.ChainEpoch
+	CommonAncestor block.TipSetKey
+	AncestorHeight abi.ChainEpoch
+	// Depth is the number of epochs reverted from the old head to the common ancestor.
+	Depth   abi.ChainEpoch
+	Dropped []ReorgBlock
+	Applied []ReorgBlock
+}
+
+// ReorgHistory keeps a bounded list of the most recent reorgs, persisted in the datastore.
+type ReorgHistory struct {
+	ds    repo.Datastore
+	limit int
+
+	lk     sync.Mutex
+	events []*ReorgEvent
+}
+
+// NewReorgHistory loads the reorg history from the datastore.
+func NewReorgHistory(ds repo.Datastore, limit int) *ReorgHistory {
+	h := &ReorgHistory{
+		ds:    ds,
+		limit: limit,
+	}
+
+	val, err := ds.Get(ReorgHistoryKey)
+	if err == nil {
+		err = json.Unmarshal(val, &h.events)
+	}
+	if err != nil && err != datastore.ErrNotFound {
+		logStore.Warnf("failed to load reorg history: %s", err)
+	}
+	return h
+}
+
+// Add appends an event to the history, dropping the oldest events beyond the limit.
+func (h *ReorgHistory) Add(event *ReorgEvent) error {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+
+	h.events = append(h.events, event)
+	if len(h.events) > h.limit {
+		h.events = h.events[len(h.events)-h.limit:]
+	}
+
+	val, err := json.Marshal(h.events)
+	if err != nil {
+		return err
+	}
+	return h.ds.Put(ReorgHistoryKey, val)
+}
+
+// List returns up to `count` of the most recent events, newest first.
+// All events are returned if count is not positive.
+func (h *ReorgHistory) List(count int) []*ReorgEvent {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+
+	if count <= 0 || count > len(h.events) {
+		count = len(h.events)
+	}
+	out := make([]*ReorgEvent, 0, count)
+	for i := len(h.events) - 1; i >= len(h.events)-count; i-- {
+		out = append(out, h.events[i])
+	}
+	return out
+}
+
+// NewReorgEvent describes the reorg reverting `dropped` and applying `applied`, both
+// ordered by ascending height.
+func NewReorgEvent(store TipSetProvider, dropped, applied []*block.TipSet) (*ReorgEvent, error) {
+	if len(dropped) == 0 {
+		return nil, errors.New("a reorg drops at least one tipset")
+	}
+
+	ancestor, err := store.GetTipSet(dropped[0].EnsureParents())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load common ancestor")
+	}
+
+	oldHead := dropped[len(dropped)-1]
+	newHead := ancestor
+	if len(applied) > 0 {
+		newHead = applied[len(applied)-1]
+	}
+
+	return &ReorgEvent{
+		Time:           time.Now(),
+		OldHead:        oldHead.Key(),
+		OldHeight:      oldHead.EnsureHeight(),
+		NewHead:        newHead.Key(),
+		NewHeight:      newHead.EnsureHeight(),
+		CommonAncestor: ancestor.Key(),
+		AncestorHeight: ancestor.EnsureHeight(),
+		Depth:          oldHead.EnsureHeight() - ancestor.EnsureHeight(),
+		Dropped:        reorgBlocks(dropped),
+		Applied:        reorgBlocks(applied),
+	}, nil
+}
+
+func reorgBlocks(tss []*block.TipSet) []ReorgBlock {
+	var out []ReorgBlock
+	for _, ts := range tss {
+		for _, blk := range ts.Blocks() {
+			out = append(out, ReorgBlock{
+				Cid:    blk.Cid(),
+				Miner:  blk.Miner,
+				Height: blk.Height,
+			})
+		}
+	}
+	return out
+}
+
+// recordReorg journals the reorg, updates the reorg metrics and adds it to the history.
+func (store *Store) recordReorg(ctx context.Context, dropped, applied []*block.TipSet) {
+	event, err := NewReorgEvent(store, dropped, applied)
+	if err != nil {
+		logStore.Warnf("failed to describe reorg: %s", err)
+		return
+	}
+
+	store.journal.Write("reorg",
+		"oldHead", event.OldHead,
+		"newHead", event.NewHead,
+		"commonAncestor", event.CommonAncestor,
+		"depth", event.Depth,
+		"dropped", event.Dropped,
+		"applied", event.Applied,
+	)
+	reorgDepth.Record(ctx, int64(event.Depth))
+	reorgDroppedBlocks.Record(ctx, int64(len(event.Dropped)))
+
+	if err := store.reorgHistory.Add(event); err != nil {
+		logStore.Warnf("failed to persist reorg: %s", err)
+	}
+}
+
+// SetJournal sets the writer reorgs of the head are journaled to.
+func (store *Store) SetJournal(w journal.Writer) {
+	store.journal = w
+}
+
+// Reorgs returns up to `count` of the most recent reorgs of the head, newest first.
+func (store *Store) Reorgs(count int) []*ReorgEvent {
+	return store.reorgHistory.List(count)
+}
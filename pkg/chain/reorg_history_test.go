@@ -0,0 +1,64 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestReorgHistory(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	genTS := builder.Genesis()
+	r := builder.Repo()
+	cs := chain.NewStore(r.Datastore(), builder.Cstore(), builder.BlockStore(), chain.NewStatusReporter(), genTS.At(0).Cid())
+
+	// genesis -> link1 -> link2
+	//         \-> fork1 -> fork2 -> fork3
+	link1 := builder.AppendOn(genTS, 1)
+	link2 := builder.AppendOn(link1, 2)
+	fork3 := builder.AppendManyOn(3, genTS)
+
+	require.NoError(t, cs.SetHead(ctx, link2))
+	require.NoError(t, cs.SetHead(ctx, fork3))
+
+	require.Eventually(t, func() bool { return len(cs.Reorgs(0)) == 1 }, time.Second, 10*time.Millisecond)
+	event := cs.Reorgs(0)[0]
+	assert.Equal(t, link2.Key(), event.OldHead)
+	assert.Equal(t, fork3.Key(), event.NewHead)
+	assert.Equal(t, genTS.Key(), event.CommonAncestor)
+	assert.Equal(t, abi.ChainEpoch(2), event.Depth)
+	assert.Len(t, event.Dropped, 3)
+	assert.Len(t, event.Applied, 3)
+
+	// the history survives a restart
+	reloaded := chain.NewReorgHistory(r.Datastore(), chain.DefaultReorgHistoryLimit)
+	require.Len(t, reloaded.List(0), 1)
+	assert.Equal(t, event.OldHead, reloaded.List(0)[0].OldHead)
+}
+
+func TestReorgHistoryLimit(t *testing.T) {
+	tf.UnitTest(t)
+
+	history := chain.NewReorgHistory(repo.NewInMemoryRepo().ChainDatastore(), 2)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, history.Add(&chain.ReorgEvent{Depth: abi.ChainEpoch(i)}))
+	}
+
+	events := history.List(0)
+	require.Len(t, events, 2)
+	assert.Equal(t, abi.ChainEpoch(3), events[0].Depth)
+	assert.Equal(t, abi.ChainEpoch(2), events[1].Depth)
+	assert.Len(t, history.List(1), 1)
+}
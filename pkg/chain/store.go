@@ -24,6 +24,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/cborutil"
 	"github.com/filecoin-project/venus/pkg/enccid"
 	"github.com/filecoin-project/venus/pkg/encoding"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/metrics/tracing"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/vm/state"
@@ -111,7 +112,7 @@ type Store struct {
 	head *block.TipSet
 
 	checkPoint block.TipSetKey
-	// Protects head and genesisCid.
+	// Protects head, checkPoint and genesisCid.
 	mu sync.RWMutex
 
 	// headEvents is a pubsub channel that publishes an event every time the head changes.
@@ -135,6 +136,11 @@ type Store struct {
 	notifees []ReorgNotifee
 
 	reorgCh chan reorg
+
+	// journal records reorgs of the head.
+	journal journal.Writer
+	// reorgHistory keeps the most recent reorgs of the head.
+	reorgHistory *ReorgHistory
 }
 
 // NewStore constructs a new default store.
@@ -157,6 +163,8 @@ func NewStore(ds repo.Datastore,
 		reporter:            sr,
 		chainIndex:          NewChainIndex(tipsetProvider.GetTipSet),
 		notifees:            []ReorgNotifee{},
+		journal:             journal.NewNoopJournal().Topic("chain"),
+		reorgHistory:        NewReorgHistory(ds, DefaultReorgHistoryLimit),
 	}
 
 	val, err := store.ds.Get(CheckPoint)
@@ -586,6 +594,10 @@ func (store *Store) reorgWorker(ctx context.Context) chan reorg {
 		for {
 			select {
 			case r := <-out:
+				if len(r.old) > 0 {
+					store.recordReorg(ctx, r.old, r.new)
+				}
+
 				var toremove map[int]struct{}
 				for i, hcf := range notifees {
 					err := hcf(r.old, r.new)
@@ -798,6 +810,8 @@ func (store *Store) Import(r io.Reader) (*block.TipSet, error) {
 }
 
 func (store *Store) SetCheckPoint(checkPoint block.TipSetKey) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
 	store.checkPoint = checkPoint
 }
 
@@ -814,6 +828,8 @@ func (store *Store) WriteCheckPoint(ctx context.Context, cids block.TipSetKey) e
 
 // GetCheckPoint get the check point from store or disk.
 func (store *Store) GetCheckPoint() block.TipSetKey {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
 	return store.checkPoint
 }
 
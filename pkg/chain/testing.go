@@ -711,6 +711,11 @@ func (f *Builder) FetchTipSetHeaders(ctx context.Context, key block.TipSetKey, f
 	return f.FetchTipSets(ctx, key, from, done)
 }
 
+// FetchStateTrees does nothing, the states computed by the Builder are in its store.
+func (f *Builder) FetchStateTrees(ctx context.Context, roots []cid.Cid) error {
+	return nil
+}
+
 // GetTipSetStateRoot returns the state root that was computed for a tipset.
 func (f *Builder) GetTipSetStateRoot(key block.TipSetKey) (cid.Cid, error) {
 	found, ok := f.tipStateCids[key.String()]
@@ -841,6 +846,10 @@ func (f *Builder) AddPeer(peer peer.ID) {
 	return
 }
 
+func (f *Builder) PeerScores() []exchange.PeerScore {
+	return nil
+}
+
 ///// Internals /////
 
 func makeCid(i interface{}) (cid.Cid, error) {
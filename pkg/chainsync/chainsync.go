@@ -3,6 +3,7 @@ package chainsync
 import (
 	"context"
 
+	"github.com/ipfs/go-cid"
 	blockstore "github.com/ipfs/go-ipfs-blockstore"
 
 	"github.com/filecoin-project/venus/pkg/block"
@@ -65,7 +66,54 @@ func (m *Manager) BlockProposer() BlockProposer {
 	return m.dispatcher
 }
 
+// OnGossipSynced sets a callback that fires after the chain of a block received
+// on pubsub is synced. It must be called before Start.
+func (m *Manager) OnGossipSynced(cb func(block.ChainInfo)) {
+	m.dispatcher.OnGossipSynced(cb)
+}
+
 // Status returns the block proposer.
 func (m *Manager) Status() status.Status {
 	return m.syncer.Status()
 }
+
+// SyncState returns the progress of the active sync targets, the queued targets and the
+// recently finished ones.
+func (m *Manager) SyncState() status.SyncState {
+	state := m.syncer.SyncState()
+	state.Queued = m.dispatcher.Queued()
+	return state
+}
+
+// ValidateBlock fully validates a block on top of its validated parent tipset.
+func (m *Manager) ValidateBlock(ctx context.Context, blk *block.Block) error {
+	return m.syncer.ValidateBlock(ctx, blk)
+}
+
+// MarkBad marks a block bad, chains including it are refused.
+func (m *Manager) MarkBad(c cid.Cid) {
+	m.syncer.MarkBad(c)
+}
+
+// UnmarkBad unmarks a bad block.
+func (m *Manager) UnmarkBad(c cid.Cid) {
+	m.syncer.UnmarkBad(c)
+}
+
+// UnmarkAllBad unmarks all bad blocks.
+func (m *Manager) UnmarkAllBad() {
+	m.syncer.UnmarkAllBad()
+}
+
+// CheckBad returns the reason a block is bad, an empty string if it isn't.
+func (m *Manager) CheckBad(c cid.Cid) string {
+	return m.syncer.CheckBad(c)
+}
+
+// SetTrustedCheckpoint makes the next sync start from the tipset key when the
+// head is below it, fetching the state root its blocks were built on instead
+// of executing the chain. The messages below it are fetched in the background
+// with backFill.
+func (m *Manager) SetTrustedCheckpoint(key block.TipSetKey, stateRoot cid.Cid, backFill bool) {
+	m.syncer.SetTrustedCheckpoint(key, stateRoot, backFill)
+}
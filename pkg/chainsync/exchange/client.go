@@ -97,8 +97,8 @@ func (c *client) doRequest(
 
 	// Try the request for each peer in the list,
 	// return on the first successful response.
-	// FIXME: Doing this serially isn't great, but fetching in parallel
-	//  may not be a good idea either. Think about this more.
+	// Parallel fetching is done by the callers, splitting long ranges
+	// in windows or racing several peers, see parallel.go.
 	globalTime := time.Now()
 	// Global time used to track what is the expected time we will need to get
 	// a response if a client fails us.
@@ -109,26 +109,12 @@ func (c *client) doRequest(
 		default:
 		}
 
-		// Send request, read response.
-		res, err := c.sendRequestToPeer(ctx, peer, req)
+		validRes, err := c.requestPeer(ctx, peer, req, tipsets)
 		if err != nil {
-			if !xerrors.Is(err, network.ErrNoConn) {
-				log.Warnf("could not send request to peer %s: %s",
-					peer.String(), err)
-			}
-			continue
-		}
-
-		// Process and validate response.
-		validRes, err := c.processResponse(req, res, tipsets)
-		if err != nil {
-			log.Warnf("processing peer %s response failed: %s",
-				peer.String(), err)
 			continue
 		}
 
 		c.peerTracker.logGlobalSuccess(time.Since(globalTime))
-		c.host.ConnManager().TagPeer(peer, "bsync", SuccessPeerTagValue)
 		return validRes, nil
 	}
 
@@ -139,15 +125,55 @@ func (c *client) doRequest(
 	return nil, xerrors.Errorf(errString)
 }
 
+// requestPeer sends the request to a single peer and validates its response.
+// Invalid responses are penalized in the peer tracker.
+func (c *client) requestPeer(ctx context.Context, p peer.ID, req *Request, tipsets []*block.TipSet) (*validatedResponse, error) {
+	start := time.Now()
+
+	// Send request, read response.
+	res, err := c.sendRequestToPeer(ctx, p, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if !xerrors.Is(err, network.ErrNoConn) {
+			log.Warnf("could not send request to peer %s: %s",
+				p.String(), err)
+		}
+		recordPeerRequest(ctx, p, resultFailure, time.Since(start))
+		return nil, err
+	}
+
+	// A peer missing the requested chain answers with an error status, this
+	// is a failure but not an invalid response.
+	if err := res.statusToError(); err != nil {
+		recordPeerRequest(ctx, p, resultFailure, time.Since(start))
+		return nil, xerrors.Errorf("status error: %s", err)
+	}
+
+	// Process and validate response.
+	validRes, err := c.processResponse(req, res, tipsets)
+	if err != nil {
+		log.Warnf("processing peer %s response failed: %s",
+			p.String(), err)
+		c.peerTracker.logInvalid(p)
+		recordPeerRequest(ctx, p, resultInvalid, time.Since(start))
+		return nil, err
+	}
+
+	c.peerTracker.logSuccess(p, time.Since(start), uint64(len(res.Chain)))
+	recordPeerRequest(ctx, p, resultSuccess, time.Since(start))
+	c.host.ConnManager().TagPeer(p, "bsync", SuccessPeerTagValue)
+	return validRes, nil
+}
+
 // Process and validate response. Check the status, the integrity of the
 // information returned, and that it matches the request. Extract the information
 // into a `validatedResponse` for the external-facing APIs to select what they
 // need.
 //
 // We are conflating in the single error returned both status and validation
-// errors. Peer penalization should happen here then, before returning, so
-// we can apply the correct penalties depending on the cause of the error.
-// FIXME: Add the `peer` as argument once we implement penalties.
+// errors. Peers are penalized by `requestPeer`, which checks the status first.
 func (c *client) processResponse(req *Request, res *Response, tipsets []*block.TipSet) (*validatedResponse, error) {
 	err := res.statusToError()
 	if err != nil {
@@ -300,18 +326,36 @@ func (c *client) GetBlocks(ctx context.Context, tsk block.TipSetKey, count int)
 		)
 	}
 
-	req := &Request{
-		Head:    enccid.WrapCid(tsk.ToSlice()),
-		Length:  uint64(count),
-		Options: Headers,
-	}
+	// Headers are fetched backwards from tsk, so the windows of a long range
+	// are requested one after the other, each one from several peers at once.
+	var out []*block.TipSet
+	head := tsk
+	for count > 0 {
+		length := uint64(count)
+		if length > MaxRequestLength {
+			length = MaxRequestLength
+		}
+		req := &Request{
+			Head:    enccid.WrapCid(head.ToSlice()),
+			Length:  length,
+			Options: Headers,
+		}
 
-	validRes, err := c.doRequest(ctx, req, nil, nil)
-	if err != nil {
-		return nil, err
+		validRes, err := c.doRacedRequest(ctx, req, nil)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, validRes.tipsets...)
+
+		last := validRes.tipsets[len(validRes.tipsets)-1]
+		if uint64(len(validRes.tipsets)) < length || last.EnsureHeight() == 0 {
+			break
+		}
+		count -= len(validRes.tipsets)
+		head = last.EnsureParents()
 	}
 
-	return validRes.tipsets, nil
+	return out, nil
 }
 
 // GetFullTipSet implements Client.GetFullTipSet(). Refer to the godocs there.
@@ -348,6 +392,10 @@ func (c *client) GetChainMessages(ctx context.Context, tipsets []*block.TipSet)
 	}
 	defer span.End()
 
+	if length > MessageWindowSize {
+		return c.getChainMessagesInWindows(ctx, tipsets)
+	}
+
 	req := &Request{
 		Head:    enccid.WrapCid(head.Key().ToSlice()),
 		Length:  length,
@@ -404,6 +452,11 @@ func (c *client) sendRequestToPeer(ctx context.Context, peer peer.ID, req *Reque
 		peer,
 		ChainExchangeProtocolID, BlockSyncProtocolID)
 	if err != nil {
+		if ctx.Err() != nil {
+			// The request was cancelled, e.g. another peer won the race, this
+			// says nothing about the peer.
+			return nil, xerrors.Errorf("context cancelled: %w", ctx.Err())
+		}
 		c.RemovePeer(peer)
 		return nil, xerrors.Errorf("failed to open stream to peer: %w", err)
 	}
@@ -414,10 +467,25 @@ func (c *client) sendRequestToPeer(ctx context.Context, peer peer.ID, req *Reque
 		go stream.Close() //nolint:errcheck
 	}()
 
+	// Reset the stream when the request is cancelled, so that a pending
+	// write or read returns.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = stream.Reset()
+		case <-done:
+		}
+	}()
+
 	// Write request.
 	_ = stream.SetWriteDeadline(time.Now().Add(WriteReqDeadline))
 	if err := WriteCborRPC(stream, req); err != nil {
 		_ = stream.SetWriteDeadline(time.Time{})
+		if ctx.Err() != nil {
+			return nil, xerrors.Errorf("context cancelled: %w", ctx.Err())
+		}
 		c.peerTracker.logFailure(peer, time.Since(connectionStart), req.Length)
 		// FIXME: Should we also remove peer here?
 		return nil, err
@@ -432,6 +500,10 @@ func (c *client) sendRequestToPeer(ctx context.Context, peer peer.ID, req *Reque
 	//		      go-libp2p-core 0.7.0
 	respBytes, err := ioutil.ReadAll(bufio.NewReader(NewInct(stream, ReadResMinSpeed, ReadResDeadline)))
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, xerrors.Errorf("context cancelled: %w", ctx.Err())
+		}
+		c.peerTracker.logFailure(peer, time.Since(connectionStart), req.Length)
 		return nil, err
 	}
 
@@ -455,9 +527,7 @@ func (c *client) sendRequestToPeer(ctx context.Context, peer peer.ID, req *Reque
 		)
 	}
 
-	c.peerTracker.logSuccess(peer, time.Since(connectionStart), uint64(len(res.Chain)))
-	// FIXME: We should really log a success only after we validate the response.
-	//  It might be a bit hard to do.
+	// The success is logged by requestPeer once the response is validated.
 	return &res, nil
 }
 
@@ -471,6 +541,11 @@ func (c *client) RemovePeer(p peer.ID) {
 	c.peerTracker.removePeer(p)
 }
 
+// PeerScores implements Client.PeerScores(). Refer to the godocs there.
+func (c *client) PeerScores() []PeerScore {
+	return c.peerTracker.scores()
+}
+
 // getShuffledPeers returns a preference-sorted set of peers (by latency
 // and failure counting), shuffling the first few peers so we don't always
 // pick the same peer.
@@ -38,4 +38,8 @@ type Client interface {
 	// RemovePeer removes a peer from the pool of peers that the Client
 	// requests data from.
 	RemovePeer(peer peer.ID)
+
+	// PeerScores returns the bookkeeping of the requests sent to each peer
+	// in the pool.
+	PeerScores() []PeerScore
 }
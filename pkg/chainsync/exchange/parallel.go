@@ -0,0 +1,221 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/enccid"
+	"github.com/filecoin-project/venus/pkg/metrics"
+)
+
+const (
+	// MessageWindowSize is the number of tipsets whose messages are requested
+	// from a single peer, longer ranges are split in windows fetched in parallel.
+	MessageWindowSize = 100
+	// MaxParallelRequests bounds the number of windows fetched at once.
+	MaxParallelRequests = 4
+	// RacedPeers is the number of peers a header request is sent to at once.
+	RacedPeers = 3
+)
+
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+	resultInvalid = "invalid"
+)
+
+var (
+	peerKey   = tag.MustNewKey("peer")
+	resultKey = tag.MustNewKey("result")
+
+	peerRequests        = metrics.NewInt64Counter("exchange/peer_requests", "The number of chain exchange requests sent to a peer, by result.", peerKey, resultKey)
+	peerRequestDuration = metrics.NewTimerMs("exchange/peer_request_duration", "The duration of chain exchange requests sent to a peer.", peerKey)
+)
+
+// recordPeerRequest records the result and duration of a request sent to a peer.
+func recordPeerRequest(ctx context.Context, p peer.ID, result string, dur time.Duration) {
+	ctx, err := tag.New(ctx, tag.Upsert(peerKey, p.String()), tag.Upsert(resultKey, result))
+	if err != nil {
+		log.Warnf("failed to tag peer request metrics: %s", err)
+		return
+	}
+	peerRequests.Inc(ctx, 1)
+	peerRequestDuration.Record(ctx, dur)
+}
+
+// doRacedRequest sends the request to the RacedPeers best peers at once and
+// returns the first valid response, the other requests are cancelled. When a
+// request fails it is sent to the next best peer.
+func (c *client) doRacedRequest(ctx context.Context, req *Request, tipsets []*block.TipSet) (*validatedResponse, error) {
+	if req.Length == 0 || req.Length > MaxRequestLength {
+		return nil, xerrors.Errorf("invalid request length %d", req.Length)
+	}
+
+	peers := c.getShuffledPeers()
+	if len(peers) == 0 {
+		return nil, xerrors.Errorf("no peers available")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		res *validatedResponse
+		err error
+	}
+	results := make(chan result, len(peers))
+	globalTime := time.Now()
+
+	next, inflight := 0, 0
+	launch := func() {
+		p := peers[next]
+		next++
+		inflight++
+		go func() {
+			res, err := c.requestPeer(ctx, p, req, tipsets)
+			results <- result{res: res, err: err}
+		}()
+	}
+	for next < len(peers) && inflight < RacedPeers {
+		launch()
+	}
+
+	for inflight > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, xerrors.Errorf("context cancelled: %w", ctx.Err())
+		case r := <-results:
+			inflight--
+			if r.err == nil {
+				c.peerTracker.logGlobalSuccess(time.Since(globalTime))
+				return r.res, nil
+			}
+			if next < len(peers) {
+				launch()
+			}
+		}
+	}
+	return nil, xerrors.Errorf("doRacedRequest failed for all peers")
+}
+
+// getChainMessagesInWindows splits the tipsets, ordered from the highest, in
+// windows of MessageWindowSize and fetches the messages of up to
+// MaxParallelRequests windows at once, each window starting with a different
+// peer. The messages are returned in the order of the tipsets.
+func (c *client) getChainMessagesInWindows(ctx context.Context, tipsets []*block.TipSet) ([]*CompactedMessages, error) {
+	peers := c.getShuffledPeers()
+	if len(peers) == 0 {
+		return nil, xerrors.Errorf("no peers available")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make([]*CompactedMessages, len(tipsets))
+	sem := make(chan struct{}, MaxParallelRequests)
+
+	var (
+		wg       sync.WaitGroup
+		errLk    sync.Mutex
+		firstErr error
+	)
+	for w, window := range splitWindows(len(tipsets), MessageWindowSize) {
+		start, end := window[0], window[1]
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			msgs, err := c.getWindowMessages(ctx, tipsets[start:end], rotatePeers(peers, w))
+			if err != nil {
+				errLk.Lock()
+				if firstErr == nil {
+					firstErr = xerrors.Errorf("failed to fetch messages of tipsets %d-%d: %w", tipsets[end-1].EnsureHeight(), tipsets[start].EnsureHeight(), err)
+				}
+				errLk.Unlock()
+				cancel()
+				return
+			}
+			copy(out[start:end], msgs)
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, xerrors.Errorf("context cancelled: %w", ctx.Err())
+	}
+	return out, nil
+}
+
+// getWindowMessages fetches the messages of a window, trying the peers in
+// order. A peer answering with a partial window is asked for the rest by the
+// next peer.
+func (c *client) getWindowMessages(ctx context.Context, tipsets []*block.TipSet, peers []peer.ID) ([]*CompactedMessages, error) {
+	var out []*CompactedMessages
+	for _, p := range peers {
+		if len(out) == len(tipsets) {
+			break
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		left := tipsets[len(out):]
+		req := &Request{
+			Head:    enccid.WrapCid(left[0].Key().ToSlice()),
+			Length:  uint64(len(left)),
+			Options: Messages,
+		}
+		res, err := c.requestPeer(ctx, p, req, left)
+		if err != nil {
+			continue
+		}
+		out = append(out, res.messages...)
+	}
+
+	if len(out) < len(tipsets) {
+		return nil, xerrors.Errorf("fetched messages of %d tipsets out of %d", len(out), len(tipsets))
+	}
+	return out, nil
+}
+
+// splitWindows splits count items in consecutive windows of at most size
+// items, returned as [start, end) index pairs.
+func splitWindows(count, size int) [][2]int {
+	var windows [][2]int
+	for start := 0; start < count; start += size {
+		end := start + size
+		if end > count {
+			end = count
+		}
+		windows = append(windows, [2]int{start, end})
+	}
+	return windows
+}
+
+// rotatePeers returns the peers starting at offset i, so that parallel windows
+// start with different peers.
+func rotatePeers(peers []peer.ID, i int) []peer.ID {
+	i %= len(peers)
+	out := make([]peer.ID, 0, len(peers))
+	out = append(out, peers[i:]...)
+	return append(out, peers[:i]...)
+}
@@ -0,0 +1,30 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestSplitWindows(t *testing.T) {
+	tf.UnitTest(t)
+
+	assert.Empty(t, splitWindows(0, MessageWindowSize))
+	assert.Equal(t, [][2]int{{0, 10}}, splitWindows(10, MessageWindowSize))
+	assert.Equal(t, [][2]int{{0, 100}}, splitWindows(100, MessageWindowSize))
+	assert.Equal(t, [][2]int{{0, 100}, {100, 200}, {200, 250}}, splitWindows(250, MessageWindowSize))
+}
+
+func TestRotatePeers(t *testing.T) {
+	tf.UnitTest(t)
+
+	peers := []peer.ID{"a", "b", "c"}
+	assert.Equal(t, []peer.ID{"a", "b", "c"}, rotatePeers(peers, 0))
+	assert.Equal(t, []peer.ID{"b", "c", "a"}, rotatePeers(peers, 1))
+	assert.Equal(t, []peer.ID{"c", "a", "b"}, rotatePeers(peers, 5))
+	// the peers are not modified
+	assert.Equal(t, []peer.ID{"a", "b", "c"}, peers)
+}
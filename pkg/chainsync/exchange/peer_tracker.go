@@ -18,6 +18,16 @@ type peerStats struct {
 	failures    int
 	firstSeen   time.Time
 	averageTime time.Duration
+
+	// invalid counts the responses which failed validation, they are also
+	// counted as failures.
+	invalid int
+	// consecutiveFailures counts the failures since the last success.
+	consecutiveFailures int
+	// throughput is the averaged number of tipsets received per second.
+	throughput float64
+	// blacklistedUntil is the time until which the peer isn't requested.
+	blacklistedUntil time.Time
 }
 
 type bsPeerTracker struct {
@@ -60,6 +70,14 @@ func (bpt *bsPeerTracker) addPeer(p peer.ID) {
 
 }
 
+const (
+	// MaxConsecutiveFailures is the number of failed requests in a row after
+	// which a peer is blacklisted.
+	MaxConsecutiveFailures = 3
+	// BlacklistDuration is how long a blacklisted peer isn't requested.
+	BlacklistDuration = 5 * time.Minute
+)
+
 const (
 	// newPeerMul is how much better than average is the new peer assumed to be
 	// less than one to encourouge trying new peers
@@ -70,10 +88,25 @@ func (bpt *bsPeerTracker) prefSortedPeers() []peer.ID {
 	// TODO: this could probably be cached, but as long as its not too many peers, fine for now
 	bpt.lk.Lock()
 	defer bpt.lk.Unlock()
+	now := time.Now()
 	out := make([]peer.ID, 0, len(bpt.peers))
-	for p := range bpt.peers {
+	var blacklisted []peer.ID
+	for p, pi := range bpt.peers {
+		if bpt.pmgr.IsBanned(p) {
+			continue
+		}
+		if now.Before(pi.blacklistedUntil) {
+			blacklisted = append(blacklisted, p)
+			continue
+		}
 		out = append(out, p)
 	}
+	if len(out) == 0 && len(blacklisted) > 0 {
+		// Rather than stalling the sync until a blacklisting ends, fall back
+		// to the blacklisted peers.
+		log.Warnf("all %d exchange peers are blacklisted, requesting them anyway", len(blacklisted))
+		out = blacklisted
+	}
 
 	// sort by 'expected cost' of requesting data from that peer
 	// additionally handle edge cases where not enough data is available
@@ -88,15 +121,13 @@ func (bpt *bsPeerTracker) prefSortedPeers() []peer.ID {
 		}
 
 		if pi.successes+pi.failures > 0 {
-			failRateI := float64(pi.failures) / float64(pi.failures+pi.successes)
-			costI = float64(pi.averageTime) + failRateI*float64(bpt.avgGlobalTime)
+			costI = bpt.peerCost(pi)
 		} else {
 			costI = getPeerInitLat(out[i])
 		}
 
 		if pj.successes+pj.failures > 0 {
-			failRateJ := float64(pj.failures) / float64(pj.failures+pj.successes)
-			costJ = float64(pj.averageTime) + failRateJ*float64(bpt.avgGlobalTime)
+			costJ = bpt.peerCost(pj)
 		} else {
 			costJ = getPeerInitLat(out[j])
 		}
@@ -107,6 +138,18 @@ func (bpt *bsPeerTracker) prefSortedPeers() []peer.ID {
 	return out
 }
 
+// invalidPenalty is how many average requests an invalid response adds to the
+// cost of a peer once its blacklisting ends.
+const invalidPenalty = 2
+
+// peerCost is the expected cost of a request to the peer, its average time per
+// tipset increased by its failure rate and invalid responses.
+func (bpt *bsPeerTracker) peerCost(pi *peerStats) float64 {
+	failRate := float64(pi.failures) / float64(pi.failures+pi.successes)
+	invalidRate := float64(pi.invalid) / float64(pi.failures+pi.successes)
+	return float64(pi.averageTime) + (failRate+invalidPenalty*invalidRate)*float64(bpt.avgGlobalTime)
+}
+
 const (
 	// xInvAlpha = (N+1)/2
 
@@ -148,10 +191,12 @@ func (bpt *bsPeerTracker) logSuccess(p peer.ID, dur time.Duration, reqSize uint6
 	}
 
 	pi.successes++
+	pi.consecutiveFailures = 0
 	if reqSize == 0 {
 		reqSize = 1
 	}
 	logTime(pi, dur/time.Duration(reqSize))
+	logThroughput(pi, dur, reqSize)
 }
 
 func (bpt *bsPeerTracker) logFailure(p peer.ID, dur time.Duration, reqSize uint64) {
@@ -166,12 +211,80 @@ func (bpt *bsPeerTracker) logFailure(p peer.ID, dur time.Duration, reqSize uint6
 	}
 
 	pi.failures++
+	pi.consecutiveFailures++
+	if pi.consecutiveFailures >= MaxConsecutiveFailures {
+		bpt.blacklist(p, pi)
+	}
 	if reqSize == 0 {
 		reqSize = 1
 	}
 	logTime(pi, dur/time.Duration(reqSize))
 }
 
+// logInvalid records a response of the peer which failed validation and
+// blacklists the peer.
+func (bpt *bsPeerTracker) logInvalid(p peer.ID) {
+	bpt.lk.Lock()
+	defer bpt.lk.Unlock()
+
+	pi, ok := bpt.peers[p]
+	if !ok {
+		return
+	}
+	pi.invalid++
+	pi.failures++
+	pi.consecutiveFailures++
+	bpt.blacklist(p, pi)
+}
+
+func (bpt *bsPeerTracker) blacklist(p peer.ID, pi *peerStats) {
+	log.Infof("blacklisting peer %s for %s after %d failures, %d invalid responses", p, BlacklistDuration, pi.consecutiveFailures, pi.invalid)
+	pi.blacklistedUntil = time.Now().Add(BlacklistDuration)
+	pi.consecutiveFailures = 0
+}
+
+func logThroughput(pi *peerStats, dur time.Duration, reqSize uint64) {
+	if dur <= 0 {
+		return
+	}
+	tp := float64(reqSize) / dur.Seconds()
+	if pi.throughput == 0 {
+		pi.throughput = tp
+		return
+	}
+	pi.throughput += (tp - pi.throughput) / localInvAlpha
+}
+
+// PeerScore is the bookkeeping of the exchange requests sent to a peer.
+type PeerScore struct {
+	ID          peer.ID
+	Successes   int
+	Failures    int
+	Invalid     int
+	AverageTime time.Duration
+	// Throughput is the number of tipsets received per second.
+	Throughput       float64
+	BlacklistedUntil time.Time
+}
+
+func (bpt *bsPeerTracker) scores() []PeerScore {
+	bpt.lk.Lock()
+	defer bpt.lk.Unlock()
+	out := make([]PeerScore, 0, len(bpt.peers))
+	for p, pi := range bpt.peers {
+		out = append(out, PeerScore{
+			ID:               p,
+			Successes:        pi.successes,
+			Failures:         pi.failures,
+			Invalid:          pi.invalid,
+			AverageTime:      pi.averageTime,
+			Throughput:       pi.throughput,
+			BlacklistedUntil: pi.blacklistedUntil,
+		})
+	}
+	return out
+}
+
 func (bpt *bsPeerTracker) removePeer(p peer.ID) {
 	bpt.lk.Lock()
 	defer bpt.lk.Unlock()
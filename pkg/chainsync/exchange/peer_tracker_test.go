@@ -0,0 +1,110 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus/pkg/net"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+type bannedPeerMgr struct {
+	net.MockPeerMgr
+	banned map[peer.ID]bool
+}
+
+func (m bannedPeerMgr) IsBanned(p peer.ID) bool {
+	return m.banned[p]
+}
+
+func newTestPeerTracker(banned ...peer.ID) *bsPeerTracker {
+	pmgr := bannedPeerMgr{banned: make(map[peer.ID]bool)}
+	for _, p := range banned {
+		pmgr.banned[p] = true
+	}
+	return &bsPeerTracker{
+		peers: make(map[peer.ID]*peerStats),
+		pmgr:  pmgr,
+	}
+}
+
+func TestPeerTrackerBlacklist(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("consecutive failures blacklist a peer", func(t *testing.T) {
+		bpt := newTestPeerTracker()
+		bpt.addPeer("a")
+		bpt.addPeer("b")
+
+		for i := 0; i < MaxConsecutiveFailures-1; i++ {
+			bpt.logFailure("a", time.Second, 1)
+		}
+		assert.ElementsMatch(t, []peer.ID{"a", "b"}, bpt.prefSortedPeers())
+
+		bpt.logFailure("a", time.Second, 1)
+		assert.Equal(t, []peer.ID{"b"}, bpt.prefSortedPeers())
+	})
+
+	t.Run("a success resets the consecutive failures", func(t *testing.T) {
+		bpt := newTestPeerTracker()
+		bpt.addPeer("a")
+		bpt.addPeer("b")
+
+		for i := 0; i < MaxConsecutiveFailures-1; i++ {
+			bpt.logFailure("a", time.Second, 1)
+		}
+		bpt.logSuccess("a", time.Second, 1)
+		bpt.logFailure("a", time.Second, 1)
+		assert.ElementsMatch(t, []peer.ID{"a", "b"}, bpt.prefSortedPeers())
+	})
+
+	t.Run("an invalid response blacklists a peer", func(t *testing.T) {
+		bpt := newTestPeerTracker()
+		bpt.addPeer("a")
+		bpt.addPeer("b")
+
+		bpt.logInvalid("a")
+		assert.Equal(t, []peer.ID{"b"}, bpt.prefSortedPeers())
+
+		scores := bpt.scores()
+		for _, s := range scores {
+			if s.ID == "a" {
+				assert.Equal(t, 1, s.Invalid)
+				assert.True(t, s.BlacklistedUntil.After(time.Now()))
+			}
+		}
+	})
+
+	t.Run("blacklisted peers are requested when no other peer is left", func(t *testing.T) {
+		bpt := newTestPeerTracker("c")
+		bpt.addPeer("a")
+		bpt.addPeer("b")
+		bpt.addPeer("c")
+
+		bpt.logInvalid("a")
+		bpt.logInvalid("b")
+		assert.ElementsMatch(t, []peer.ID{"a", "b"}, bpt.prefSortedPeers())
+	})
+
+	t.Run("banned peers are never requested", func(t *testing.T) {
+		bpt := newTestPeerTracker("a")
+		bpt.addPeer("a")
+
+		assert.Empty(t, bpt.prefSortedPeers())
+	})
+
+	t.Run("peers are sorted by cost", func(t *testing.T) {
+		bpt := newTestPeerTracker()
+		bpt.addPeer("slow")
+		bpt.addPeer("fast")
+		bpt.logGlobalSuccess(time.Second)
+
+		bpt.logSuccess("slow", 2*time.Second, 1)
+		bpt.logSuccess("fast", 100*time.Millisecond, 1)
+		peers := bpt.prefSortedPeers()
+		assert.Equal(t, []peer.ID{"fast", "slow"}, peers)
+	})
+}
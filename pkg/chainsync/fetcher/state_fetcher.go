@@ -0,0 +1,154 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-graphsync"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/ipld/go-ipld-prime"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	ipldselector "github.com/ipld/go-ipld-prime/traversal/selector"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+	typegen "github.com/whyrusleeping/cbor-gen"
+)
+
+// stateBlocksBatch is the number of missing state blocks requested at once.
+const stateBlocksBatch = 64
+
+// FetchStateTrees fetches the whole DAGs of the state trees at roots into the
+// blockstore. The trees are expected to share most of their blocks: the first
+// tree with nothing stored locally is requested as a whole, the others are
+// completed level by level, requesting only the blocks missing locally. The
+// tracked peers are tried one after the other until the trees are complete.
+func (gsf *GraphSyncFetcher) FetchStateTrees(ctx context.Context, roots []cid.Cid) error {
+	// seen holds the blocks stored locally whose links were walked, the blocks
+	// shared by the trees are walked once.
+	seen := cid.NewSet()
+	var rpf *requestPeerFinder
+	for _, root := range roots {
+		missing, err := gsf.missingBlocks(seen, []cid.Cid{root})
+		if err != nil {
+			return err
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if rpf == nil {
+			rpf, err = newRequestPeerFinder(gsf.peerTracker, false)
+			if err != nil {
+				return errors.Wrapf(err, "failed to fetch state tree %s", root)
+			}
+		}
+
+		for len(missing) > 0 {
+			targetPeer := rpf.CurrentPeer()
+			if seen.Len() == 0 {
+				err = gsf.fetchDAG(ctx, root, targetPeer)
+			} else {
+				err = gsf.fetchStateBlocks(ctx, missing, targetPeer)
+			}
+			if err == nil {
+				missing, err = gsf.fetchedMissing(seen, missing)
+			}
+			if err == nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logGraphsyncFetcher.Infof("failed to fetch state tree %s from %s: %s", root, targetPeer, err)
+
+			if err := rpf.FindNextPeer(); err != nil {
+				return errors.Wrapf(err, "failed to fetch state tree %s", root)
+			}
+		}
+	}
+	return nil
+}
+
+// fetchStateBlocks requests the blocks from the peer without their links.
+func (gsf *GraphSyncFetcher) fetchStateBlocks(ctx context.Context, cids []cid.Cid, targetPeer peer.ID) error {
+	for len(cids) > 0 {
+		n := stateBlocksBatch
+		if n > len(cids) {
+			n = len(cids)
+		}
+		if err := gsf.fetchBlocks(ctx, gsf.headerSel, cids[:n], targetPeer); err != nil {
+			return err
+		}
+		cids = cids[n:]
+	}
+	return nil
+}
+
+// fetchedMissing returns the blocks still missing below the requested blocks,
+// it fails when one of the requested blocks was not received.
+func (gsf *GraphSyncFetcher) fetchedMissing(seen *cid.Set, requested []cid.Cid) ([]cid.Cid, error) {
+	for _, c := range requested {
+		has, err := gsf.store.Has(c)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, fmt.Errorf("block %s is missing", c)
+		}
+	}
+	return gsf.missingBlocks(seen, requested)
+}
+
+// dagSel generates a selector for all the blocks reachable from the root.
+func (gsf *GraphSyncFetcher) dagSel() ipld.Node {
+	return gsf.ssb.ExploreRecursive(ipldselector.RecursionLimitNone(),
+		gsf.ssb.ExploreAll(gsf.ssb.ExploreRecursiveEdge())).Node()
+}
+
+func (gsf *GraphSyncFetcher) fetchDAG(ctx context.Context, root cid.Cid, targetPeer peer.ID) error {
+	requestCtx, requestCancel := context.WithCancel(ctx)
+	defer requestCancel()
+
+	requestChan, errChan := gsf.exchange.Request(requestCtx, targetPeer, cidlink.Link{Cid: root}, gsf.dagSel(), graphsync.ExtensionData{Name: ChainsyncProtocolExtension})
+	return gsf.consumeResponse(requestChan, errChan, requestCancel)
+}
+
+// missingBlocks walks the DAGs from roots in the blockstore, skipping the
+// blocks in seen, and returns the blocks missing. The blocks found are added to
+// seen.
+func (gsf *GraphSyncFetcher) missingBlocks(seen *cid.Set, roots []cid.Cid) ([]cid.Cid, error) {
+	var missing []cid.Cid
+	missingSet := cid.NewSet()
+	queue := append([]cid.Cid(nil), roots...)
+	for len(queue) > 0 {
+		c := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if seen.Has(c) || c.Prefix().MhType == 0 {
+			// Identity cids embed their data.
+			continue
+		}
+
+		blk, err := gsf.store.Get(c)
+		if err == bstore.ErrNotFound {
+			if missingSet.Visit(c) {
+				missing = append(missing, c)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen.Add(c)
+		if c.Prefix().Codec != cid.DagCBOR {
+			continue
+		}
+		err = typegen.ScanForLinks(bytes.NewReader(blk.RawData()), func(link cid.Cid) {
+			queue = append(queue, link)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to scan links of %s", c)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,125 @@
+package fetcher_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	format "github.com/ipfs/go-ipld-format"
+	basicnode "github.com/ipld/go-ipld-prime/node/basic"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+	selectorbuilder "github.com/ipld/go-ipld-prime/traversal/selector/builder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chainsync/fetcher"
+	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/constants"
+	th "github.com/filecoin-project/venus/pkg/testhelpers"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestFetchStateTrees(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	fc := clock.NewFake(time.Unix(1234567890, 0))
+
+	ssb := selectorbuilder.NewSelectorSpecBuilder(basicnode.Prototype.Any)
+	dagSelector, err := ssb.ExploreRecursive(selector.RecursionLimitNone(), ssb.ExploreAll(ssb.ExploreRecursiveEdge())).Selector()
+	require.NoError(t, err)
+	blockSelector, err := ssb.Matcher().Selector()
+	require.NoError(t, err)
+
+	wrap := func(obj interface{}) format.Node {
+		nd, err := cbor.WrapObject(obj, constants.DefaultHashFunction, -1)
+		require.NoError(t, err)
+		return nd
+	}
+	// Two state trees sharing the leaf a.
+	leafA := wrap(map[string]interface{}{"value": "a"})
+	leafB := wrap(map[string]interface{}{"value": "b"})
+	leafC := wrap(map[string]interface{}{"value": "c"})
+	root1 := wrap(map[string]interface{}{"links": []cid.Cid{leafA.Cid(), leafB.Cid()}})
+	root2 := wrap(map[string]interface{}{"links": []cid.Cid{leafA.Cid(), leafC.Cid()}})
+	nodes := []format.Node{leafA, leafB, leafC, root1, root2}
+	loader := simpleLoader(nodes)
+
+	pid0 := th.RequireIntPeerID(t, 0)
+	pid1 := th.RequireIntPeerID(t, 1)
+	key := block.NewTipSetKey(root1.Cid())
+	chain0 := block.NewChainInfo(pid0, pid0, key, 1)
+	chain1 := block.NewChainInfo(pid1, pid1, key, 1)
+
+	requireHasAll := func(t *testing.T, bs bstore.Blockstore) {
+		for _, nd := range nodes {
+			has, err := bs.Has(nd.Cid())
+			require.NoError(t, err)
+			assert.True(t, has, "block %s is fetched", nd.Cid())
+		}
+	}
+
+	t.Run("fetches the first tree whole and only the missing blocks of the others", func(t *testing.T) {
+		bs := bstore.NewBlockstore(datastore.NewMapDatastore())
+		mgs := newMockableGraphsync(ctx, bs, fc, t)
+		mgs.expectRequestToRespondWithLoader(pid0, dagSelector, loader, root1.Cid())
+		mgs.expectRequestToRespondWithLoader(pid0, blockSelector, loader, root2.Cid(), leafC.Cid())
+
+		f := fetcher.NewGraphSyncFetcher(ctx, mgs, bs, mockSyntaxValidator{}, fc, newFakePeerTracker(chain0))
+		require.NoError(t, f.FetchStateTrees(ctx, []cid.Cid{root1.Cid(), root2.Cid()}))
+
+		requireHasAll(t, bs)
+		mgs.verifyReceivedRequestCount(3)
+		mgs.verifyExpectations()
+	})
+
+	t.Run("does not request the trees stored locally", func(t *testing.T) {
+		bs := bstore.NewBlockstore(datastore.NewMapDatastore())
+		for _, nd := range nodes {
+			requireBlockStorePut(t, bs, nd)
+		}
+		mgs := newMockableGraphsync(ctx, bs, fc, t)
+
+		f := fetcher.NewGraphSyncFetcher(ctx, mgs, bs, mockSyntaxValidator{}, fc, newFakePeerTracker(chain0))
+		require.NoError(t, f.FetchStateTrees(ctx, []cid.Cid{root1.Cid(), root2.Cid()}))
+		mgs.verifyReceivedRequestCount(0)
+	})
+
+	t.Run("tries the next peer when a peer misses blocks", func(t *testing.T) {
+		bs := bstore.NewBlockstore(datastore.NewMapDatastore())
+		mgs := newMockableGraphsync(ctx, bs, fc, t)
+		mgs.expectRequestToRespondWithLoader(pid0, dagSelector, errorOnCidsLoader(loader, leafB.Cid()), root1.Cid())
+		mgs.expectRequestToRespondWithLoader(pid1, dagSelector, loader, root1.Cid())
+
+		f := fetcher.NewGraphSyncFetcher(ctx, mgs, bs, mockSyntaxValidator{}, fc, newFakePeerTracker(chain0, chain1))
+		require.NoError(t, f.FetchStateTrees(ctx, []cid.Cid{root1.Cid()}))
+
+		for _, nd := range []format.Node{root1, leafA, leafB} {
+			has, err := bs.Has(nd.Cid())
+			require.NoError(t, err)
+			assert.True(t, has)
+		}
+		mgs.verifyReceivedRequestCount(2)
+		mgs.verifyExpectations()
+	})
+
+	t.Run("fails when no peer has the blocks", func(t *testing.T) {
+		bs := bstore.NewBlockstore(datastore.NewMapDatastore())
+		mgs := newMockableGraphsync(ctx, bs, fc, t)
+		errorLoader := errorOnCidsLoader(loader, leafC.Cid())
+		mgs.expectRequestToRespondWithLoader(pid0, dagSelector, loader, root1.Cid())
+		mgs.expectRequestToRespondWithLoader(pid0, blockSelector, loader, root2.Cid())
+		mgs.expectRequestToRespondWithLoader(pid0, blockSelector, errorLoader, leafC.Cid())
+		mgs.expectRequestToRespondWithLoader(pid1, blockSelector, errorLoader, leafC.Cid())
+
+		f := fetcher.NewGraphSyncFetcher(ctx, mgs, bs, mockSyntaxValidator{}, fc, newFakePeerTracker(chain0, chain1))
+		err := f.FetchStateTrees(ctx, []cid.Cid{root1.Cid(), root2.Cid()})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch state tree")
+		mgs.verifyExpectations()
+	})
+}
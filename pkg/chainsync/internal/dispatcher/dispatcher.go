@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"sort"
+	"sync"
 
 	logging "github.com/ipfs/go-log/v2"
 
@@ -98,25 +100,29 @@ type Dispatcher struct {
 
 	// syncTargetCount counts the number of successful syncs.
 	syncTargetCount uint64
+
+	// gossipSyncedCb is called after every successful sync of a target
+	// received on gossip.
+	gossipSyncedCb func(block.ChainInfo)
 }
 
 // SendHello handles chain information from bootstrap peers.
 func (d *Dispatcher) SendHello(ci *block.ChainInfo) error {
-	return d.enqueue(ci)
+	return d.enqueue(Target{ChainInfo: *ci})
 }
 
 // SendOwnBlock handles chain info from a node's own mining system
 func (d *Dispatcher) SendOwnBlock(ci *block.ChainInfo) error {
-	return d.enqueue(ci)
+	return d.enqueue(Target{ChainInfo: *ci})
 }
 
 // SendGossipBlock handles chain info from new blocks sent on pubsub
 func (d *Dispatcher) SendGossipBlock(ci *block.ChainInfo) error {
-	return d.enqueue(ci)
+	return d.enqueue(Target{ChainInfo: *ci, Gossip: true})
 }
 
-func (d *Dispatcher) enqueue(ci *block.ChainInfo) error {
-	d.incoming <- Target{ChainInfo: *ci}
+func (d *Dispatcher) enqueue(t Target) error {
+	d.incoming <- t
 	return nil
 }
 
@@ -194,6 +200,9 @@ func (d *Dispatcher) Start(syncingCtx context.Context) {
 					log.Infof("failed sync of %v (catchup=%t): %s", &syncTarget.ChainInfo, d.catchup, err)
 				}
 				d.syncTargetCount++
+				if err == nil && syncTarget.Gossip && d.gossipSyncedCb != nil {
+					d.gossipSyncedCb(syncTarget.ChainInfo)
+				}
 				d.registeredCb(syncTarget, err)
 				follow, err := d.transitioner.MaybeTransitionToFollow(syncingCtx, d.catchup, d.workQueue.Len())
 				if err != nil {
@@ -235,6 +244,22 @@ func (d *Dispatcher) RegisterCallback(cb func(Target, error)) {
 	d.control <- cbMessage{cb: cb}
 }
 
+// OnGossipSynced sets a callback that will fire after every successful sync of
+// a target received on gossip. It must be called before Start.
+func (d *Dispatcher) OnGossipSynced(cb func(block.ChainInfo)) {
+	d.gossipSyncedCb = cb
+}
+
+// Queued returns the chains waiting in the work queue, highest first.
+func (d *Dispatcher) Queued() []block.ChainInfo {
+	targets := d.workQueue.Targets()
+	out := make([]block.ChainInfo, len(targets))
+	for i, t := range targets {
+		out[i] = t.ChainInfo
+	}
+	return out
+}
+
 // WaiterForTarget returns a function that will block until the dispatcher
 // processes the given target and returns the error produced by that targer
 func (d *Dispatcher) WaiterForTarget(waitKey block.TipSetKey) func() error {
@@ -270,6 +295,8 @@ func (d *Dispatcher) processCtrl(ctrlMsg interface{}) {
 // syncing job against given inputs.
 type Target struct {
 	block.ChainInfo
+	// Gossip is true for the blocks received on pubsub.
+	Gossip bool
 }
 
 // Transitioner determines whether the caller should move between catchup and
@@ -363,6 +390,7 @@ func (gt *GapTransitioner) TransitionChannel() chan bool {
 // It wraps the `targetQueue` to prevent panics during
 // normal operation.
 type TargetQueue struct {
+	lk        sync.Mutex
 	q         targetQueue
 	targetSet map[string]struct{}
 }
@@ -379,6 +407,8 @@ func NewTargetQueue() *TargetQueue {
 
 // Push adds a sync target to the target queue.
 func (tq *TargetQueue) Push(t Target) {
+	tq.lk.Lock()
+	defer tq.lk.Unlock()
 	// If already in queue drop quickly
 	if _, inQ := tq.targetSet[t.ChainInfo.Head.String()]; inQ {
 		return
@@ -391,7 +421,9 @@ func (tq *TargetQueue) Push(t Target) {
 // Pop removes and returns the highest priority syncing target. If there is
 // nothing in the queue the second argument returns false
 func (tq *TargetQueue) Pop() (Target, bool) {
-	if tq.Len() == 0 {
+	tq.lk.Lock()
+	defer tq.lk.Unlock()
+	if tq.q.Len() == 0 {
 		return Target{}, false
 	}
 	req := heap.Pop(&tq.q).(Target)
@@ -402,9 +434,23 @@ func (tq *TargetQueue) Pop() (Target, bool) {
 
 // Len returns the number of targets in the queue.
 func (tq *TargetQueue) Len() int {
+	tq.lk.Lock()
+	defer tq.lk.Unlock()
 	return tq.q.Len()
 }
 
+// Targets returns the targets in the queue ordered by priority, highest first.
+func (tq *TargetQueue) Targets() []Target {
+	tq.lk.Lock()
+	defer tq.lk.Unlock()
+	out := make([]Target, len(tq.q))
+	copy(out, tq.q)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Height > out[j].Height
+	})
+	return out
+}
+
 // targetQueue orders targets by a policy.
 //
 // The current simple policy is to order syncing requests by claimed chain
@@ -2,6 +2,7 @@ package dispatcher_test
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
 
@@ -112,6 +113,44 @@ func TestDispatcherDropsWhenFull(t *testing.T) {
 	finished.Wait()
 }
 
+// failingSyncer fails to sync the targets at heights in fail.
+type failingSyncer struct {
+	fail map[abi.ChainEpoch]bool
+}
+
+func (fs *failingSyncer) HandleNewTipSet(_ context.Context, ci *block.ChainInfo, _ bool) error {
+	if fs.fail[ci.Height] {
+		return errors.New("invalid chain")
+	}
+	return nil
+}
+
+func TestDispatcherGossipSynced(t *testing.T) {
+	tf.UnitTest(t)
+	s := &failingSyncer{fail: map[abi.ChainEpoch]bool{2: true}}
+	nt := &noopTransitioner{}
+	testDispatch := dispatcher.NewDispatcher(s, nt)
+
+	var synced []abi.ChainEpoch
+	testDispatch.OnGossipSynced(func(ci block.ChainInfo) {
+		synced = append(synced, ci.Height)
+	})
+	allDone := moresync.NewLatch(4)
+	testDispatch.RegisterCallback(func(t dispatcher.Target, _ error) { allDone.Done() })
+
+	go func() {
+		assert.NoError(t, testDispatch.SendGossipBlock(chainInfoFromHeight(t, 4)))
+		assert.NoError(t, testDispatch.SendHello(chainInfoFromHeight(t, 3)))
+		assert.NoError(t, testDispatch.SendGossipBlock(chainInfoFromHeight(t, 2)))
+		assert.NoError(t, testDispatch.SendGossipBlock(chainInfoFromHeight(t, 1)))
+	}()
+	testDispatch.Start(context.Background())
+	allDone.Wait()
+
+	// Only the gossip targets that synced are reported.
+	assert.ElementsMatch(t, []abi.ChainEpoch{4, 1}, synced)
+}
+
 func TestQueueHappy(t *testing.T) {
 	tf.UnitTest(t)
 	testQ := dispatcher.NewTargetQueue()
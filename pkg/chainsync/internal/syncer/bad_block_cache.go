@@ -0,0 +1,126 @@
+package syncer
+
+import (
+	"fmt"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus/pkg/block"
+)
+
+// BadBlockCacheSize is the number of bad blocks remembered by the syncer.
+const BadBlockCacheSize = 1 << 15
+
+// BadBlockReason records why a block was marked bad.
+type BadBlockReason struct {
+	Reason string
+	TipSet block.TipSetKey
+	// OriginalReason is the reason of the bad ancestor of a block marked bad
+	// because it descends from it, nil for the bad ancestor itself.
+	OriginalReason *BadBlockReason
+	At             time.Time
+}
+
+// NewBadBlockReason creates a reason for the blocks of the tipset.
+func NewBadBlockReason(tsk block.TipSetKey, format string, args ...interface{}) BadBlockReason {
+	return BadBlockReason{
+		Reason: fmt.Sprintf(format, args...),
+		TipSet: tsk,
+		At:     time.Now(),
+	}
+}
+
+// Linked returns the reason of the blocks of a tipset descending from a block
+// bad for this reason.
+func (bbr BadBlockReason) Linked(tsk block.TipSetKey, format string, args ...interface{}) BadBlockReason {
+	or := &bbr
+	if bbr.OriginalReason != nil {
+		or = bbr.OriginalReason
+	}
+	return BadBlockReason{
+		Reason:         fmt.Sprintf(format, args...),
+		TipSet:         tsk,
+		OriginalReason: or,
+		At:             time.Now(),
+	}
+}
+
+// String returns the reason, followed by the original reason of a linked one.
+func (bbr BadBlockReason) String() string {
+	res := bbr.Reason
+	if bbr.OriginalReason != nil {
+		res += " caused by: " + bbr.OriginalReason.String()
+	}
+	return res
+}
+
+// BadBlockCache keeps track of bad blocks that the syncer should not try to
+// download. The purpose of this cache is to prevent a node from having to
+// repeatedly invalidate a block (and its children) in the event that the
+// block does not conform to the rules of consensus. The cache is bounded, the
+// least recently used blocks are evicted first. Note that the cache is only
+// in-memory, so it is reset whenever the node is restarted.
+type BadBlockCache struct {
+	badBlocks *lru.ARCCache
+}
+
+// NewBadBlockCache creates a BadBlockCache holding up to BadBlockCacheSize blocks.
+func NewBadBlockCache() *BadBlockCache {
+	cache, err := lru.NewARC(BadBlockCacheSize)
+	if err != nil {
+		panic(err) // ok
+	}
+	return &BadBlockCache{badBlocks: cache}
+}
+
+// Add marks a block bad.
+func (cache *BadBlockCache) Add(c cid.Cid, bbr BadBlockReason) {
+	cache.badBlocks.Add(c, bbr)
+}
+
+// AddTipSet marks the blocks of the tipset bad.
+func (cache *BadBlockCache) AddTipSet(ts *block.TipSet, bbr BadBlockReason) {
+	for _, c := range ts.Key().ToSlice() {
+		cache.Add(c, bbr)
+	}
+}
+
+// AddChain marks the blocks of the tipsets bad, the tipsets descend from a
+// tipset bad for the reason bbr.
+func (cache *BadBlockCache) AddChain(chain []*block.TipSet, bbr BadBlockReason) {
+	for _, ts := range chain {
+		cache.AddTipSet(ts, bbr.Linked(ts.Key(), "descends from bad tipset %s", bbr.TipSet))
+	}
+}
+
+// Remove unmarks a block.
+func (cache *BadBlockCache) Remove(c cid.Cid) {
+	cache.badBlocks.Remove(c)
+}
+
+// Purge unmarks all blocks.
+func (cache *BadBlockCache) Purge() {
+	cache.badBlocks.Purge()
+}
+
+// Has returns the reason a block is bad, false if it isn't.
+func (cache *BadBlockCache) Has(c cid.Cid) (BadBlockReason, bool) {
+	rval, ok := cache.badBlocks.Get(c)
+	if !ok {
+		return BadBlockReason{}, false
+	}
+	return rval.(BadBlockReason), true
+}
+
+// HasTipSet returns the reason of the first bad block of the tipset, false if
+// none is bad.
+func (cache *BadBlockCache) HasTipSet(tsk block.TipSetKey) (BadBlockReason, bool) {
+	for _, c := range tsk.ToSlice() {
+		if bbr, ok := cache.Has(c); ok {
+			return bbr, true
+		}
+	}
+	return BadBlockReason{}, false
+}
@@ -0,0 +1,163 @@
+package syncer
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/chainsync/status"
+	"github.com/filecoin-project/venus/pkg/specactors/policy"
+)
+
+// ErrCheckpointStateMismatch is returned when the blocks of a trusted checkpoint
+// were not built on the trusted state root.
+var ErrCheckpointStateMismatch = errors.New("trusted checkpoint state root does not match its blocks")
+
+// trustedCheckpoint is a tipset trusted by the operator. A node whose head is
+// below it fetches the headers down to its head and the states the tipset and
+// its lookback were built on, then syncs normally from the parent of the
+// tipset without executing the chain in between.
+type trustedCheckpoint struct {
+	key block.TipSetKey
+	// stateRoot is the parent state root of the blocks of the tipset.
+	stateRoot cid.Cid
+	// backFill fetches the messages of the tipsets below the checkpoint in the background.
+	backFill bool
+}
+
+// SetTrustedCheckpoint sets the tipset the next sync starts from when the head
+// is below it. The parent state root of its blocks must be stateRoot.
+func (syncer *Syncer) SetTrustedCheckpoint(key block.TipSetKey, stateRoot cid.Cid, backFill bool) {
+	syncer.fastSyncLk.Lock()
+	defer syncer.fastSyncLk.Unlock()
+	syncer.trustedCheckpoint = &trustedCheckpoint{key: key, stateRoot: stateRoot, backFill: backFill}
+}
+
+func (syncer *Syncer) getTrustedCheckpoint() *trustedCheckpoint {
+	syncer.fastSyncLk.Lock()
+	defer syncer.fastSyncLk.Unlock()
+	return syncer.trustedCheckpoint
+}
+
+// clearTrustedCheckpoint clears cp unless it was replaced meanwhile.
+func (syncer *Syncer) clearTrustedCheckpoint(cp *trustedCheckpoint) {
+	syncer.fastSyncLk.Lock()
+	defer syncer.fastSyncLk.Unlock()
+	if syncer.trustedCheckpoint == cp {
+		syncer.trustedCheckpoint = nil
+	}
+}
+
+// fastSync moves the staged head to the parent of the trusted checkpoint when
+// the target is above it and the head below it. The states of the parent and
+// of its ancestors within a finality, which mining validation looks back at,
+// are fetched instead of executed, the headers in between are only checked to
+// link to each other and the checkpoint. The tipsets above the checkpoint are
+// then validated normally. A failed fast sync is retried by the next sync,
+// except when the checkpoint does not match its state root.
+func (syncer *Syncer) fastSync(ctx context.Context, ci *block.ChainInfo) error {
+	cp := syncer.getTrustedCheckpoint()
+	if cp == nil {
+		return nil
+	}
+	if syncer.chainStore.HasTipSetAndState(ctx, cp.key) {
+		syncer.clearTrustedCheckpoint(cp)
+		return nil
+	}
+
+	headers, err := syncer.exchangeClient.GetBlocks(ctx, cp.key, 1)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch trusted checkpoint %s", cp.key)
+	}
+	if len(headers) == 0 || !headers[0].Key().Equals(cp.key) {
+		return xerrors.Errorf("trusted checkpoint %s not found", cp.key)
+	}
+	checkpoint := headers[0]
+	if checkpoint.EnsureHeight() <= syncer.staged.EnsureHeight()+1 {
+		logSyncer.Infof("skip fast sync, trusted checkpoint at %d is not above head at %d", checkpoint.EnsureHeight(), syncer.staged.EnsureHeight())
+		syncer.clearTrustedCheckpoint(cp)
+		return nil
+	}
+	for _, blk := range checkpoint.Blocks() {
+		if !blk.ParentStateRoot.Cid.Equals(cp.stateRoot) {
+			syncer.clearTrustedCheckpoint(cp)
+			return errors.Wrapf(ErrCheckpointStateMismatch, "block %s has state root %s, trusted %s", blk.Cid(), blk.ParentStateRoot.Cid, cp.stateRoot)
+		}
+	}
+
+	logSyncer.Infof("fast sync from trusted checkpoint %s at %d", cp.key, checkpoint.EnsureHeight())
+	tipsets, err := syncer.fetchChainBlocks(ctx, syncer.staged, cp.key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch headers down to the trusted checkpoint")
+	}
+	if len(tipsets) < 2 {
+		syncer.clearTrustedCheckpoint(cp)
+		return nil
+	}
+	parent := tipsets[len(tipsets)-2]
+
+	// The ancestors of the parent within a finality get the state roots
+	// committed by their children, like a snapshot import. Their states are
+	// fetched before anything is recorded, so no tipset is stored without its
+	// state.
+	lowest := len(tipsets) - 2 - int(policy.ChainFinality)
+	if lowest < 0 {
+		lowest = 0
+	}
+	var metas []*chain.TipSetMetadata
+	var roots []cid.Cid
+	for i := len(tipsets) - 2; i >= lowest; i-- {
+		child := tipsets[i+1]
+		metas = append(metas, &chain.TipSetMetadata{
+			TipSet:          tipsets[i],
+			TipSetStateRoot: child.At(0).ParentStateRoot.Cid,
+			TipSetReceipts:  child.At(0).ParentMessageReceipts.Cid,
+		})
+		roots = append(roots, child.At(0).ParentStateRoot.Cid)
+	}
+
+	syncer.reporter.UpdateTarget(ci.Head, status.TargetStage(status.StageState))
+	if err := syncer.fetcher.FetchStateTrees(ctx, roots); err != nil {
+		return errors.Wrapf(err, "failed to fetch the states of trusted checkpoint %s", cp.key)
+	}
+	for _, meta := range metas {
+		if err := syncer.chainStore.PutTipSetMetadata(ctx, meta); err != nil {
+			return err
+		}
+	}
+
+	syncer.checkPoint = parent.Key()
+	syncer.staged = parent
+	if err := syncer.SetStagedHead(ctx); err != nil {
+		return err
+	}
+	if err := syncer.chainStore.WriteCheckPoint(ctx, parent.Key()); err != nil {
+		return err
+	}
+	syncer.chainStore.SetCheckPoint(parent.Key())
+	syncer.clearTrustedCheckpoint(cp)
+	logSyncer.Infof("fast synced to %s at %d", parent.Key(), parent.EnsureHeight())
+
+	if cp.backFill {
+		go syncer.backFillMessages(ctx, tipsets[:len(tipsets)-1])
+	}
+	return nil
+}
+
+// backFillMessages fetches the messages of the headers below the checkpoint,
+// it stops with ctx.
+func (syncer *Syncer) backFillMessages(ctx context.Context, headers []*block.TipSet) {
+	err := SegProcess(headers, func(seg []*block.TipSet) error {
+		_, err := syncer.fetchSegMessage(ctx, seg)
+		return err
+	})
+	if err != nil {
+		logSyncer.Errorf("failed to back-fill messages: %s", err)
+		return
+	}
+	logSyncer.Infof("back-filled the messages of %d tipsets", len(headers))
+}
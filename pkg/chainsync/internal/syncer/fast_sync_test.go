@@ -0,0 +1,148 @@
+package syncer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/chainsync/internal/syncer"
+	"github.com/filecoin-project/venus/pkg/chainsync/status"
+	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/fork"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+// stateFetcher records the state roots requested from the builder.
+type stateFetcher struct {
+	*chain.Builder
+	roots []cid.Cid
+	err   error
+}
+
+func (f *stateFetcher) FetchStateTrees(ctx context.Context, roots []cid.Cid) error {
+	f.roots = append(f.roots, roots...)
+	return f.err
+}
+
+// miningRecorder records the heights whose mining is validated.
+type miningRecorder struct {
+	chain.FakeStateEvaluator
+	validated []abi.ChainEpoch
+}
+
+func (e *miningRecorder) ValidateMining(ctx context.Context, parent, ts *block.TipSet, parentWeight big.Int, parentReceiptRoot cid.Cid) error {
+	e.validated = append(e.validated, ts.EnsureHeight())
+	return nil
+}
+
+func setupFastSync(t *testing.T) (*chain.Builder, *stateFetcher, *miningRecorder, *syncer.Syncer) {
+	builder := chain.NewBuilder(t, address.Undef)
+	fetcher := &stateFetcher{Builder: builder}
+	eval := &miningRecorder{}
+	s, err := syncer.NewSyncer(eval,
+		eval,
+		&chain.FakeChainSelector{},
+		builder.Store(),
+		builder.Mstore(),
+		builder.BlockStore(),
+		fetcher,
+		builder,
+		status.NewReporter(),
+		clock.NewFake(time.Unix(1234567890, 0)),
+		&noopFaultDetector{}, fork.NewMockFork())
+	require.NoError(t, err)
+	require.NoError(t, s.InitStaged())
+	return builder, fetcher, eval, s
+}
+
+func TestFastSync(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	t.Run("fetches the lookback states before staging the parent of the checkpoint", func(t *testing.T) {
+		builder, fetcher, eval, s := setupFastSync(t)
+		genesis := builder.Genesis()
+		t1 := builder.AppendOn(genesis, 1)
+		t2 := builder.AppendOn(t1, 1)
+		t3 := builder.AppendOn(t2, 1)
+		t4 := builder.AppendOn(t3, 1)
+		t5 := builder.AppendOn(t4, 1)
+
+		s.SetTrustedCheckpoint(t4.Key(), t4.At(0).ParentStateRoot.Cid, false)
+		require.NoError(t, s.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t5.Key(), heightFromTip(t, t5)), false))
+		verifyHead(t, builder.Store(), t5)
+
+		// The states of the parent of the checkpoint and of its ancestors are
+		// fetched, the tipsets below the checkpoint are not executed.
+		assert.Equal(t, []cid.Cid{
+			t4.At(0).ParentStateRoot.Cid,
+			t3.At(0).ParentStateRoot.Cid,
+			t2.At(0).ParentStateRoot.Cid,
+		}, fetcher.roots)
+		verifyTip(t, builder.Store(), t1, t2.At(0).ParentStateRoot.Cid)
+		verifyTip(t, builder.Store(), t3, t4.At(0).ParentStateRoot.Cid)
+
+		// The trusted checkpoint is not validated, the tipsets above are.
+		assert.Equal(t, []abi.ChainEpoch{heightFromTip(t, t5)}, eval.validated)
+	})
+
+	t.Run("records nothing when the states fail to fetch", func(t *testing.T) {
+		builder, fetcher, _, s := setupFastSync(t)
+		genesis := builder.Genesis()
+		t3 := builder.AppendManyOn(3, genesis)
+		t4 := builder.AppendOn(t3, 1)
+		t5 := builder.AppendOn(t4, 1)
+
+		fetcher.err = errors.New("no peer has the state")
+		s.SetTrustedCheckpoint(t4.Key(), t4.At(0).ParentStateRoot.Cid, false)
+		err := s.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t5.Key(), heightFromTip(t, t5)), false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no peer has the state")
+		assert.False(t, builder.Store().HasTipSetAndState(ctx, t3.Key()))
+		verifyHead(t, builder.Store(), genesis)
+
+		// The checkpoint stays pending, the next sync retries it.
+		fetcher.err = nil
+		fetcher.roots = nil
+		require.NoError(t, s.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t5.Key(), heightFromTip(t, t5)), false))
+		assert.NotEmpty(t, fetcher.roots)
+		verifyHead(t, builder.Store(), t5)
+	})
+
+	t.Run("rejects a checkpoint not built on the trusted state root", func(t *testing.T) {
+		builder, fetcher, _, s := setupFastSync(t)
+		genesis := builder.Genesis()
+		t3 := builder.AppendManyOn(3, genesis)
+		t4 := builder.AppendOn(t3, 1)
+
+		s.SetTrustedCheckpoint(t4.Key(), genesis.At(0).ParentStateRoot.Cid, false)
+		err := s.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t4.Key(), heightFromTip(t, t4)), false)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, syncer.ErrCheckpointStateMismatch))
+		assert.Empty(t, fetcher.roots)
+	})
+
+	t.Run("syncs normally when the checkpoint is not above the head", func(t *testing.T) {
+		builder, fetcher, eval, s := setupFastSync(t)
+		genesis := builder.Genesis()
+		t1 := builder.AppendOn(genesis, 1)
+		t2 := builder.AppendOn(t1, 1)
+
+		s.SetTrustedCheckpoint(t1.Key(), t1.At(0).ParentStateRoot.Cid, false)
+		require.NoError(t, s.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t2.Key(), heightFromTip(t, t2)), false))
+		verifyHead(t, builder.Store(), t2)
+		assert.Empty(t, fetcher.roots)
+		assert.Len(t, eval.validated, 2)
+	})
+}
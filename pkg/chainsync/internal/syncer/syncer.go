@@ -59,8 +59,8 @@ type Syncer struct {
 	// and messages.
 	fetcher        Fetcher
 	exchangeClient exchange.Client
-	// BadTipSetCache is used to filter out collections of invalid blocks.
-	badTipSets *BadTipSetCache
+	// badBlocks is used to filter out collections of invalid blocks.
+	badBlocks *BadBlockCache
 
 	// Evaluates tipset messages and stores the resulting states.
 	fullValidator FullBlockValidator
@@ -87,6 +87,10 @@ type Syncer struct {
 	checkPoint block.TipSetKey
 
 	fork fork.IFork
+
+	fastSyncLk sync.Mutex
+	// trustedCheckpoint is the checkpoint of the next fast sync, nil when none is pending.
+	trustedCheckpoint *trustedCheckpoint
 }
 
 // Fetcher defines an interface that may be used to fetch data from the network.
@@ -99,6 +103,9 @@ type Fetcher interface {
 	// FetchTipSetHeaders will fetch only the headers of tipset blocks.
 	// Returned slice in reversal order
 	FetchTipSetHeaders(context.Context, block.TipSetKey, peer.ID, func(*block.TipSet) (bool, error)) ([]*block.TipSet, error)
+
+	// FetchStateTrees fetches the whole state trees at roots into the block store.
+	FetchStateTrees(ctx context.Context, roots []cid.Cid) error
 }
 
 // ChainReaderWriter reads and writes the chain bsstore.
@@ -115,6 +122,10 @@ type ChainReaderWriter interface {
 	GetTipSetAndStatesByParentsAndHeight(pTsKey block.TipSetKey, h abi.ChainEpoch) ([]*chain.TipSetMetadata, error)
 	GetLatestBeaconEntry(ts *block.TipSet) (*block.BeaconEntry, error)
 	GetGenesisBlock(ctx context.Context) (*block.Block, error)
+	GetCheckPointTipSet() (*block.TipSet, error)
+	WriteCheckPoint(ctx context.Context, cids block.TipSetKey) error
+	SetCheckPoint(checkPoint block.TipSetKey)
+	CheckFork(ctx context.Context, cur, candidate *block.TipSet) error
 }
 
 type messageStore interface {
@@ -195,11 +206,9 @@ func NewSyncer(fv FullBlockValidator,
 	fd faultDetector,
 	fork fork.IFork) (*Syncer, error) {
 	return &Syncer{
-		fetcher:        f,
-		exchangeClient: exchangeClient,
-		badTipSets: &BadTipSetCache{
-			bad: make(map[string]struct{}),
-		},
+		fetcher:         f,
+		exchangeClient:  exchangeClient,
+		badBlocks:       NewBadBlockCache(),
 		fullValidator:   fv,
 		blockValidator:  hv,
 		chainSelector:   cs,
@@ -532,14 +541,28 @@ func (syncer *Syncer) handleNewTipSet(ctx context.Context, ci *block.ChainInfo)
 		return nil
 	}
 
+	if bbr, ok := syncer.badBlocks.HasTipSet(ci.Head); ok {
+		return errors.Wrapf(ErrChainHasBadTipSet, "tipset %s: %s", ci.Head, bbr)
+	}
+
 	syncer.reporter.UpdateStatus(status.SyncingStarted(syncer.clock.Now().Unix()), status.SyncHead(ci.Head), status.SyncHeight(ci.Height), status.SyncComplete(false))
 	defer syncer.reporter.UpdateStatus(status.SyncComplete(true))
 
+	syncer.reporter.StartTarget(ci, syncer.clock.Now())
+	syncer.reporter.UpdateTarget(ci.Head, status.TargetBase(syncer.staged))
+	defer func() {
+		syncer.reporter.FinishTarget(ci.Head, syncer.clock.Now(), err)
+	}()
+
 	syncer.reporter.UpdateStatus(func(s *status.Status) {
 		s.FetchingHead = ci.Head
 		s.FetchingHeight = ci.Height
 	})
 
+	if err := syncer.fastSync(ctx, ci); err != nil {
+		return errors.Wrapf(err, "failure fast syncing from the trusted checkpoint")
+	}
+
 	tipsets, err := syncer.fetchChainBlocks(ctx, syncer.staged, ci.Head)
 	if err != nil {
 		return errors.Wrapf(err, "failure fetching or validating headers")
@@ -551,6 +574,7 @@ func (syncer *Syncer) handleNewTipSet(ctx context.Context, ci *block.ChainInfo)
 	}
 
 	logSyncer.Infof("fetch & validate header success at %v %s ...", tipsets[0].EnsureHeight(), tipsets[0].Key())
+	syncer.reporter.UpdateTarget(ci.Head, status.TargetStage(status.StageMessages))
 	errProcessChan := make(chan error, 1)
 	errProcessChan <- nil //init
 	var wg sync.WaitGroup
@@ -565,6 +589,7 @@ func (syncer *Syncer) handleNewTipSet(ctx context.Context, ci *block.ChainInfo)
 			return err
 		}
 		logSyncer.Infof("finish to fetch message segement %d-%d", startTip, emdTipset)
+		syncer.reporter.UpdateTarget(ci.Head, status.TargetHeight(emdTipset))
 		err = <-errProcessChan
 		if err != nil {
 			return xerrors.Errorf("process message failed %v", err)
@@ -579,6 +604,7 @@ func (syncer *Syncer) handleNewTipSet(ctx context.Context, ci *block.ChainInfo)
 				errProcessChan <- errProcess
 				return
 			}
+			syncer.reporter.UpdateTarget(ci.Head, status.TargetValidated(emdTipset))
 			errProcessChan <- syncer.SetStagedHead(ctx)
 		}()
 
@@ -588,6 +614,7 @@ func (syncer *Syncer) handleNewTipSet(ctx context.Context, ci *block.ChainInfo)
 	if err != nil {
 		return err
 	}
+	syncer.reporter.UpdateTarget(ci.Head, status.TargetStage(status.StageValidating))
 	wg.Wait()
 	select {
 	case err = <-errProcessChan:
@@ -599,6 +626,7 @@ func (syncer *Syncer) handleNewTipSet(ctx context.Context, ci *block.ChainInfo)
 
 func (syncer *Syncer) fetchChainBlocks(ctx context.Context, knownTip *block.TipSet, targetTip block.TipSetKey) ([]*block.TipSet, error) {
 	var chainTipsets []*block.TipSet
+	target := targetTip
 
 	var flushDb = func(saveTips []*block.TipSet) error {
 		bs := bstore.NewTemporary()
@@ -621,6 +649,9 @@ loop:
 	for len(chainTipsets) == 0 || chainTipsets[len(chainTipsets)-1].EnsureHeight() > untilHeight {
 		tipset, err := syncer.chainStore.GetTipSet(targetTip)
 		if err == nil {
+			if err := syncer.checkBadBlocks(tipset, chainTipsets); err != nil {
+				return nil, err
+			}
 			chainTipsets = append(chainTipsets, tipset)
 			targetTip = tipset.EnsureParents()
 			count++
@@ -640,6 +671,7 @@ loop:
 		}
 
 		logSyncer.Infof("fetch  blocks %d height from %d-%d", len(fetchHeaders), fetchHeaders[0].EnsureHeight(), fetchHeaders[len(fetchHeaders)-1].EnsureHeight())
+		syncer.reporter.UpdateTarget(target, status.TargetHeight(fetchHeaders[len(fetchHeaders)-1].EnsureHeight()))
 		if err = flushDb(fetchHeaders); err != nil {
 			return nil, err
 		}
@@ -647,6 +679,9 @@ loop:
 			if b.EnsureHeight() < untilHeight {
 				break loop
 			}
+			if err := syncer.checkBadBlocks(b, chainTipsets); err != nil {
+				return nil, err
+			}
 			chainTipsets = append(chainTipsets, b)
 			targetTip = b.EnsureParents()
 		}
@@ -678,11 +713,10 @@ loop:
 	fork, err := syncer.syncFork(ctx, base, knownTip)
 	if err != nil {
 		if xerrors.Is(err, ErrForkTooLong) {
-			// TODO: we're marking this block bad in the same way that we mark invalid blocks bad. Maybe distinguish?
 			log.Warn("adding forked chain to our bad tipset cache")
-			/*		for _, b := range incoming.Blocks() {
-					syncer.bad.Add(b.Cid(), NewBadBlockReason(incoming.Cids(), "fork past finality"))
-				}*/
+			bbr := NewBadBlockReason(base.Key(), "fork past finality")
+			syncer.badBlocks.AddTipSet(base, bbr)
+			syncer.badBlocks.AddChain(chainTipsets[:len(chainTipsets)-1], bbr)
 		}
 		return nil, xerrors.Errorf("failed to sync fork: %w", err)
 	}
@@ -695,6 +729,17 @@ loop:
 	return chainTipsets, nil
 }
 
+// checkBadBlocks returns ErrChainHasBadTipSet if a block of the tipset is
+// bad, the descendants of the tipset fetched so far are then marked bad too.
+func (syncer *Syncer) checkBadBlocks(ts *block.TipSet, descendants []*block.TipSet) error {
+	bbr, ok := syncer.badBlocks.HasTipSet(ts.Key())
+	if !ok {
+		return nil
+	}
+	syncer.badBlocks.AddChain(descendants, bbr)
+	return errors.Wrapf(ErrChainHasBadTipSet, "tipset %s: %s", ts.Key(), bbr)
+}
+
 func (syncer *Syncer) syncFork(ctx context.Context, incoming *block.TipSet, known *block.TipSet) ([]*block.TipSet, error) {
 	// TODO: Does this mean we always ask for ForkLengthThreshold blocks from the network, even if we just need, like, 2?
 	// Would it not be better to ask in smaller chunks, given that an ~ForkLengthThreshold is very rare?
@@ -713,6 +758,11 @@ func (syncer *Syncer) syncFork(ctx context.Context, incoming *block.TipSet, know
 		return nil, xerrors.Errorf("failed to load next local tipset: %w", err)
 	}
 
+	checkPoint, err := syncer.chainStore.GetCheckPointTipSet()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load checkpoint tipset: %w", err)
+	}
+
 	for cur := 0; cur < len(tips); {
 		if nts.EnsureHeight() == 0 {
 			if !gensisiBlock.Equals(nts.At(0)) {
@@ -721,6 +771,11 @@ func (syncer *Syncer) syncFork(ctx context.Context, incoming *block.TipSet, know
 			return nil, xerrors.Errorf("synced chain forked at genesis, refusing to sync; incoming: %s", incoming.ToSlice())
 		}
 
+		// the common ancestor can only be below nts from here on
+		if nts.EnsureHeight() < checkPoint.EnsureHeight() {
+			return nil, xerrors.Errorf("refusing to sync fork of %s past checkpoint %d: %w", incoming.Key(), checkPoint.EnsureHeight(), chain.ErrForkBelowCheckPoint)
+		}
+
 		if nts.Equals(tips[cur]) {
 			return tips[:cur], nil
 		}
@@ -862,7 +917,12 @@ func (syncer *Syncer) processTipSetSeg(ctx context.Context, segTipset []*block.T
 				// have access to the chain. If syncOne fails for non-consensus reasons,
 				// there is no assumption that the running node's data is valid at all,
 				// so we don't really lose anything with this simplification.
-				syncer.badTipSets.AddChain(segTipset[i:])
+				// A cancelled sync says nothing about the tipset.
+				if ctx.Err() == nil {
+					bbr := NewBadBlockReason(ts.Key(), "%s", err)
+					syncer.badBlocks.AddTipSet(ts, bbr)
+					syncer.badBlocks.AddChain(segTipset[i+1:], bbr)
+				}
 				return errors.Wrapf(err, "failed to sync tipset %s, number %d of %d in chain", ts.Key(), i, len(segTipset))
 			}
 
@@ -885,6 +945,11 @@ func (syncer *Syncer) processTipSetSeg(ctx context.Context, segTipset []*block.T
 func (syncer *Syncer) stageIfHeaviest(ctx context.Context, candidate *block.TipSet) error {
 	// stageIfHeaviest sets the provided candidates to the staging head of the chain if they
 	// are heavier. Precondtion: candidates are validated and added to the store.
+	// Candidates forking from the staged chain below the checkpoint are never selected.
+	if err := syncer.chainStore.CheckFork(ctx, syncer.staged, candidate); err != nil {
+		return err
+	}
+
 	heavier, err := syncer.chainSelector.IsHeavier(ctx, candidate, syncer.staged)
 	if err != nil {
 		return err
@@ -905,6 +970,79 @@ func (syncer *Syncer) Status() status.Status {
 	return syncer.reporter.Status()
 }
 
+// SyncState returns the progress of the active and recently finished sync targets.
+func (syncer *Syncer) SyncState() status.SyncState {
+	return syncer.reporter.SyncState()
+}
+
+// ValidateBlock fully validates a block on top of its parent tipset, which
+// must be validated already. The block messages must be in the store.
+func (syncer *Syncer) ValidateBlock(ctx context.Context, blk *block.Block) error {
+	if bbr, ok := syncer.badBlocks.Has(blk.Cid()); ok {
+		return errors.Wrapf(ErrChainHasBadTipSet, "block %s: %s", blk.Cid(), bbr)
+	}
+	if bbr, ok := syncer.badBlocks.HasTipSet(blk.Parents); ok {
+		return errors.Wrapf(ErrChainHasBadTipSet, "parent %s: %s", blk.Parents, bbr)
+	}
+
+	parent, err := syncer.chainStore.GetTipSet(blk.Parents)
+	if err != nil {
+		return xerrors.Errorf("load parent tipset %s failed %w", blk.Parents, err)
+	}
+	if !syncer.chainStore.HasTipSetAndState(ctx, parent.Key()) {
+		return xerrors.Errorf("parent tipset %s is not validated", parent.Key())
+	}
+
+	ts, err := block.NewTipSet(blk)
+	if err != nil {
+		return err
+	}
+
+	parentWeight, err := syncer.chainSelector.Weight(ctx, parent)
+	if err != nil {
+		return xerrors.Errorf("calc parent weight failed %w", err)
+	}
+	parentReceiptRoot, err := syncer.chainStore.GetTipSetReceiptsRoot(parent.Key())
+	if err != nil {
+		return xerrors.Errorf("get parent tipset receipt failed %w", err)
+	}
+
+	if err := syncer.blockValidator.ValidateHeaderSemantic(ctx, blk, parent); err != nil {
+		return xerrors.Errorf("validate header failed %w", err)
+	}
+	if err := syncer.blockValidator.ValidateMessagesSemantic(ctx, blk, parent.Key()); err != nil {
+		return xerrors.Errorf("validate messages failed %w", err)
+	}
+	if err := syncer.fullValidator.ValidateMining(ctx, parent, ts, parentWeight, parentReceiptRoot); err != nil {
+		return xerrors.Errorf("validate mining failed %w", err)
+	}
+	return nil
+}
+
+// MarkBad marks a block bad, the syncer refuses the chains including it.
+func (syncer *Syncer) MarkBad(c cid.Cid) {
+	syncer.badBlocks.Add(c, NewBadBlockReason(block.NewTipSetKey(c), "manually marked bad"))
+}
+
+// UnmarkBad unmarks a bad block.
+func (syncer *Syncer) UnmarkBad(c cid.Cid) {
+	syncer.badBlocks.Remove(c)
+}
+
+// UnmarkAllBad unmarks all bad blocks.
+func (syncer *Syncer) UnmarkAllBad() {
+	syncer.badBlocks.Purge()
+}
+
+// CheckBad returns the reason a block is bad, an empty string if it isn't.
+func (syncer *Syncer) CheckBad(c cid.Cid) string {
+	bbr, ok := syncer.badBlocks.Has(c)
+	if !ok {
+		return ""
+	}
+	return bbr.String()
+}
+
 // TODO: this function effectively accepts unchecked input from the network,
 // either validate it here, or ensure that its validated elsewhere (maybe make
 // sure the blocksync code checks it?)
@@ -476,6 +476,85 @@ func TestSemanticallyBadTipSetFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "val semantic fails")
 }
 
+func TestMarkBadRefusesDescendants(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, syncer := setup(ctx, t)
+	genesis := builder.RequireTipSet(builder.Store().GetHead())
+
+	t1 := builder.AppendOn(genesis, 1)
+	t2 := builder.AppendOn(t1, 1)
+
+	syncer.MarkBad(t1.At(0).Cid())
+	assert.Contains(t, syncer.CheckBad(t1.At(0).Cid()), "manually marked bad")
+
+	err := syncer.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t2.Key(), heightFromTip(t, t2)), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cached bad tipset")
+
+	// The descendant is marked bad with the reason of its bad ancestor.
+	reason := syncer.CheckBad(t2.At(0).Cid())
+	assert.Contains(t, reason, "descends from bad tipset")
+	assert.Contains(t, reason, "manually marked bad")
+
+	syncer.UnmarkAllBad()
+	assert.Equal(t, "", syncer.CheckBad(t1.At(0).Cid()))
+	require.NoError(t, syncer.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t2.Key(), heightFromTip(t, t2)), false))
+	verifyHead(t, builder.Store(), t2)
+}
+
+func TestValidateBlock(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	eval := newPoisonValidator(t, 98, 99)
+	builder, syncer := setupWithValidator(ctx, t, eval, eval)
+	genesis := builder.RequireTipSet(builder.Store().GetHead())
+
+	t.Run("accepts a valid block on a validated parent", func(t *testing.T) {
+		t1 := builder.AppendOn(genesis, 1)
+		assert.NoError(t, syncer.ValidateBlock(ctx, t1.At(0)))
+	})
+
+	t.Run("rejects an invalid block", func(t *testing.T) {
+		t1 := builder.BuildOneOn(genesis, func(bb *chain.BlockBuilder) {
+			bb.SetTimestamp(98) // poison header val
+		})
+		err := syncer.ValidateBlock(ctx, t1.At(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "val semantic fails")
+	})
+
+	t.Run("rejects a cached bad block", func(t *testing.T) {
+		t1 := builder.AppendOn(genesis, 1)
+		syncer.MarkBad(t1.At(0).Cid())
+		defer syncer.UnmarkAllBad()
+
+		err := syncer.ValidateBlock(ctx, t1.At(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "manually marked bad")
+	})
+
+	t.Run("rejects a block on a bad parent", func(t *testing.T) {
+		t1 := builder.AppendOn(genesis, 1)
+		t2 := builder.AppendOn(t1, 1)
+		syncer.MarkBad(t1.At(0).Cid())
+		defer syncer.UnmarkAllBad()
+
+		err := syncer.ValidateBlock(ctx, t2.At(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "manually marked bad")
+	})
+
+	t.Run("rejects a block on a parent not validated", func(t *testing.T) {
+		t1 := builder.AppendOn(genesis, 1)
+		t2 := builder.AppendOn(t1, 1)
+
+		err := syncer.ValidateBlock(ctx, t2.At(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not validated")
+	})
+}
+
 func TestSyncerStatus(t *testing.T) {
 	tf.UnitTest(t)
 	ctx := context.Background()
@@ -3,6 +3,7 @@ package status
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/pkg/block"
@@ -13,6 +14,11 @@ import (
 type Reporter interface {
 	UpdateStatus(...UpdateFn)
 	Status() Status
+
+	StartTarget(ci *block.ChainInfo, start time.Time)
+	UpdateTarget(target block.TipSetKey, update ...TargetUpdateFn)
+	FinishTarget(target block.TipSetKey, end time.Time, err error)
+	SyncState() SyncState
 }
 
 // Status defines a structure used to represent the state of a chain store and syncer.
@@ -39,6 +45,9 @@ type Status struct {
 type reporter struct {
 	statusMu sync.Mutex
 	status   *Status
+
+	active []*TargetStatus
+	recent []*TargetStatus
 }
 
 // UpdateFn defines a type for ipdating syncer status.
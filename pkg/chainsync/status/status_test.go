@@ -1,11 +1,13 @@
 package status_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/stretchr/testify/assert"
-
-	//"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/venus/pkg/block"
 	"github.com/filecoin-project/venus/pkg/chainsync/status"
@@ -41,3 +43,38 @@ func TestStatus(t *testing.T) {
 		status.FetchHead(t3), status.FetchHeight(789))
 	assert.Equal(t, expStatus, sr.Status())
 }
+
+func TestSyncState(t *testing.T) {
+	tf.UnitTest(t)
+
+	sr := status.NewReporter()
+	cidFn := types.NewCidForTestGetter()
+	start := time.Unix(1234567890, 0)
+
+	ok := block.NewChainInfo("", "", block.NewTipSetKey(cidFn()), 100)
+	failed := block.NewChainInfo("", "", block.NewTipSetKey(cidFn()), 120)
+	sr.StartTarget(ok, start)
+	sr.StartTarget(failed, start)
+
+	sr.UpdateTarget(ok.Head, status.TargetStage(status.StageMessages), status.TargetHeight(50))
+	state := sr.SyncState()
+	require.Len(t, state.Active, 2)
+	assert.Equal(t, status.StageMessages, state.Active[0].Stage)
+	assert.Equal(t, abi.ChainEpoch(50), state.Active[0].Height)
+	assert.Equal(t, status.StageHeaders, state.Active[1].Stage)
+
+	sr.UpdateTarget(ok.Head, status.TargetValidated(40))
+	assert.Equal(t, abi.ChainEpoch(50), sr.SyncState().Active[0].Height)
+	sr.UpdateTarget(ok.Head, status.TargetStage(status.StageValidating))
+	assert.Equal(t, abi.ChainEpoch(40), sr.SyncState().Active[0].Height)
+
+	sr.FinishTarget(ok.Head, start.Add(time.Minute), nil)
+	sr.FinishTarget(failed.Head, start.Add(time.Minute), errors.New("boom"))
+	state = sr.SyncState()
+	assert.Empty(t, state.Active)
+	require.Len(t, state.Recent, 2)
+	assert.Equal(t, status.StageError, state.Recent[0].Stage)
+	assert.Equal(t, "boom", state.Recent[0].Err)
+	assert.Equal(t, status.StageComplete, state.Recent[1].Stage)
+	assert.Equal(t, abi.ChainEpoch(100), state.Recent[1].Height)
+}
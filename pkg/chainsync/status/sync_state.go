@@ -0,0 +1,212 @@
+package status
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	"github.com/filecoin-project/venus/pkg/block"
+)
+
+// RecentTargetsLimit is the number of finished targets kept in the sync state.
+const RecentTargetsLimit = 10
+
+// Stage is the stage of the sync of a target.
+type Stage int
+
+const (
+	// StageIdle is the stage of a target not started yet.
+	StageIdle Stage = iota
+	// StageHeaders is the stage fetching the block headers from the target down to the base.
+	StageHeaders
+	// StageMessages is the stage fetching the messages of the fetched headers.
+	StageMessages
+	// StageValidating is the stage executing and validating the fetched tipsets.
+	StageValidating
+	// StageComplete is the stage of a target synced successfully.
+	StageComplete
+	// StageError is the stage of a target whose sync failed.
+	StageError
+	// StageState is the stage fetching the state trees of a trusted checkpoint.
+	StageState
+)
+
+var stageNames = map[Stage]string{
+	StageIdle:       "idle",
+	StageHeaders:    "headers",
+	StageMessages:   "messages",
+	StageValidating: "validating",
+	StageComplete:   "complete",
+	StageError:      "error",
+	StageState:      "state",
+}
+
+// String returns the name of the stage.
+func (s Stage) String() string {
+	if name, ok := stageNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// MarshalText encodes the stage as its name.
+func (s Stage) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a stage from its name.
+func (s *Stage) UnmarshalText(text []byte) error {
+	for stage, name := range stageNames {
+		if name == string(text) {
+			*s = stage
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown sync stage %s", text)
+}
+
+// TargetStatus is the progress of the sync of a single target.
+type TargetStatus struct {
+	Target       block.TipSetKey
+	Sender       peer.ID
+	Stage        Stage
+	Base         block.TipSetKey
+	BaseHeight   abi.ChainEpoch
+	TargetHeight abi.ChainEpoch
+	// Height is the height reached by the current stage.
+	Height abi.ChainEpoch
+	// ValidatedHeight is the height up to which tipsets are validated, validation runs
+	// while the messages of the next tipsets are fetched.
+	ValidatedHeight abi.ChainEpoch
+	Start           time.Time
+	End             time.Time
+	Err             string
+}
+
+// SyncState describes the targets being synced, the ones waiting to be synced and the
+// recently finished ones.
+type SyncState struct {
+	// Active are the targets being synced, oldest first.
+	Active []TargetStatus
+	// Queued are the targets waiting in the dispatcher, highest first.
+	Queued []block.ChainInfo
+	// Recent are the last finished targets, newest first.
+	Recent []TargetStatus
+}
+
+// TargetUpdateFn defines a type for updating the status of a target.
+type TargetUpdateFn func(*TargetStatus)
+
+// TargetStage sets the stage of the target and resets the height reached.
+func TargetStage(stage Stage) TargetUpdateFn {
+	return func(s *TargetStatus) {
+		s.Stage = stage
+		s.Height = s.BaseHeight
+		if stage == StageValidating {
+			s.Height = s.ValidatedHeight
+		}
+	}
+}
+
+// TargetBase sets the tipset the target is synced from.
+func TargetBase(base *block.TipSet) TargetUpdateFn {
+	return func(s *TargetStatus) {
+		s.Base = base.Key()
+		s.BaseHeight = base.EnsureHeight()
+		s.Height = s.BaseHeight
+		s.ValidatedHeight = s.BaseHeight
+	}
+}
+
+// TargetHeight sets the height reached by the current stage.
+func TargetHeight(h abi.ChainEpoch) TargetUpdateFn {
+	return func(s *TargetStatus) {
+		s.Height = h
+	}
+}
+
+// TargetValidated sets the height up to which tipsets are validated.
+func TargetValidated(h abi.ChainEpoch) TargetUpdateFn {
+	return func(s *TargetStatus) {
+		s.ValidatedHeight = h
+		if s.Stage == StageValidating {
+			s.Height = h
+		}
+	}
+}
+
+// StartTarget adds a target to the active targets.
+func (sr *reporter) StartTarget(ci *block.ChainInfo, start time.Time) {
+	sr.statusMu.Lock()
+	defer sr.statusMu.Unlock()
+
+	sr.active = append(sr.active, &TargetStatus{
+		Target:       ci.Head,
+		Sender:       ci.Sender,
+		Stage:        StageHeaders,
+		TargetHeight: ci.Height,
+		Start:        start,
+	})
+}
+
+// UpdateTarget updates the status of an active target.
+func (sr *reporter) UpdateTarget(target block.TipSetKey, update ...TargetUpdateFn) {
+	sr.statusMu.Lock()
+	defer sr.statusMu.Unlock()
+
+	for _, s := range sr.active {
+		if s.Target.Equals(target) {
+			for _, u := range update {
+				u(s)
+			}
+			return
+		}
+	}
+}
+
+// FinishTarget moves an active target to the recent targets, failed if err is not nil.
+func (sr *reporter) FinishTarget(target block.TipSetKey, end time.Time, err error) {
+	sr.statusMu.Lock()
+	defer sr.statusMu.Unlock()
+
+	for i, s := range sr.active {
+		if !s.Target.Equals(target) {
+			continue
+		}
+		sr.active = append(sr.active[:i], sr.active[i+1:]...)
+
+		s.End = end
+		if err != nil {
+			s.Stage = StageError
+			s.Err = err.Error()
+		} else {
+			s.Stage = StageComplete
+			s.Height = s.TargetHeight
+		}
+		sr.recent = append([]*TargetStatus{s}, sr.recent...)
+		if len(sr.recent) > RecentTargetsLimit {
+			sr.recent = sr.recent[:RecentTargetsLimit]
+		}
+		return
+	}
+}
+
+// SyncState returns a copy of the active and recent targets.
+func (sr *reporter) SyncState() SyncState {
+	sr.statusMu.Lock()
+	defer sr.statusMu.Unlock()
+
+	state := SyncState{
+		Active: make([]TargetStatus, len(sr.active)),
+		Recent: make([]TargetStatus, len(sr.recent)),
+	}
+	for i, s := range sr.active {
+		state.Active[i] = *s
+	}
+	for i, s := range sr.recent {
+		state.Recent[i] = *s
+	}
+	return state
+}
@@ -1,5 +1,7 @@
 package config
 
+import "fmt"
+
 type DrandEnum int
 
 const (
@@ -10,6 +12,23 @@ const (
 	DrandIncentinet
 )
 
+func (e DrandEnum) String() string {
+	switch e {
+	case DrandMainnet:
+		return "mainnet"
+	case DrandTestnet:
+		return "testnet"
+	case DrandDevnet:
+		return "devnet"
+	case DrandLocalnet:
+		return "localnet"
+	case DrandIncentinet:
+		return "incentinet"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(e))
+	}
+}
+
 type DrandConf struct {
 	Servers       []string
 	Relays        []string
@@ -11,6 +11,7 @@ import (
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
 	"github.com/pkg/errors"
 )
 
@@ -18,6 +19,7 @@ import (
 type Config struct {
 	API           *APIConfig           `json:"api"`
 	Bootstrap     *BootstrapConfig     `json:"bootstrap"`
+	Chain         *ChainConfig         `json:"chain"`
 	Datastore     *DatastoreConfig     `json:"datastore"`
 	Mpool         *MessagePoolConfig   `json:"mpool"`
 	NetworkParams *NetworkParamsConfig `json:"parameters"`
@@ -76,11 +78,38 @@ func newDefaultDatastoreConfig() *DatastoreConfig {
 type SwarmConfig struct {
 	Address            string `json:"address"`
 	PublicRelayAddress string `json:"public_relay_address,omitempty"`
+	// ConnMgrLow is the number of connections kept when trimming connections.
+	ConnMgrLow int `json:"connMgrLow"`
+	// ConnMgrHigh is the number of connections above which connections are trimmed.
+	ConnMgrHigh int `json:"connMgrHigh"`
+	// ConnMgrGrace is how long a new connection can't be trimmed.
+	ConnMgrGrace string `json:"connMgrGrace"`
+	// ConnMgrMaxPerPeer is the number of connections kept to a single peer, 0 for no limit.
+	ConnMgrMaxPerPeer int `json:"connMgrMaxPerPeer"`
+	// ProtectedPeers are the peer ids or p2p multiaddrs of the peers whose
+	// connections are never trimmed.
+	ProtectedPeers []string `json:"protectedPeers,omitempty"`
+	// AllowedPeers are the peer ids or p2p multiaddrs of the only peers the
+	// node talks to, all peers are allowed when empty.
+	AllowedPeers []string `json:"allowedPeers,omitempty"`
+	// AllowedCIDRs are the only networks the node connects to, all networks
+	// are allowed when empty.
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+	// DeniedCIDRs are the networks the node never connects to.
+	DeniedCIDRs []string `json:"deniedCIDRs,omitempty"`
+	// PrivateNetworkKey is the path of a libp2p pre-shared key file, absolute
+	// or relative to the repo, making the node only talk to the nodes with the
+	// same key. Changes require a restart.
+	PrivateNetworkKey string `json:"privateNetworkKey,omitempty"`
 }
 
 func newDefaultSwarmConfig() *SwarmConfig {
 	return &SwarmConfig{
-		Address: "/ip4/0.0.0.0/tcp/6000",
+		Address:           "/ip4/0.0.0.0/tcp/6000",
+		ConnMgrLow:        150,
+		ConnMgrHigh:       180,
+		ConnMgrGrace:      "20s",
+		ConnMgrMaxPerPeer: 4,
 	}
 }
 
@@ -89,6 +118,14 @@ type BootstrapConfig struct {
 	Addresses        []string `json:"addresses"`
 	MinPeerThreshold int      `json:"minPeerThreshold"`
 	Period           string   `json:"period,omitempty"`
+	// MDNS connects to the nodes of the network found on the local network,
+	// for local devnets.
+	MDNS bool `json:"mdns,omitempty"`
+	// MDNSInterval is the period of the mDNS queries, 10s by default.
+	MDNSInterval string `json:"mdnsInterval,omitempty"`
+	// Rendezvous advertises the node in the DHT under the network name and
+	// connects to the other nodes advertising it.
+	Rendezvous bool `json:"rendezvous,omitempty"`
 }
 
 // TODO: provide bootstrap node addresses
@@ -100,6 +137,32 @@ func newDefaultBootstrapConfig() *BootstrapConfig {
 	}
 }
 
+// ChainConfig holds all configuration options related to the chain store and its sync.
+type ChainConfig struct {
+	// AutoCheckPointDistance moves the checkpoint forward to this many epochs behind the
+	// head as the head advances, so that finalized epochs can never be reorged.
+	// 0 disables it, the finality used by consensus is 900 epochs.
+	AutoCheckPointDistance abi.ChainEpoch `json:"autoCheckPointDistance"`
+	// TrustedCheckpoint, when set, makes a node whose head is below it sync from
+	// the checkpoint instead of executing the whole chain.
+	TrustedCheckpoint *TrustedCheckpointConfig `json:"trustedCheckpoint,omitempty"`
+}
+
+// TrustedCheckpointConfig is a tipset trusted by the operator and the state
+// root it was built on, the parent state root of its blocks.
+type TrustedCheckpointConfig struct {
+	Key       []string `json:"key"`
+	StateRoot string   `json:"stateRoot"`
+	// BackFill fetches the messages of the chain below the checkpoint in the background.
+	BackFill bool `json:"backfill"`
+}
+
+func newDefaultChainConfig() *ChainConfig {
+	return &ChainConfig{
+		AutoCheckPointDistance: 0,
+	}
+}
+
 // WalletConfig holds all configuration options related to the wallet.
 type WalletConfig struct {
 	DefaultAddress address.Address `json:"defaultAddress,omitempty"`
@@ -217,6 +280,22 @@ type ForkUpgradeConfig struct {
 	BreezeGasTampingDuration abi.ChainEpoch
 	UpgradeCalicoHeight      abi.ChainEpoch
 	UpgradePersianHeight     abi.ChainEpoch
+
+	// UpgradeSchedule replaces the upgrades at the heights above when not empty, to declare the
+	// upgrades of a devnet. The heights above still apply to gas prices and the circulating supply.
+	UpgradeSchedule []*UpgradeConfig `json:",omitempty"`
+}
+
+// UpgradeConfig is an upgrade of the network to a version at a height, disabled when the height
+// is negative.
+type UpgradeConfig struct {
+	Height  abi.ChainEpoch
+	Network network.Version
+	Name    string
+	// Migration is the name of a registered state migration, empty when the upgrade only
+	// changes the network version.
+	Migration string
+	Expensive bool
 }
 
 var DefaultForkUpgradeParam = &ForkUpgradeConfig{
@@ -254,6 +333,7 @@ func NewDefaultConfig() *Config {
 	return &Config{
 		API:           newDefaultAPIConfig(),
 		Bootstrap:     newDefaultBootstrapConfig(),
+		Chain:         newDefaultChainConfig(),
 		Datastore:     newDefaultDatastoreConfig(),
 		Mpool:         newDefaultMessagePoolConfig(),
 		NetworkParams: newDefaultNetworkParamsConfig(),
@@ -223,6 +223,69 @@ func (c *Expected) CallWithGas(ctx context.Context, msg *types.UnsignedMessage)
 	return c.processor.ProcessUnsignedMessage(ctx, msg, priorState, vms, vmOption)
 }
 
+// Call applies the message against the parent state of the given tipset, or of the head if ts is nil.
+// Neither the signature nor the nonce of the message is checked and gas is free, so any
+// account may call any actor method. The resulting state is never flushed to the store.
+func (c *Expected) Call(ctx context.Context, msg *types.UnsignedMessage, ts *block.TipSet) (*vm.Ret, error) {
+	ctx, span := trace.StartSpan(ctx, "Expected.Call")
+	defer span.End()
+
+	if ts == nil {
+		var err error
+		ts, err = c.chainState.GetTipSet(c.chainState.GetHead())
+		if err != nil {
+			return nil, err
+		}
+	}
+	span.AddAttributes(trace.StringAttribute("tipset", ts.String()))
+
+	vms := vm.NewStorage(c.bstore)
+	priorState, err := state.LoadState(ctx, vms, ts.At(0).ParentStateRoot.Cid)
+	if err != nil {
+		return nil, err
+	}
+
+	fromActor, found, err := priorState.GetActor(ctx, msg.From)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, xerrors.Errorf("call from actor %s not found at tipset %s", msg.From, ts.Key())
+	}
+
+	callMsg := *msg
+	callMsg.Nonce = fromActor.Nonce
+	callMsg.GasFeeCap = big.Zero()
+	callMsg.GasPremium = big.Zero()
+	if callMsg.GasLimit == 0 {
+		callMsg.GasLimit = constants.BlockGasLimit
+	}
+	if callMsg.Value.Nil() {
+		callMsg.Value = big.Zero()
+	}
+
+	rnd := headRandomness{
+		chain: c.rnd,
+		head:  ts.Key(),
+	}
+
+	vmOption := vm.VmOption{
+		CircSupplyCalculator: func(ctx context.Context, epoch abi.ChainEpoch, tree state.Tree) (abi.TokenAmount, error) {
+			dertail, err := c.circulatingSupplyCalculator.GetCirculatingSupplyDetailed(ctx, epoch, tree)
+			if err != nil {
+				return abi.TokenAmount{}, err
+			}
+			return dertail.FilCirculating, nil
+		},
+		NtwkVersionGetter: c.fork.GetNtwkVersion,
+		Rnd:               &rnd,
+		BaseFee:           big.Zero(),
+		Epoch:             ts.At(0).Height,
+		GasPriceSchedule:  c.gasPirceSchedule,
+	}
+	return c.processor.ProcessUnsignedMessage(ctx, &callMsg, priorState, vms, vmOption)
+}
+
 // RunStateTransition applies the messages in a tipset to a state, and persists that new state.
 // It errors if the tipset was not mined according to the EC rules, or if any of the messages
 // in the tipset results in an error.
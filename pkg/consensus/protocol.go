@@ -37,5 +37,8 @@ type Protocol interface {
 	// CallWithGas
 	CallWithGas(ctx context.Context, msg *types.UnsignedMessage) (*vm.Ret, error)
 
+	// Call applies a message to the parent state of a tipset without persisting the result.
+	Call(ctx context.Context, msg *types.UnsignedMessage, ts *block.TipSet) (*vm.Ret, error)
+
 	ValidateMining(ctx context.Context, parent, ts *block.TipSet, parentWeight big.Int, parentReceiptRoot cid.Cid) error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-state-types/abi"
@@ -69,6 +70,10 @@ type HelloProtocolHandler struct {
 	networkName string
 
 	peerMgr fnet.IPeerMgr
+
+	lk sync.Mutex
+	// peerGenesis is the genesis the connected peers said hello with.
+	peerGenesis map[peer.ID]cid.Cid
 }
 
 type PeerDiscoveredCallback func(ci *block.ChainInfo)
@@ -83,9 +88,19 @@ func NewHelloProtocolHandler(h host.Host, peerMgr fnet.IPeerMgr, gen cid.Cid, ne
 		genesis:     gen,
 		networkName: networkName,
 		peerMgr:     peerMgr,
+		peerGenesis: make(map[peer.ID]cid.Cid),
 	}
 }
 
+// PeerGenesis returns the genesis a connected peer said hello with, false if
+// it didn't.
+func (h *HelloProtocolHandler) PeerGenesis(p peer.ID) (cid.Cid, bool) {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	genesis, ok := h.peerGenesis[p]
+	return genesis, ok
+}
+
 // Register registers the handler with the network.
 func (h *HelloProtocolHandler) Register(peerDiscoveredCallback PeerDiscoveredCallback, getHeaviestTipSet GetTipSetFunc) {
 	// register callbacks
@@ -115,6 +130,14 @@ func (h *HelloProtocolHandler) handleNewStream(s net.Stream) {
 
 	// process the hello message
 	from := s.Conn().RemotePeer()
+	h.lk.Lock()
+	h.peerGenesis[from] = hello.GenesisHash.Cid
+	h.lk.Unlock()
+	if h.peerMgr.IsBanned(from) {
+		log.Debugf("ignoring hello from banned peer %s", from)
+		_ = s.Conn().Close()
+		return
+	}
 	ci, err := h.processHelloMessage(from, hello)
 	switch {
 	// no error
@@ -255,6 +278,9 @@ func (hn *helloProtocolNotifiee) Connected(n net.Network, c net.Conn) {
 	// - read LatencyMessage response on stream
 	//
 	// Terminate the connection if it has a different genesis block
+	if hn.asHandler().peerMgr.IsBanned(c.RemotePeer()) {
+		return
+	}
 	go func() {
 		// add timeout
 		ctx, cancel := context.WithTimeout(context.Background(), helloTimeout)
@@ -285,6 +311,15 @@ func (hn *helloProtocolNotifiee) Connected(n net.Network, c net.Conn) {
 
 func (hn *helloProtocolNotifiee) Listen(n net.Network, a ma.Multiaddr)      { /* empty */ }
 func (hn *helloProtocolNotifiee) ListenClose(n net.Network, a ma.Multiaddr) { /* empty */ }
-func (hn *helloProtocolNotifiee) Disconnected(n net.Network, c net.Conn)    { /* empty */ }
 func (hn *helloProtocolNotifiee) OpenedStream(n net.Network, s net.Stream)  { /* empty */ }
 func (hn *helloProtocolNotifiee) ClosedStream(n net.Network, s net.Stream)  { /* empty */ }
+
+func (hn *helloProtocolNotifiee) Disconnected(n net.Network, c net.Conn) {
+	if len(n.ConnsToPeer(c.RemotePeer())) > 0 {
+		return
+	}
+	h := hn.asHandler()
+	h.lk.Lock()
+	delete(h.peerGenesis, c.RemotePeer())
+	h.lk.Unlock()
+}
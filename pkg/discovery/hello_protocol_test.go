@@ -66,7 +66,8 @@ func TestHelloHandshake(t *testing.T) {
 	aPeerMgr, err := mockPeerMgr(ctx, t, a)
 	require.NoError(t, err)
 
-	discovery.NewHelloProtocolHandler(a, aPeerMgr, genesisA.Cid(), "").Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
+	helloA := discovery.NewHelloProtocolHandler(a, aPeerMgr, genesisA.Cid(), "")
+	helloA.Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
 	discovery.NewHelloProtocolHandler(b, aPeerMgr, genesisA.Cid(), "").Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
 
 	msc1.On("HelloCallback", b.ID(), heavy2.Key(), abi.ChainEpoch(3)).Return()
@@ -97,6 +98,10 @@ func TestHelloHandshake(t *testing.T) {
 
 		return msc1Done && msc2Done, nil
 	}))
+
+	genesis, ok := helloA.PeerGenesis(b.ID())
+	require.True(t, ok)
+	assert.Equal(t, genesisA.Cid(), genesis)
 }
 
 func TestHelloBadGenesis(t *testing.T) {
@@ -188,5 +193,5 @@ func mockPeerMgr(ctx context.Context, t *testing.T, h host.Host) (*net.PeerMgr,
 	addrInfo, err := net.ParseAddresses(ctx, repo.NewInMemoryRepo().Config().Bootstrap.Addresses)
 	require.NoError(t, err)
 
-	return net.NewPeerMgr(h, dht.NewDHT(ctx, h, ds.NewMapDatastore()), 10, addrInfo)
+	return net.NewPeerMgr(h, dht.NewDHT(ctx, h, ds.NewMapDatastore()), 10, addrInfo, net.NewBanList())
 }
@@ -0,0 +1,152 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	coredisc "github.com/libp2p/go-libp2p-core/discovery"
+	host "github.com/libp2p/go-libp2p-core/host"
+	inet "github.com/libp2p/go-libp2p-core/network"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/routing"
+	libp2pdisc "github.com/libp2p/go-libp2p-discovery"
+	mdns "github.com/libp2p/go-libp2p/p2p/discovery"
+)
+
+var logLocalDiscovery = logging.Logger("net.discovery")
+
+const (
+	// DefaultMDNSInterval is the default period of the mDNS queries.
+	DefaultMDNSInterval = 10 * time.Second
+	// RendezvousPeriod is the period of the rendezvous peer searches.
+	RendezvousPeriod = time.Minute
+	// rendezvousPeerLimit bounds the peers returned by a rendezvous search.
+	rendezvousPeerLimit = 20
+	// discoveredConnectTimeout is how long to wait for a connection to a discovered peer.
+	discoveredConnectTimeout = 20 * time.Second
+)
+
+// RendezvousNamespace is the namespace under which the nodes of a network
+// advertise themselves.
+func RendezvousNamespace(networkName string) string {
+	return "/fil/venus/" + networkName
+}
+
+// mdnsServiceTag is the mDNS service name of the nodes of a network.
+func mdnsServiceTag(networkName string) string {
+	return fmt.Sprintf("_venus-%s._udp", networkName)
+}
+
+// connectDiscovered connects to a discovered peer, the hello protocol then
+// tracks the peer if it is on our chain.
+func connectDiscovered(ctx context.Context, h host.Host, pi peer.AddrInfo, source string) {
+	if pi.ID == h.ID() || h.Network().Connectedness(pi.ID) == inet.Connected {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, discoveredConnectTimeout)
+	defer cancel()
+	if err := h.Connect(ctx, pi); err != nil {
+		logLocalDiscovery.Debugf("failed to connect to peer %s found by %s: %s", pi.ID, source, err)
+		return
+	}
+	logLocalDiscovery.Infof("connected to peer %s found by %s", pi.ID, source)
+}
+
+// MDNSDiscovery connects to the nodes of the same network found on the local
+// network with mDNS.
+type MDNSDiscovery struct {
+	h       host.Host
+	ctx     context.Context
+	service mdns.Service
+}
+
+// NewMDNSDiscovery starts the mDNS discovery of the nodes of the network,
+// it runs until the context is cancelled or it is closed.
+func NewMDNSDiscovery(ctx context.Context, h host.Host, interval time.Duration, networkName string) (*MDNSDiscovery, error) {
+	service, err := mdns.NewMdnsService(ctx, h, interval, mdnsServiceTag(networkName))
+	if err != nil {
+		return nil, err
+	}
+	md := &MDNSDiscovery{
+		h:       h,
+		ctx:     ctx,
+		service: service,
+	}
+	service.RegisterNotifee(md)
+	return md, nil
+}
+
+// HandlePeerFound connects to the found peer, it implements mdns.Notifee.
+func (md *MDNSDiscovery) HandlePeerFound(pi peer.AddrInfo) {
+	go connectDiscovered(md.ctx, md.h, pi, "mdns")
+}
+
+// Close stops the mDNS discovery.
+func (md *MDNSDiscovery) Close() error {
+	return md.service.Close()
+}
+
+// RendezvousDiscovery advertises the node in the DHT under the network name
+// and periodically connects to the other nodes advertising it.
+type RendezvousDiscovery struct {
+	h         host.Host
+	discovery *libp2pdisc.RoutingDiscovery
+	ns        string
+	period    time.Duration
+
+	cancel context.CancelFunc
+}
+
+// NewRendezvousDiscovery creates a RendezvousDiscovery of the nodes of the network.
+func NewRendezvousDiscovery(h host.Host, router routing.ContentRouting, networkName string, period time.Duration) *RendezvousDiscovery {
+	return &RendezvousDiscovery{
+		h:         h,
+		discovery: libp2pdisc.NewRoutingDiscovery(router),
+		ns:        RendezvousNamespace(networkName),
+		period:    period,
+	}
+}
+
+// Start advertises the node and starts searching for peers, cancel ctx or
+// call Stop to stop it.
+func (rd *RendezvousDiscovery) Start(ctx context.Context) {
+	ctx, rd.cancel = context.WithCancel(ctx)
+	libp2pdisc.Advertise(ctx, rd.discovery, rd.ns)
+
+	go func() {
+		ticker := time.NewTicker(rd.period)
+		defer ticker.Stop()
+
+		for {
+			rd.findPeers(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (rd *RendezvousDiscovery) findPeers(ctx context.Context) {
+	peers, err := rd.discovery.FindPeers(ctx, rd.ns, coredisc.Limit(rendezvousPeerLimit))
+	if err != nil {
+		logLocalDiscovery.Warnf("rendezvous search of %s failed: %s", rd.ns, err)
+		return
+	}
+	for pi := range peers {
+		if len(pi.Addrs) == 0 {
+			continue
+		}
+		go connectDiscovered(ctx, rd.h, pi, "rendezvous")
+	}
+}
+
+// Stop stops advertising the node and searching for peers.
+func (rd *RendezvousDiscovery) Stop() {
+	if rd.cancel != nil {
+		rd.cancel()
+	}
+}
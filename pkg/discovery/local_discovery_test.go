@@ -0,0 +1,95 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	inet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	th "github.com/filecoin-project/venus/pkg/testhelpers"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+// fakeRouting provides the content of a namespace from a fixed set of peers.
+type fakeRouting struct {
+	providers []peer.AddrInfo
+	provided  chan cid.Cid
+}
+
+func (fr *fakeRouting) Provide(_ context.Context, c cid.Cid, _ bool) error {
+	select {
+	case fr.provided <- c:
+	default:
+	}
+	return nil
+}
+
+func (fr *fakeRouting) FindProvidersAsync(ctx context.Context, _ cid.Cid, _ int) <-chan peer.AddrInfo {
+	out := make(chan peer.AddrInfo, len(fr.providers))
+	for _, pi := range fr.providers {
+		out <- pi
+	}
+	close(out)
+	return out
+}
+
+func TestConnectDiscovered(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mn, err := mocknet.WithNPeers(ctx, 2)
+	require.NoError(t, err)
+	require.NoError(t, mn.LinkAll())
+	a, b := mn.Hosts()[0], mn.Hosts()[1]
+
+	// connecting to ourselves is skipped
+	connectDiscovered(ctx, a, peer.AddrInfo{ID: a.ID(), Addrs: a.Addrs()}, "test")
+	assert.Empty(t, a.Network().Conns())
+
+	connectDiscovered(ctx, a, peer.AddrInfo{ID: b.ID(), Addrs: b.Addrs()}, "test")
+	assert.Equal(t, inet.Connected, a.Network().Connectedness(b.ID()))
+}
+
+func TestRendezvousDiscovery(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mn, err := mocknet.WithNPeers(ctx, 3)
+	require.NoError(t, err)
+	require.NoError(t, mn.LinkAll())
+	a, b, c := mn.Hosts()[0], mn.Hosts()[1], mn.Hosts()[2]
+
+	router := &fakeRouting{
+		providers: []peer.AddrInfo{
+			{ID: b.ID(), Addrs: b.Addrs()},
+			// peers without addresses are skipped
+			{ID: c.ID()},
+		},
+		provided: make(chan cid.Cid, 1),
+	}
+	rd := NewRendezvousDiscovery(a, router, "testnet", time.Hour)
+	assert.Equal(t, "/fil/venus/testnet", rd.ns)
+
+	rd.Start(ctx)
+	defer rd.Stop()
+
+	select {
+	case <-router.provided:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the node was not advertised")
+	}
+	require.NoError(t, th.WaitForIt(50, 100*time.Millisecond, func() (bool, error) {
+		return a.Network().Connectedness(b.ID()) == inet.Connected, nil
+	}))
+	assert.NotEqual(t, inet.Connected, a.Network().Connectedness(c.ID()))
+}
@@ -63,6 +63,15 @@ func (tracker *PeerTracker) Track(ci *block.ChainInfo) {
 	logPeerTracker.Infow("Track peer", "chainInfo", ci, "new", !tracking, "count", len(tracker.peers), "trusted", trusted)
 }
 
+// Get returns the chain info of a tracked peer.
+func (tracker *PeerTracker) Get(pid peer.ID) (*block.ChainInfo, bool) {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+
+	ci, ok := tracker.peers[pid]
+	return ci, ok
+}
+
 // Self returns the peer tracker's owner ID
 func (tracker *PeerTracker) Self() peer.ID {
 	return tracker.self
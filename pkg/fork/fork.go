@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/binary"
 	"math"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -48,18 +49,34 @@ var log = logging.Logger("fork")
 
 // UpgradeFunc is a migration function run at every upgrade.
 //
+// - The cache holds the results of the pre-migrations of the upgrade.
 // - The oldState is the state produced by the upgrade epoch.
 // - The returned newState is the new state that will be used by the next epoch.
 // - The height is the upgrade epoch height (already executed).
 // - The tipset is the tipset for the last non-null block before the upgrade. Do
 //   not assume that ts.Height() is the upgrade height.
-type UpgradeFunc func(ctx context.Context, sm *ChainFork, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) (newState cid.Cid, err error)
+type UpgradeFunc func(ctx context.Context, sm *ChainFork, cache MigrationCache, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) (newState cid.Cid, err error)
+
+// PreMigrationFunc runs a migration ahead of the upgrade, in the background, writing the results
+// the migration reuses at the upgrade epoch to the cache.
+//
+// - The oldState is the parent state of the lookback tipset ts.
+// - The height is the upgrade epoch height.
+// - It must return when ctx is canceled, on a reorg or when the pre-migration window ends.
+//
+// No migration has a pre-migration yet: every upgrade of the schedules, the default one and the
+// one declared in config, runs its whole migration at the upgrade epoch.
+type PreMigrationFunc func(ctx context.Context, sm *ChainFork, cache MigrationCache, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) error
 
 type Upgrade struct {
 	Height    abi.ChainEpoch
 	Network   network.Version
+	Name      string
 	Expensive bool
 	Migration UpgradeFunc
+	// PreMigrate runs in each of the PreMigrations windows.
+	PreMigrate    PreMigrationFunc
+	PreMigrations []PreMigration
 }
 
 type UpgradeSchedule []Upgrade
@@ -70,43 +87,53 @@ func defaultUpgradeSchedule(cf *ChainFork, upgradeHeight *config.ForkUpgradeConf
 	updates := []Upgrade{{
 		Height:    upgradeHeight.UpgradeBreezeHeight,
 		Network:   network.Version1,
+		Name:      "breeze",
 		Migration: cf.UpgradeFaucetBurnRecovery,
 	}, {
 		Height:    upgradeHeight.UpgradeSmokeHeight,
 		Network:   network.Version2,
+		Name:      "smoke",
 		Migration: nil,
 	}, {
 		Height:    upgradeHeight.UpgradeIgnitionHeight,
 		Network:   network.Version3,
+		Name:      "ignition",
 		Migration: cf.UpgradeIgnition,
 	}, {
 		Height:    upgradeHeight.UpgradeRefuelHeight,
 		Network:   network.Version3,
+		Name:      "refuel",
 		Migration: cf.UpgradeRefuel,
 	}, {
 		Height:    upgradeHeight.UpgradeActorsV2Height,
 		Network:   network.Version4,
+		Name:      "actorsv2",
 		Expensive: true,
 		Migration: cf.UpgradeActorsV2,
 	}, {
 		Height:    upgradeHeight.UpgradeTapeHeight,
 		Network:   network.Version5,
+		Name:      "tape",
 		Migration: nil,
 	}, {
 		Height:    upgradeHeight.UpgradeLiftoffHeight,
 		Network:   network.Version5,
+		Name:      "liftoff",
 		Migration: cf.UpgradeLiftoff,
 	}, {
 		Height:    upgradeHeight.UpgradeKumquatHeight,
 		Network:   network.Version6,
+		Name:      "kumquat",
 		Migration: nil,
 	}, {
 		Height:    upgradeHeight.UpgradeCalicoHeight,
 		Network:   network.Version7,
+		Name:      "calico",
 		Migration: cf.UpgradeCalico,
 	}, {
 		Height:    upgradeHeight.UpgradePersianHeight,
 		Network:   network.Version8,
+		Name:      "persian",
 		Migration: nil,
 	}}
 
@@ -114,22 +141,27 @@ func defaultUpgradeSchedule(cf *ChainFork, upgradeHeight *config.ForkUpgradeConf
 		updates = []Upgrade{{
 			Height:    upgradeHeight.UpgradeBreezeHeight,
 			Network:   network.Version1,
+			Name:      "breeze",
 			Migration: cf.UpgradeFaucetBurnRecovery,
 		}, {
 			Height:    upgradeHeight.UpgradeSmokeHeight,
 			Network:   network.Version2,
+			Name:      "smoke",
 			Migration: nil,
 		}, {
 			Height:    upgradeHeight.UpgradeIgnitionHeight,
 			Network:   network.Version3,
+			Name:      "ignition",
 			Migration: cf.UpgradeIgnition,
 		}, {
 			Height:    upgradeHeight.UpgradeRefuelHeight,
 			Network:   network.Version3,
+			Name:      "refuel",
 			Migration: cf.UpgradeRefuel,
 		}, {
 			Height:    upgradeHeight.UpgradeLiftoffHeight,
 			Network:   network.Version3,
+			Name:      "liftoff",
 			Migration: cf.UpgradeLiftoff,
 		}}
 	}
@@ -168,6 +200,36 @@ func (us UpgradeSchedule) Validate() error {
 			return xerrors.Errorf("upgrade heights must be strictly increasing: upgrade %d was at height %d, followed by upgrade %d at height %d", i-1, prev.Height, i, curr.Height)
 		}
 	}
+
+	// Make sure a height runs at most one migration, and the pre-migration windows make sense.
+	migrationHeights := make(map[abi.ChainEpoch]struct{}, len(us))
+	for i, u := range us {
+		if u.Migration != nil {
+			if _, ok := migrationHeights[u.Height]; ok {
+				return xerrors.Errorf("upgrade %d at height %d: more than one migration at the same height", i, u.Height)
+			}
+			migrationHeights[u.Height] = struct{}{}
+		} else if u.PreMigrate != nil || len(u.PreMigrations) > 0 {
+			return xerrors.Errorf("upgrade %d at height %d: pre-migrations without a migration", i, u.Height)
+		}
+		if u.PreMigrate == nil && len(u.PreMigrations) > 0 {
+			return xerrors.Errorf("upgrade %d at height %d: the migration has no pre-migration", i, u.Height)
+		}
+		for _, m := range u.PreMigrations {
+			if m.StartWithin <= 0 {
+				return xerrors.Errorf("upgrade %d: pre-migration must start within a positive number of epochs, was %d", i, m.StartWithin)
+			}
+			if m.DontStartWithin < 0 || m.StopWithin < 0 {
+				return xerrors.Errorf("upgrade %d: pre-migration epochs must not be negative", i)
+			}
+			if m.StartWithin <= m.StopWithin {
+				return xerrors.Errorf("upgrade %d: pre-migration must start before it stops, start within %d, stop within %d", i, m.StartWithin, m.StopWithin)
+			}
+			if m.DontStartWithin != 0 && (m.DontStartWithin < m.StopWithin || m.StartWithin <= m.DontStartWithin) {
+				return xerrors.Errorf("upgrade %d: pre-migration dont start within %d must be between stop within %d and start within %d", i, m.DontStartWithin, m.StopWithin, m.StartWithin)
+			}
+		}
+	}
 	return nil
 }
 
@@ -181,6 +243,8 @@ type chainReader interface {
 type IFork interface {
 	HandleStateForks(ctx context.Context, root cid.Cid, height abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error)
 	GetNtwkVersion(ctx context.Context, height abi.ChainEpoch) network.Version
+	GetUpgradeSchedule() UpgradeSchedule
+	PreMigrate(ctx context.Context, head *block.TipSet)
 }
 
 var _ = IFork((*ChainFork)(nil))
@@ -190,12 +254,15 @@ type ChainFork struct {
 	bs        blockstore.Blockstore
 	ipldstore cbor.IpldStore
 
+	// The upgrades of the network, ordered by height.
+	upgrades UpgradeSchedule
+
 	// Determines the network version at any given epoch.
 	networkVersions []versionSpec
 	latestVersion   network.Version
 
 	// Maps chain epochs to upgrade functions.
-	stateMigrations map[abi.ChainEpoch]UpgradeFunc
+	stateMigrations map[abi.ChainEpoch]*migration
 	// A set of potentially expensive/time consuming upgrades. Explicit
 	// calls for, e.g., gas estimation fail against this epoch with
 	// ErrExpensiveFork.
@@ -221,12 +288,18 @@ func NewChainFork(cr chainReader, ipldstore cbor.IpldStore, bs blockstore.Blocks
 
 	// If we have upgrades, make sure they're in-order and make sense.
 	us := defaultUpgradeSchedule(fork, forkUpgrade)
+	if len(forkUpgrade.UpgradeSchedule) > 0 {
+		var err error
+		if us, err = configUpgradeSchedule(forkUpgrade.UpgradeSchedule); err != nil {
+			return nil, err
+		}
+	}
 	if err := us.Validate(); err != nil {
-		return nil, err
+		return nil, xerrors.Wrap(err, "invalid upgrade schedule")
 	}
 	log.Infof("UpgradeSchedule: %v", us)
 
-	stateMigrations := make(map[abi.ChainEpoch]UpgradeFunc, len(us))
+	stateMigrations := make(map[abi.ChainEpoch]*migration, len(us))
 	expensiveUpgrades := make(map[abi.ChainEpoch]struct{}, len(us))
 	var networkVersions []versionSpec
 	lastVersion := network.Version0
@@ -234,7 +307,7 @@ func NewChainFork(cr chainReader, ipldstore cbor.IpldStore, bs blockstore.Blocks
 		// If we have any upgrades, process them and create a version schedule.
 		for _, upgrade := range us {
 			if upgrade.Migration != nil {
-				stateMigrations[upgrade.Height] = upgrade.Migration
+				stateMigrations[upgrade.Height] = newMigration(upgrade)
 			}
 			if upgrade.Expensive {
 				expensiveUpgrades[upgrade.Height] = struct{}{}
@@ -250,6 +323,7 @@ func NewChainFork(cr chainReader, ipldstore cbor.IpldStore, bs blockstore.Blocks
 		lastVersion = constants.NewestNetworkVersion
 	}
 
+	fork.upgrades = us
 	fork.networkVersions = networkVersions
 	fork.latestVersion = lastVersion
 	fork.stateMigrations = stateMigrations
@@ -265,12 +339,17 @@ func (c *ChainFork) StateTree(ctx context.Context, st cid.Cid) (*vmstate.State,
 func (c *ChainFork) HandleStateForks(ctx context.Context, root cid.Cid, height abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
 	retCid := root
 	var err error
-	migration, ok := c.stateMigrations[height]
+	m, ok := c.stateMigrations[height]
 	if ok {
-		retCid, err = migration(ctx, c, root, height, ts)
+		// The pre-migration is too late to help, and competes with the migration for resources.
+		m.stopPreMigration()
+
+		start := time.Now()
+		retCid, err = m.upgrade(ctx, c, m.cache, root, height, ts)
 		if err != nil {
 			return cid.Undef, err
 		}
+		recordMigration(ctx, m.name, time.Since(start))
 	}
 
 	return retCid, nil
@@ -292,6 +371,11 @@ func (c *ChainFork) GetNtwkVersion(ctx context.Context, height abi.ChainEpoch) n
 	return c.latestVersion
 }
 
+// GetUpgradeSchedule returns the enabled upgrades of the network, ordered by height.
+func (c *ChainFork) GetUpgradeSchedule() UpgradeSchedule {
+	return c.upgrades
+}
+
 func doTransfer(tree vmstate.Tree, from, to address.Address, amt abi.TokenAmount) error {
 	fromAct, found, err := tree.GetActor(context.TODO(), from)
 	if err != nil {
@@ -340,7 +424,7 @@ func (c *ChainFork) ParentState(ts *block.TipSet) cid.Cid {
 	return cid.Undef
 }
 
-func (c *ChainFork) UpgradeFaucetBurnRecovery(ctx context.Context, sm *ChainFork, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+func (c *ChainFork) UpgradeFaucetBurnRecovery(ctx context.Context, sm *ChainFork, cache MigrationCache, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
 	// Some initial parameters
 	FundsForMiners := types.FromFil(1_000_000)
 	LookbackEpoch := abi.ChainEpoch(32000)
@@ -846,7 +930,7 @@ func resetMultisigVesting0(ctx context.Context, store adt0.Store, tree *vmstate.
 	return nil
 }
 
-func (c *ChainFork) UpgradeIgnition(ctx context.Context, sm *ChainFork, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+func (c *ChainFork) UpgradeIgnition(ctx context.Context, sm *ChainFork, cache MigrationCache, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
 	store := adt.WrapStore(ctx, sm.ipldstore)
 
 	if c.forkUpgrade.UpgradeLiftoffHeight <= epoch {
@@ -901,7 +985,7 @@ func (c *ChainFork) UpgradeIgnition(ctx context.Context, sm *ChainFork, root cid
 	return tree.Flush(ctx)
 }
 
-func (c *ChainFork) UpgradeRefuel(ctx context.Context, sm *ChainFork, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+func (c *ChainFork) UpgradeRefuel(ctx context.Context, sm *ChainFork, cache MigrationCache, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
 	store := adt.WrapStore(ctx, sm.ipldstore)
 	tree, err := sm.StateTree(ctx, root)
 	if err != nil {
@@ -1074,7 +1158,10 @@ func Copy(ctx context.Context, from, to blockstore.Blockstore, root cid.Cid) err
 	return nil
 }
 
-func (c *ChainFork) UpgradeActorsV2(ctx context.Context, sm *ChainFork, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+// UpgradeActorsV2 has no pre-migration: nv4 migrates each actor with priorEpoch and its balance,
+// and does not expose the per actor migrations, so nothing computed on a lookback state can be
+// reused at the upgrade epoch.
+func (c *ChainFork) UpgradeActorsV2(ctx context.Context, sm *ChainFork, cache MigrationCache, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
 	buf := bufbstore.NewTieredBstore(sm.bs, bstore.NewTemporarySync())
 	store := ActorStore(ctx, buf)
 
@@ -1120,7 +1207,7 @@ func (c *ChainFork) UpgradeActorsV2(ctx context.Context, sm *ChainFork, root cid
 	return newRoot, nil
 }
 
-func (c *ChainFork) UpgradeLiftoff(ctx context.Context, sm *ChainFork, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+func (c *ChainFork) UpgradeLiftoff(ctx context.Context, sm *ChainFork, cache MigrationCache, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
 	tree, err := sm.StateTree(ctx, root)
 	if err != nil {
 		return cid.Undef, xerrors.Errorf("getting state tree: %v", err)
@@ -1134,7 +1221,7 @@ func (c *ChainFork) UpgradeLiftoff(ctx context.Context, sm *ChainFork, root cid.
 	return tree.Flush(ctx)
 }
 
-func (c *ChainFork) UpgradeCalico(ctx context.Context, sm *ChainFork, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+func (c *ChainFork) UpgradeCalico(ctx context.Context, sm *ChainFork, cache MigrationCache, root cid.Cid, epoch abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
 	store := adt.WrapStore(ctx, sm.ipldstore)
 	var stateRoot vmstate.StateRoot
 	if err := store.Get(ctx, root, &stateRoot); err != nil {
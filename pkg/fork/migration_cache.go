@@ -0,0 +1,77 @@
+package fork
+
+import (
+	"sync"
+
+	"github.com/ipfs/go-cid"
+)
+
+// MigrationCache holds the intermediate results of a state migration, computed by its
+// pre-migrations on a lookback state and reused by the migration at the upgrade epoch.
+type MigrationCache interface {
+	Write(key string, value cid.Cid) error
+	Read(key string) (bool, cid.Cid, error)
+	// Load returns the value cached for key, or computes it with loadFunc and caches it.
+	Load(key string, loadFunc func() (cid.Cid, error)) (cid.Cid, error)
+}
+
+// MemMigrationCache is an in memory MigrationCache.
+type MemMigrationCache struct {
+	mapLk sync.RWMutex
+	m     map[string]cid.Cid
+}
+
+var _ MigrationCache = (*MemMigrationCache)(nil)
+
+// NewMemMigrationCache creates an empty MemMigrationCache.
+func NewMemMigrationCache() *MemMigrationCache {
+	return &MemMigrationCache{m: make(map[string]cid.Cid)}
+}
+
+func (c *MemMigrationCache) Write(key string, value cid.Cid) error {
+	c.mapLk.Lock()
+	defer c.mapLk.Unlock()
+	c.m[key] = value
+	return nil
+}
+
+func (c *MemMigrationCache) Read(key string) (bool, cid.Cid, error) {
+	c.mapLk.RLock()
+	defer c.mapLk.RUnlock()
+	value, ok := c.m[key]
+	return ok, value, nil
+}
+
+func (c *MemMigrationCache) Load(key string, loadFunc func() (cid.Cid, error)) (cid.Cid, error) {
+	if found, value, _ := c.Read(key); found {
+		return value, nil
+	}
+	value, err := loadFunc()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return value, c.Write(key, value)
+}
+
+// Clone returns a copy of the cache, for a pre-migration to write to without leaving partial
+// results in the cache when it is canceled.
+func (c *MemMigrationCache) Clone() *MemMigrationCache {
+	c.mapLk.RLock()
+	defer c.mapLk.RUnlock()
+	clone := NewMemMigrationCache()
+	for k, v := range c.m {
+		clone.m[k] = v
+	}
+	return clone
+}
+
+// Update adds the entries of other to the cache.
+func (c *MemMigrationCache) Update(other *MemMigrationCache) {
+	other.mapLk.RLock()
+	defer other.mapLk.RUnlock()
+	c.mapLk.Lock()
+	defer c.mapLk.Unlock()
+	for k, v := range other.m {
+		c.m[k] = v
+	}
+}
@@ -0,0 +1,44 @@
+package fork
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestMemMigrationCache(t *testing.T) {
+	tf.UnitTest(t)
+
+	cache := NewMemMigrationCache()
+	calls := 0
+	load := func() (cid.Cid, error) {
+		calls++
+		return types.EmptyMessagesCID, nil
+	}
+
+	value, err := cache.Load("key", load)
+	require.NoError(t, err)
+	assert.Equal(t, types.EmptyMessagesCID, value)
+	value, err = cache.Load("key", load)
+	require.NoError(t, err)
+	assert.Equal(t, types.EmptyMessagesCID, value)
+	assert.Equal(t, 1, calls)
+
+	// Writes to a clone are only seen by the cache once merged back.
+	clone := cache.Clone()
+	require.NoError(t, clone.Write("other", types.EmptyReceiptsCID))
+	found, _, err := cache.Read("other")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	cache.Update(clone)
+	found, value, err = cache.Read("other")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, types.EmptyReceiptsCID, value)
+}
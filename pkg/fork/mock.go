@@ -25,3 +25,10 @@ func (mockFork *MockFork) HandleStateForks(ctx context.Context, root cid.Cid, he
 func (mockFork *MockFork) GetNtwkVersion(ctx context.Context, height abi.ChainEpoch) network.Version {
 	return network.Version0
 }
+
+func (mockFork *MockFork) GetUpgradeSchedule() UpgradeSchedule {
+	return nil
+}
+
+func (mockFork *MockFork) PreMigrate(ctx context.Context, head *block.TipSet) {
+}
@@ -0,0 +1,208 @@
+package fork
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/venus/pkg/block"
+)
+
+const (
+	preMigrationDone     = "done"
+	preMigrationFailed   = "failed"
+	preMigrationCanceled = "canceled"
+)
+
+var (
+	upgradeKey = tag.MustNewKey("upgrade")
+	resultKey  = tag.MustNewKey("result")
+
+	migrationDuration    = stats.Float64("fork/migration_duration", "Duration of state migrations at upgrade epochs in milliseconds", stats.UnitMilliseconds)
+	preMigrationDuration = stats.Float64("fork/pre_migration_duration", "Duration of background pre-migrations in milliseconds, by result", stats.UnitMilliseconds)
+)
+
+func init() {
+	// [>=0, >=1s, >=10s, >=30s, >=1m, >=5m, >=10m, >=30m, >=1h]
+	bounds := []float64{1000, 10000, 30000, 60000, 300000, 600000, 1800000, 3600000}
+	if err := view.Register(&view.View{
+		Name:        migrationDuration.Name(),
+		Measure:     migrationDuration,
+		Description: migrationDuration.Description(),
+		TagKeys:     []tag.Key{upgradeKey},
+		Aggregation: view.Distribution(bounds...),
+	}, &view.View{
+		Name:        preMigrationDuration.Name(),
+		Measure:     preMigrationDuration,
+		Description: preMigrationDuration.Description(),
+		TagKeys:     []tag.Key{upgradeKey, resultKey},
+		Aggregation: view.Distribution(bounds...),
+	}); err != nil {
+		panic(err)
+	}
+}
+
+func recordMigration(ctx context.Context, name string, d time.Duration) {
+	ctx, err := tag.New(ctx, tag.Upsert(upgradeKey, name))
+	if err != nil {
+		log.Warnf("failed to tag migration metrics: %s", err)
+	}
+	stats.Record(ctx, migrationDuration.M(float64(d)/float64(time.Millisecond)))
+}
+
+func recordPreMigration(ctx context.Context, name, result string, d time.Duration) {
+	ctx, err := tag.New(ctx, tag.Upsert(upgradeKey, name), tag.Upsert(resultKey, result))
+	if err != nil {
+		log.Warnf("failed to tag pre-migration metrics: %s", err)
+	}
+	stats.Record(ctx, preMigrationDuration.M(float64(d)/float64(time.Millisecond)))
+}
+
+// migration is the state migration of an upgrade, with its pre-migrations and their results.
+type migration struct {
+	name          string
+	upgrade       UpgradeFunc
+	preMigrate    PreMigrationFunc
+	preMigrations []PreMigration
+	cache         *MemMigrationCache
+
+	lk sync.Mutex
+	// started flags the pre-migration windows already run, or running.
+	started []bool
+	running *preMigrationRun
+}
+
+// preMigrationRun is a pre-migration running on the parent state of the tipset ts.
+type preMigrationRun struct {
+	window int
+	ts     *block.TipSet
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newMigration(upgrade Upgrade) *migration {
+	return &migration{
+		name:          upgrade.Name,
+		upgrade:       upgrade.Migration,
+		preMigrate:    upgrade.PreMigrate,
+		preMigrations: upgrade.PreMigrations,
+		cache:         NewMemMigrationCache(),
+		started:       make([]bool, len(upgrade.PreMigrations)),
+	}
+}
+
+// PreMigrate starts, in the background, the pre-migrations of the upcoming upgrades whose window
+// the new head is in, and cancels those running on a tipset reverted by a reorg or whose window
+// ended. It is called on every head change.
+func (c *ChainFork) PreMigrate(ctx context.Context, head *block.TipSet) {
+	for height, m := range c.stateMigrations {
+		if m.preMigrate != nil {
+			m.onHead(ctx, c, height, head)
+		}
+	}
+}
+
+func (m *migration) onHead(ctx context.Context, c *ChainFork, height abi.ChainEpoch, head *block.TipSet) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	left := height - head.EnsureHeight()
+	if r := m.running; r != nil {
+		switch {
+		case left <= m.preMigrations[r.window].StopWithin:
+			log.Infof("stopping pre-migration for upgrade %s, %d epochs before the upgrade", m.name, left)
+			r.cancel()
+			m.running = nil
+		case !c.onChain(ctx, r.ts, head):
+			// The state the pre-migration runs on was reverted, run it again on the new chain.
+			log.Infof("canceling pre-migration for upgrade %s on reverted tipset %s", m.name, r.ts.Key())
+			r.cancel()
+			m.running = nil
+			m.started[r.window] = false
+		}
+	}
+	if m.running != nil || left <= 0 {
+		return
+	}
+
+	for i, pm := range m.preMigrations {
+		if m.started[i] || left > pm.StartWithin || left <= pm.StopWithin {
+			continue
+		}
+		if pm.DontStartWithin != 0 && left <= pm.DontStartWithin {
+			continue
+		}
+		m.started[i] = true
+		m.start(ctx, c, height, i, head)
+		return
+	}
+}
+
+// start runs the pre-migration of window on the parent state of ts. Its results are added to the
+// cache of the migration only when it completes.
+func (m *migration) start(ctx context.Context, c *ChainFork, height abi.ChainEpoch, window int, ts *block.TipSet) {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &preMigrationRun{window: window, ts: ts, cancel: cancel, done: make(chan struct{})}
+	m.running = r
+
+	log.Infof("starting pre-migration for upgrade %s at height %d on tipset %s", m.name, height, ts.Key())
+	go func() {
+		defer close(r.done)
+		defer cancel()
+
+		cache := m.cache.Clone()
+		start := time.Now()
+		err := m.preMigrate(ctx, c, cache, c.ParentState(ts), height, ts)
+		duration := time.Since(start)
+
+		result := preMigrationDone
+		switch {
+		case ctx.Err() != nil:
+			result = preMigrationCanceled
+		case err != nil:
+			result = preMigrationFailed
+			log.Warnf("pre-migration for upgrade %s failed after %s: %s", m.name, duration, err)
+		default:
+			log.Infof("pre-migration for upgrade %s done in %s", m.name, duration)
+			m.cache.Update(cache)
+		}
+		recordPreMigration(ctx, m.name, result, duration)
+
+		m.lk.Lock()
+		if m.running == r {
+			m.running = nil
+		}
+		m.lk.Unlock()
+	}()
+}
+
+// stopPreMigration cancels the running pre-migration and waits for it to return.
+func (m *migration) stopPreMigration() {
+	m.lk.Lock()
+	r := m.running
+	m.running = nil
+	m.lk.Unlock()
+
+	if r != nil {
+		r.cancel()
+		<-r.done
+	}
+}
+
+// onChain returns whether ts is head or one of its ancestors.
+func (c *ChainFork) onChain(ctx context.Context, ts, head *block.TipSet) bool {
+	if ts.EnsureHeight() > head.EnsureHeight() {
+		return false
+	}
+	anc, err := c.cr.GetTipSetByHeight(ctx, head, ts.EnsureHeight(), false)
+	if err != nil {
+		log.Warnf("failed to load the tipset at %d from %s: %s", ts.EnsureHeight(), head.Key(), err)
+		return false
+	}
+	return anc.Key().Equals(ts.Key())
+}
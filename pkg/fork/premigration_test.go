@@ -0,0 +1,274 @@
+package fork
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/enccid"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// testMigrationName is a migration for the upgrade schedules of the tests.
+const testMigrationName = "test"
+
+func init() {
+	RegisterMigration(testMigrationName, func(ctx context.Context, sm *ChainFork, cache MigrationCache, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+		return oldState, nil
+	})
+}
+
+// fakePreMigration writes a result for the tipset it runs on to the cache, then waits to be
+// released or canceled.
+type fakePreMigration struct {
+	started chan *block.TipSet
+	release chan error
+}
+
+func newFakePreMigration() *fakePreMigration {
+	return &fakePreMigration{started: make(chan *block.TipSet, 10), release: make(chan error, 1)}
+}
+
+func (f *fakePreMigration) preMigrate(ctx context.Context, sm *ChainFork, cache MigrationCache, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) error {
+	if err := cache.Write(ts.Key().String(), oldState); err != nil {
+		return err
+	}
+	f.started <- ts
+	select {
+	case err := <-f.release:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *fakePreMigration) requireStarted(t *testing.T, ts *block.TipSet) {
+	select {
+	case started := <-f.started:
+		assert.Equal(t, ts.Key(), started.Key())
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pre-migration did not start on %s", ts.Key())
+	}
+}
+
+// fakeChain is a chain reader over the tipsets it built.
+type fakeChain struct {
+	chainReader
+	tipsets map[string]*block.TipSet
+}
+
+func (fc *fakeChain) newTipSet(t *testing.T, parent *block.TipSet, height abi.ChainEpoch, fork byte) *block.TipSet {
+	var parents block.TipSetKey
+	if parent != nil {
+		parents = parent.Key()
+	}
+	ts, err := block.NewTipSet(&block.Block{
+		Ticket:          block.Ticket{VRFProof: crypto.VRFPi{fork, byte(height)}},
+		Parents:         parents,
+		ParentWeight:    big.Zero(),
+		Height:          height,
+		ParentStateRoot: enccid.NewCid(types.EmptyMessagesCID),
+		ParentBaseFee:   abi.NewTokenAmount(0),
+	})
+	require.NoError(t, err)
+	fc.tipsets[ts.Key().String()] = ts
+	return ts
+}
+
+// extend appends tipsets on top of ts up to height.
+func (fc *fakeChain) extend(t *testing.T, ts *block.TipSet, height abi.ChainEpoch, fork byte) *block.TipSet {
+	for h := ts.EnsureHeight() + 1; h <= height; h++ {
+		ts = fc.newTipSet(t, ts, h, fork)
+	}
+	return ts
+}
+
+func (fc *fakeChain) GetTipSetByHeight(ctx context.Context, ts *block.TipSet, h abi.ChainEpoch, prev bool) (*block.TipSet, error) {
+	for ts.EnsureHeight() > h {
+		parents, err := ts.Parents()
+		if err != nil {
+			return nil, err
+		}
+		parent, ok := fc.tipsets[parents.String()]
+		if !ok {
+			return nil, errors.Errorf("unknown tipset %s", parents)
+		}
+		ts = parent
+	}
+	return ts, nil
+}
+
+const testUpgradeHeight = abi.ChainEpoch(100)
+
+func setupPreMigration(t *testing.T) (*ChainFork, *migration, *fakeChain, *fakePreMigration) {
+	fc := &fakeChain{tipsets: make(map[string]*block.TipSet)}
+	f := newFakePreMigration()
+	m := newMigration(Upgrade{
+		Height:     testUpgradeHeight,
+		Name:       testMigrationName,
+		Migration:  migrations[testMigrationName],
+		PreMigrate: f.preMigrate,
+		PreMigrations: []PreMigration{{
+			StartWithin:     20,
+			DontStartWithin: 10,
+			StopWithin:      5,
+		}},
+	})
+	c := &ChainFork{cr: fc, stateMigrations: map[abi.ChainEpoch]*migration{testUpgradeHeight: m}}
+	return c, m, fc, f
+}
+
+// runningPreMigration returns the running pre-migration.
+func (m *migration) runningPreMigration() *preMigrationRun {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	return m.running
+}
+
+// waitPreMigration waits for the pre-migration to return and its results to be merged.
+func waitPreMigration(t *testing.T, r *preMigrationRun) {
+	require.NotNil(t, r)
+	select {
+	case <-r.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pre-migration did not return")
+	}
+}
+
+func requireCached(t *testing.T, m *migration, ts *block.TipSet, cached bool) {
+	found, _, err := m.cache.Read(ts.Key().String())
+	require.NoError(t, err)
+	assert.Equal(t, cached, found, "result of the pre-migration on %s", ts.Key())
+}
+
+func TestPreMigration(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	t.Run("starts within StartWithin epochs of the upgrade and merges its result on success", func(t *testing.T) {
+		c, m, fc, f := setupPreMigration(t)
+		ts79 := fc.extend(t, fc.newTipSet(t, nil, 70, 0), 79, 0)
+
+		c.PreMigrate(ctx, ts79)
+		assert.Nil(t, m.runningPreMigration())
+
+		ts80 := fc.extend(t, ts79, 80, 0)
+		c.PreMigrate(ctx, ts80)
+		f.requireStarted(t, ts80)
+
+		// The pre-migration keeps running as the chain grows.
+		ts81 := fc.extend(t, ts80, 81, 0)
+		c.PreMigrate(ctx, ts81)
+		requireCached(t, m, ts80, false)
+
+		r := m.runningPreMigration()
+		f.release <- nil
+		waitPreMigration(t, r)
+		requireCached(t, m, ts80, true)
+
+		// A window runs once.
+		c.PreMigrate(ctx, fc.extend(t, ts81, 82, 0))
+		assert.Nil(t, m.runningPreMigration())
+	})
+
+	t.Run("does not start within DontStartWithin epochs of the upgrade", func(t *testing.T) {
+		c, m, fc, _ := setupPreMigration(t)
+		head := fc.extend(t, fc.newTipSet(t, nil, 80, 0), 90, 0)
+
+		c.PreMigrate(ctx, head)
+		assert.Nil(t, m.runningPreMigration())
+	})
+
+	t.Run("stops within StopWithin epochs of the upgrade without merging its result", func(t *testing.T) {
+		c, m, fc, f := setupPreMigration(t)
+		ts85 := fc.extend(t, fc.newTipSet(t, nil, 80, 0), 85, 0)
+
+		c.PreMigrate(ctx, ts85)
+		f.requireStarted(t, ts85)
+		r := m.runningPreMigration()
+
+		ts95 := fc.extend(t, ts85, 95, 0)
+		c.PreMigrate(ctx, ts95)
+		assert.Nil(t, m.runningPreMigration())
+		waitPreMigration(t, r)
+		requireCached(t, m, ts85, false)
+	})
+
+	t.Run("does not merge the result of a failed pre-migration", func(t *testing.T) {
+		c, m, fc, f := setupPreMigration(t)
+		ts85 := fc.extend(t, fc.newTipSet(t, nil, 80, 0), 85, 0)
+
+		c.PreMigrate(ctx, ts85)
+		f.requireStarted(t, ts85)
+		r := m.runningPreMigration()
+		f.release <- errors.New("pre-migration failed")
+		waitPreMigration(t, r)
+		requireCached(t, m, ts85, false)
+	})
+
+	t.Run("restarts on the new chain when its tipset is reverted", func(t *testing.T) {
+		c, m, fc, f := setupPreMigration(t)
+		ts84 := fc.extend(t, fc.newTipSet(t, nil, 80, 0), 84, 0)
+		ts85 := fc.extend(t, ts84, 85, 0)
+
+		c.PreMigrate(ctx, ts85)
+		f.requireStarted(t, ts85)
+		r := m.runningPreMigration()
+
+		// A head extending the chain keeps the pre-migration running.
+		c.PreMigrate(ctx, fc.extend(t, ts85, 86, 0))
+		assert.Equal(t, r, m.runningPreMigration())
+
+		// A fork reverting its tipset restarts it on the new head.
+		forked := fc.extend(t, ts84, 86, 1)
+		c.PreMigrate(ctx, forked)
+		waitPreMigration(t, r)
+		f.requireStarted(t, forked)
+		restarted := m.runningPreMigration()
+		assert.NotEqual(t, r, restarted)
+
+		f.release <- nil
+		waitPreMigration(t, restarted)
+		requireCached(t, m, ts85, false)
+		requireCached(t, m, forked, true)
+	})
+
+	t.Run("the migration stops the running pre-migration and reads the merged results", func(t *testing.T) {
+		c, m, fc, f := setupPreMigration(t)
+		ts81 := fc.extend(t, fc.newTipSet(t, nil, 80, 0), 81, 0)
+		require.NoError(t, m.cache.Write("merged", types.EmptyMessagesCID))
+		c.PreMigrate(ctx, ts81)
+		f.requireStarted(t, ts81)
+		r := m.runningPreMigration()
+
+		var cache MigrationCache
+		m.upgrade = func(ctx context.Context, sm *ChainFork, mc MigrationCache, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+			cache = mc
+			return oldState, nil
+		}
+		root, err := c.HandleStateForks(ctx, types.EmptyReceiptsCID, testUpgradeHeight, fc.extend(t, ts81, 91, 0))
+		require.NoError(t, err)
+		assert.Equal(t, types.EmptyReceiptsCID, root)
+
+		// The pre-migration returned before the migration ran, without merging its result.
+		waitPreMigration(t, r)
+		assert.Nil(t, m.runningPreMigration())
+		require.NotNil(t, cache)
+		found, _, err := cache.Read("merged")
+		require.NoError(t, err)
+		assert.True(t, found)
+		found, _, err = cache.Read(ts81.Key().String())
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
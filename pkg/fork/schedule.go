@@ -0,0 +1,80 @@
+package fork
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/config"
+)
+
+// PreMigration is the window of epochs before an upgrade in which its migration runs in the
+// background.
+type PreMigration struct {
+	// StartWithin is the number of epochs before the upgrade from which the pre-migration starts.
+	StartWithin abi.ChainEpoch
+	// DontStartWithin is the number of epochs before the upgrade below which the pre-migration
+	// is not started, zero to always start.
+	DontStartWithin abi.ChainEpoch
+	// StopWithin is the number of epochs before the upgrade at which a running pre-migration
+	// is stopped.
+	StopWithin abi.ChainEpoch
+}
+
+// migrations are the state migrations an upgrade schedule in config refers to by name.
+var migrations = map[string]UpgradeFunc{}
+
+func init() {
+	for name, m := range map[string]func(*ChainFork, context.Context, *ChainFork, MigrationCache, cid.Cid, abi.ChainEpoch, *block.TipSet) (cid.Cid, error){
+		"breeze":   (*ChainFork).UpgradeFaucetBurnRecovery,
+		"ignition": (*ChainFork).UpgradeIgnition,
+		"refuel":   (*ChainFork).UpgradeRefuel,
+		"actorsv2": (*ChainFork).UpgradeActorsV2,
+		"liftoff":  (*ChainFork).UpgradeLiftoff,
+		"calico":   (*ChainFork).UpgradeCalico,
+	} {
+		m := m
+		RegisterMigration(name, func(ctx context.Context, sm *ChainFork, cache MigrationCache, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) (cid.Cid, error) {
+			return m(sm, ctx, sm, cache, oldState, height, ts)
+		})
+	}
+}
+
+// RegisterMigration makes a state migration available to upgrade schedules in config under name.
+// It must be called before the chain is loaded, typically from an init function.
+func RegisterMigration(name string, migration UpgradeFunc) {
+	if _, ok := migrations[name]; ok {
+		panic("duplicate migration " + name)
+	}
+	migrations[name] = migration
+}
+
+// configUpgradeSchedule builds the upgrade schedule declared in config, skipping the disabled
+// upgrades. Their migrations run at the upgrade epoch, config declares no pre-migration.
+func configUpgradeSchedule(upgrades []*config.UpgradeConfig) (UpgradeSchedule, error) {
+	var us UpgradeSchedule
+	for _, u := range upgrades {
+		if u.Height < 0 {
+			// upgrade disabled
+			continue
+		}
+		upgrade := Upgrade{
+			Height:    u.Height,
+			Network:   u.Network,
+			Name:      u.Name,
+			Expensive: u.Expensive,
+		}
+		if u.Migration != "" {
+			migration, ok := migrations[u.Migration]
+			if !ok {
+				return nil, xerrors.Errorf("upgrade %s at height %d: unknown migration %s", u.Name, u.Height, u.Migration)
+			}
+			upgrade.Migration = migration
+		}
+		us = append(us, upgrade)
+	}
+	return us, nil
+}
@@ -0,0 +1,96 @@
+package fork
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/block"
+	"github.com/filecoin-project/venus/pkg/config"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestConfigUpgradeSchedule(t *testing.T) {
+	tf.UnitTest(t)
+
+	us, err := configUpgradeSchedule([]*config.UpgradeConfig{
+		{Height: -1, Network: network.Version1, Name: "disabled", Migration: "breeze"},
+		{Height: 10, Network: network.Version2, Name: "smoke"},
+		{Height: 20, Network: network.Version4, Name: "actorsv2", Migration: "actorsv2", Expensive: true},
+		{Height: 30, Network: network.Version5, Name: "test", Migration: testMigrationName},
+	})
+	require.NoError(t, err)
+	require.NoError(t, us.Validate())
+
+	require.Len(t, us, 3)
+	assert.Equal(t, "smoke", us[0].Name)
+	assert.Nil(t, us[0].Migration)
+	assert.NotNil(t, us[1].Migration)
+	assert.Nil(t, us[1].PreMigrate)
+	assert.True(t, us[1].Expensive)
+	assert.NotNil(t, us[2].Migration)
+
+	// The upgrades declared in config have no pre-migration, their migrations run at the upgrade
+	// epoch and the head changes start nothing ahead of them.
+	for _, u := range us {
+		assert.Nil(t, u.PreMigrate, u.Name)
+		assert.Empty(t, u.PreMigrations, u.Name)
+	}
+	c := &ChainFork{stateMigrations: map[abi.ChainEpoch]*migration{}}
+	for _, u := range us {
+		if u.Migration != nil {
+			c.stateMigrations[u.Height] = newMigration(u)
+		}
+	}
+	fc := &fakeChain{tipsets: make(map[string]*block.TipSet)}
+	c.cr = fc
+	c.PreMigrate(context.Background(), fc.newTipSet(t, nil, 25, 0))
+	for _, m := range c.stateMigrations {
+		assert.Nil(t, m.runningPreMigration())
+	}
+
+	_, err = configUpgradeSchedule([]*config.UpgradeConfig{{Height: 10, Network: network.Version2, Migration: "unknown"}})
+	assert.Error(t, err)
+}
+
+func TestValidateUpgradeSchedule(t *testing.T) {
+	tf.UnitTest(t)
+
+	migration := migrations[testMigrationName]
+	preMigrate := func(ctx context.Context, sm *ChainFork, cache MigrationCache, oldState cid.Cid, height abi.ChainEpoch, ts *block.TipSet) error {
+		return nil
+	}
+	for name, us := range map[string]UpgradeSchedule{
+		"downgrade": {
+			{Height: 10, Network: network.Version3},
+			{Height: 20, Network: network.Version2},
+		},
+		"decreasing heights": {
+			{Height: 20, Network: network.Version2},
+			{Height: 10, Network: network.Version3},
+		},
+		"two migrations at a height": {
+			{Height: 10, Network: network.Version3, Migration: migration},
+			{Height: 10, Network: network.Version3, Migration: migration},
+		},
+		"pre-migration without migration": {
+			{Height: 10, Network: network.Version3, PreMigrate: preMigrate, PreMigrations: []PreMigration{{StartWithin: 5}}},
+		},
+		"pre-migration windows without pre-migration": {
+			{Height: 10, Network: network.Version3, Migration: migration, PreMigrations: []PreMigration{{StartWithin: 5}}},
+		},
+		"pre-migration stops before it starts": {
+			{Height: 10, Network: network.Version3, Migration: migration, PreMigrate: preMigrate, PreMigrations: []PreMigration{{StartWithin: 5, StopWithin: 5}}},
+		},
+		"pre-migration dont start within after start": {
+			{Height: 10, Network: network.Version3, Migration: migration, PreMigrate: preMigrate, PreMigrations: []PreMigration{{StartWithin: 5, DontStartWithin: 6, StopWithin: 1}}},
+		},
+	} {
+		assert.Error(t, us.Validate(), name)
+	}
+}
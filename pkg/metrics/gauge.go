@@ -43,3 +43,37 @@ func NewInt64Gauge(name, desc string, keys ...tag.Key) *Int64Gauge {
 func (c *Int64Gauge) Set(ctx context.Context, v int64) {
 	stats.Record(ctx, c.measureCt.M(v))
 }
+
+// Float64Gauge wraps an opencensus float64 measure that is uses as a gauge.
+type Float64Gauge struct {
+	measureCt *stats.Float64Measure
+	view      *view.View
+}
+
+// NewFloat64Gauge creates a new Float64Gauge with demensionless units.
+func NewFloat64Gauge(name, desc string, keys ...tag.Key) *Float64Gauge {
+	log.Infof("registering float64 gauge: %s - %s", name, desc)
+	fMeasure := stats.Float64(name, desc, stats.UnitDimensionless)
+
+	fView := &view.View{
+		Name:        name,
+		Measure:     fMeasure,
+		Description: desc,
+		Aggregation: view.LastValue(),
+		TagKeys:     keys,
+	}
+	if err := view.Register(fView); err != nil {
+		// a panic here indicates a developer error when creating a view.
+		panic(err)
+	}
+
+	return &Float64Gauge{
+		measureCt: fMeasure,
+		view:      fView,
+	}
+}
+
+// Set sets the value of the gauge to value `v`.
+func (c *Float64Gauge) Set(ctx context.Context, v float64) {
+	stats.Record(ctx, c.measureCt.M(v))
+}
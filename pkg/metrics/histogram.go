@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+// Int64Histogram wraps an opencensus int64 measure whose values are aggregated into buckets.
+type Int64Histogram struct {
+	measure *stats.Int64Measure
+	view    *view.View
+}
+
+// NewInt64Histogram creates a new Int64Histogram with demensionless units and the given
+// bucket bounds.
+func NewInt64Histogram(name, desc string, bounds []float64) *Int64Histogram {
+	log.Infof("registering int64 histogram: %s - %s", name, desc)
+	iMeasure := stats.Int64(name, desc, stats.UnitDimensionless)
+	iView := &view.View{
+		Name:        name,
+		Measure:     iMeasure,
+		Description: desc,
+		Aggregation: view.Distribution(bounds...),
+	}
+	if err := view.Register(iView); err != nil {
+		// a panic here indicates a developer error when creating a view.
+		// Since this method is called in init() methods, this panic when hit
+		// will cause running the program to fail immediately.
+		panic(err)
+	}
+
+	return &Int64Histogram{
+		measure: iMeasure,
+		view:    iView,
+	}
+}
+
+// Record records the value `v` in the histogram.
+func (h *Int64Histogram) Record(ctx context.Context, v int64) {
+	stats.Record(ctx, h.measure.M(v))
+}
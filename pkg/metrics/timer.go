@@ -72,3 +72,8 @@ func (sw *Stopwatch) Stop(ctx context.Context) time.Duration {
 	stats.Record(ctx, sw.recorder(float64(duration)/1e6))
 	return duration
 }
+
+// Record records a duration measured by the caller in the corresponding opencensus view.
+func (t *Float64Timer) Record(ctx context.Context, d time.Duration) {
+	stats.Record(ctx, t.measureMs.M(float64(d.Round(time.Millisecond))/1e6))
+}
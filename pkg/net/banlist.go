@@ -0,0 +1,101 @@
+package net
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// BanEntry is a banned peer.
+type BanEntry struct {
+	Peer   peer.ID
+	Reason string
+	Until  time.Time
+}
+
+// BanList holds the peers the node refuses to talk to until their ban expires.
+type BanList struct {
+	lk    sync.Mutex
+	bans  map[peer.ID]BanEntry
+	onBan []func(peer.ID)
+}
+
+// NewBanList creates an empty BanList.
+func NewBanList() *BanList {
+	return &BanList{
+		bans: make(map[peer.ID]BanEntry),
+	}
+}
+
+// OnBan registers a function called with every banned peer, used to close the
+// connections to the peer.
+func (bl *BanList) OnBan(f func(peer.ID)) {
+	bl.lk.Lock()
+	defer bl.lk.Unlock()
+	bl.onBan = append(bl.onBan, f)
+}
+
+// Ban bans the peer for the duration, extending a current ban.
+func (bl *BanList) Ban(p peer.ID, d time.Duration, reason string) {
+	bl.lk.Lock()
+	until := time.Now().Add(d)
+	if cur, ok := bl.bans[p]; ok && cur.Until.After(until) {
+		until = cur.Until
+	}
+	bl.bans[p] = BanEntry{Peer: p, Reason: reason, Until: until}
+	onBan := bl.onBan
+	bl.lk.Unlock()
+
+	log.Infof("banned peer %s until %s: %s", p, until.Format(time.RFC3339), reason)
+	for _, f := range onBan {
+		f(p)
+	}
+}
+
+// Unban lifts the ban of the peer and returns whether it was banned.
+func (bl *BanList) Unban(p peer.ID) bool {
+	bl.lk.Lock()
+	defer bl.lk.Unlock()
+
+	_, ok := bl.bans[p]
+	delete(bl.bans, p)
+	return ok
+}
+
+// IsBanned returns whether the peer is banned.
+func (bl *BanList) IsBanned(p peer.ID) bool {
+	bl.lk.Lock()
+	defer bl.lk.Unlock()
+
+	entry, ok := bl.bans[p]
+	if !ok {
+		return false
+	}
+	if time.Now().After(entry.Until) {
+		delete(bl.bans, p)
+		return false
+	}
+	return true
+}
+
+// List returns the banned peers, the ban expiring first first.
+func (bl *BanList) List() []BanEntry {
+	bl.lk.Lock()
+	defer bl.lk.Unlock()
+
+	now := time.Now()
+	out := make([]BanEntry, 0, len(bl.bans))
+	for p, entry := range bl.bans {
+		if now.After(entry.Until) {
+			delete(bl.bans, p)
+			continue
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Until.Before(out[j].Until)
+	})
+	return out
+}
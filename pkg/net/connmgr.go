@@ -0,0 +1,413 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	net "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// DefaultConnMgrLow is the number of connections kept when trimming.
+	DefaultConnMgrLow = 150
+	// DefaultConnMgrHigh is the number of connections above which they are trimmed.
+	DefaultConnMgrHigh = 180
+	// DefaultConnMgrGrace is how long a new connection can't be trimmed.
+	DefaultConnMgrGrace = 20 * time.Second
+	// DefaultConnMgrMaxPerPeer is the number of connections kept to a single peer.
+	DefaultConnMgrMaxPerPeer = 4
+
+	// trimInterval is the period of the background trimming.
+	trimInterval = time.Minute
+	// idleTagsTTL is how long the tags of a peer without connections are kept.
+	idleTagsTTL = 10 * time.Minute
+)
+
+// Tags set by the node on the peers useful to the chain.
+const (
+	// ProtectedTag protects the peers set in the swarm config and by the operator.
+	ProtectedTag = "config-protected"
+	// NewBlockTag is set on the peers which sent us a block the syncer validated.
+	NewBlockTag = "new-block"
+	// NewBlockTagValue is the value of NewBlockTag.
+	NewBlockTagValue = 20
+)
+
+type connPeerInfo struct {
+	firstSeen time.Time
+	value     int
+	tags      map[string]int
+	// conns are the open connections of the peer, with their opening time.
+	conns map[net.Conn]time.Time
+	// idleSince is when the peer was last left without connections, its tags
+	// are dropped after idleTagsTTL.
+	idleSince time.Time
+}
+
+func newConnPeerInfo(now time.Time) *connPeerInfo {
+	return &connPeerInfo{
+		firstSeen: now,
+		tags:      make(map[string]int),
+		conns:     make(map[net.Conn]time.Time),
+		idleSince: now,
+	}
+}
+
+// ConnManager keeps the number of connections between a low and a high
+// watermark. When there are more than high connections, the connections of the
+// least valuable peers are closed until low are left. Peers are valued by the
+// sum of their tags, protected peers and connections younger than the grace
+// period are never closed. The newest connections of a peer with more than
+// maxPerPeer connections are closed too, zero disables the limit. The tags of a
+// peer are kept while it reconnects, and dropped once it has been without
+// connections for a while.
+type ConnManager struct {
+	low, high  int
+	grace      time.Duration
+	maxPerPeer int
+
+	lk        sync.Mutex
+	peers     map[peer.ID]*connPeerInfo
+	protected map[peer.ID]map[string]struct{}
+	connCount int
+
+	trimLk   sync.Mutex
+	trigger  chan struct{}
+	closing  chan struct{}
+	closeOne sync.Once
+}
+
+var _ connmgr.ConnManager = (*ConnManager)(nil)
+
+// NewConnManager creates a ConnManager and starts its background trimming.
+func NewConnManager(low, high int, grace time.Duration, maxPerPeer int) *ConnManager {
+	cm := &ConnManager{
+		low:        low,
+		high:       high,
+		grace:      grace,
+		maxPerPeer: maxPerPeer,
+		peers:      make(map[peer.ID]*connPeerInfo),
+		protected:  make(map[peer.ID]map[string]struct{}),
+		trigger:    make(chan struct{}, 1),
+		closing:    make(chan struct{}),
+	}
+	go cm.background()
+	return cm
+}
+
+func (cm *ConnManager) background() {
+	ticker := time.NewTicker(trimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-cm.trigger:
+		case <-cm.closing:
+			return
+		}
+		cm.TrimOpenConns(context.Background())
+		cm.dropIdleTags(time.Now())
+	}
+}
+
+// dropIdleTags forgets the peers left without connections for idleTagsTTL.
+func (cm *ConnManager) dropIdleTags(now time.Time) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	for p, info := range cm.peers {
+		if len(info.conns) == 0 && info.idleSince.Add(idleTagsTTL).Before(now) {
+			delete(cm.peers, p)
+		}
+	}
+}
+
+// Close stops the background trimming.
+func (cm *ConnManager) Close() error {
+	cm.closeOne.Do(func() {
+		close(cm.closing)
+	})
+	return nil
+}
+
+// TrimOpenConns closes the connections over the per peer limit, then the
+// connections of the least valuable peers when there are more than the high
+// watermark, until the low watermark is reached.
+func (cm *ConnManager) TrimOpenConns(ctx context.Context) {
+	cm.trimLk.Lock()
+	defer cm.trimLk.Unlock()
+
+	for _, c := range cm.connsToClose() {
+		log.Infof("closing connection to %s, over the connection limits", c.RemotePeer())
+		if err := c.Close(); err != nil {
+			log.Warnf("failed to close connection to %s: %s", c.RemotePeer(), err)
+		}
+	}
+}
+
+func (cm *ConnManager) connsToClose() []net.Conn {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	now := time.Now()
+	closing := make(map[net.Conn]struct{})
+	var out []net.Conn
+	candidates := make([]*connPeerInfo, 0, len(cm.peers))
+	for p, info := range cm.peers {
+		if _, ok := cm.protected[p]; ok || len(info.conns) == 0 {
+			continue
+		}
+		candidates = append(candidates, info)
+
+		if cm.maxPerPeer > 0 && len(info.conns) > cm.maxPerPeer {
+			for _, c := range newestConns(info)[:len(info.conns)-cm.maxPerPeer] {
+				closing[c] = struct{}{}
+				out = append(out, c)
+			}
+		}
+	}
+
+	if cm.connCount-len(out) <= cm.high {
+		return out
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].value < candidates[j].value
+	})
+
+	target := cm.connCount - len(out) - cm.low
+	for _, info := range candidates {
+		if target <= 0 {
+			break
+		}
+		for c, opened := range info.conns {
+			if _, ok := closing[c]; ok || opened.Add(cm.grace).After(now) {
+				continue
+			}
+			out = append(out, c)
+			target--
+		}
+	}
+	return out
+}
+
+// newestConns returns the connections of the peer, newest first.
+func newestConns(info *connPeerInfo) []net.Conn {
+	out := make([]net.Conn, 0, len(info.conns))
+	for c := range info.conns {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return info.conns[out[i]].After(info.conns[out[j]])
+	})
+	return out
+}
+
+// TagPeer sets the value of a tag of the peer.
+func (cm *ConnManager) TagPeer(p peer.ID, tag string, val int) {
+	cm.UpsertTag(p, tag, func(int) int { return val })
+}
+
+// UntagPeer removes a tag of the peer.
+func (cm *ConnManager) UntagPeer(p peer.ID, tag string) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	info, ok := cm.peers[p]
+	if !ok {
+		return
+	}
+	info.value -= info.tags[tag]
+	delete(info.tags, tag)
+}
+
+// UpsertTag updates the value of a tag of the peer, the tag is created with a
+// value of zero passed to upsert.
+func (cm *ConnManager) UpsertTag(p peer.ID, tag string, upsert func(int) int) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	info, ok := cm.peers[p]
+	if !ok {
+		// The tags of a peer without connections are kept for idleTagsTTL,
+		// in case it connects.
+		info = newConnPeerInfo(time.Now())
+		cm.peers[p] = info
+	}
+	old := info.tags[tag]
+	info.tags[tag] = upsert(old)
+	info.value += info.tags[tag] - old
+}
+
+// GetTagInfo returns the tags and connections of the peer, nil if unknown.
+func (cm *ConnManager) GetTagInfo(p peer.ID) *connmgr.TagInfo {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	info, ok := cm.peers[p]
+	if !ok {
+		return nil
+	}
+	out := &connmgr.TagInfo{
+		FirstSeen: info.firstSeen,
+		Value:     info.value,
+		Tags:      make(map[string]int, len(info.tags)),
+		Conns:     make(map[string]time.Time, len(info.conns)),
+	}
+	for t, v := range info.tags {
+		out.Tags[t] = v
+	}
+	for c, at := range info.conns {
+		out.Conns[c.RemoteMultiaddr().String()] = at
+	}
+	return out
+}
+
+// Protect prevents the connections of the peer from being trimmed until all
+// its protection tags are removed.
+func (cm *ConnManager) Protect(p peer.ID, tag string) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	tags, ok := cm.protected[p]
+	if !ok {
+		tags = make(map[string]struct{})
+		cm.protected[p] = tags
+	}
+	tags[tag] = struct{}{}
+}
+
+// Unprotect removes a protection tag of the peer and returns whether the
+// peer is still protected by other tags.
+func (cm *ConnManager) Unprotect(p peer.ID, tag string) bool {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	tags, ok := cm.protected[p]
+	if !ok {
+		return false
+	}
+	delete(tags, tag)
+	if len(tags) == 0 {
+		delete(cm.protected, p)
+		return false
+	}
+	return true
+}
+
+// IsProtected returns whether the peer is protected by the tag, or by any tag
+// when tag is empty.
+func (cm *ConnManager) IsProtected(p peer.ID, tag string) bool {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	tags, ok := cm.protected[p]
+	if !ok {
+		return false
+	}
+	if tag == "" {
+		return true
+	}
+	_, ok = tags[tag]
+	return ok
+}
+
+// Protected returns the protected peers.
+func (cm *ConnManager) Protected() []peer.ID {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	out := make([]peer.ID, 0, len(cm.protected))
+	for p := range cm.protected {
+		out = append(out, p)
+	}
+	return out
+}
+
+// Notifee returns the network notifiee tracking the connections.
+func (cm *ConnManager) Notifee() net.Notifiee {
+	return (*cmNotifee)(cm)
+}
+
+type cmNotifee ConnManager
+
+func (nn *cmNotifee) cm() *ConnManager {
+	return (*ConnManager)(nn)
+}
+
+func (nn *cmNotifee) Connected(n net.Network, c net.Conn) {
+	cm := nn.cm()
+	now := time.Now()
+	cm.lk.Lock()
+	info, ok := cm.peers[c.RemotePeer()]
+	if !ok {
+		info = newConnPeerInfo(now)
+		cm.peers[c.RemotePeer()] = info
+	}
+	if _, ok := info.conns[c]; !ok {
+		info.conns[c] = now
+		cm.connCount++
+	}
+	over := cm.connCount > cm.high || (cm.maxPerPeer > 0 && len(info.conns) > cm.maxPerPeer)
+	cm.lk.Unlock()
+
+	if over {
+		select {
+		case cm.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (nn *cmNotifee) Disconnected(n net.Network, c net.Conn) {
+	cm := nn.cm()
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	info, ok := cm.peers[c.RemotePeer()]
+	if !ok {
+		return
+	}
+	if _, ok := info.conns[c]; !ok {
+		return
+	}
+	delete(info.conns, c)
+	cm.connCount--
+	if len(info.conns) == 0 {
+		// The tags are kept for a while, in case the peer reconnects.
+		info.idleSince = time.Now()
+	}
+}
+
+func (nn *cmNotifee) Listen(n net.Network, addr ma.Multiaddr)      {}
+func (nn *cmNotifee) ListenClose(n net.Network, addr ma.Multiaddr) {}
+func (nn *cmNotifee) OpenedStream(net.Network, net.Stream)         {}
+func (nn *cmNotifee) ClosedStream(net.Network, net.Stream)         {}
+
+// ParsePeerIDs parses peer ids given either as ids or as multiaddrs ending with
+// the peer id.
+func ParsePeerIDs(addrs []string) ([]peer.ID, error) {
+	out := make([]peer.ID, 0, len(addrs))
+	for _, addr := range addrs {
+		if id, err := peer.Decode(addr); err == nil {
+			out = append(out, id)
+			continue
+		}
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer %s: %s", addr, err)
+		}
+		info, err := peer.AddrInfoFromP2pAddr(maddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer %s: %s", addr, err)
+		}
+		out = append(out, info.ID)
+	}
+	return out, nil
+}
@@ -0,0 +1,175 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	net "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestConnManagerProtect(t *testing.T) {
+	tf.UnitTest(t)
+
+	cm := NewConnManager(1, 2, time.Second, 0)
+	defer cm.Close() // nolint: errcheck
+
+	p, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+
+	cm.Protect(p, ProtectedTag)
+	cm.Protect(p, "other")
+	assert.True(t, cm.IsProtected(p, ""))
+	assert.True(t, cm.IsProtected(p, ProtectedTag))
+	assert.Equal(t, []peer.ID{p}, cm.Protected())
+
+	assert.True(t, cm.Unprotect(p, ProtectedTag))
+	assert.False(t, cm.IsProtected(p, ProtectedTag))
+	assert.False(t, cm.Unprotect(p, "other"))
+	assert.False(t, cm.IsProtected(p, ""))
+	assert.Empty(t, cm.Protected())
+}
+
+func TestConnManagerTags(t *testing.T) {
+	tf.UnitTest(t)
+
+	cm := NewConnManager(1, 2, time.Second, 0)
+	defer cm.Close() // nolint: errcheck
+
+	p, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+	assert.Nil(t, cm.GetTagInfo(p))
+
+	cm.TagPeer(p, NewBlockTag, NewBlockTagValue)
+	cm.UpsertTag(p, "bsync", func(v int) int { return v + 5 })
+	cm.UpsertTag(p, "bsync", func(v int) int { return v + 5 })
+
+	info := cm.GetTagInfo(p)
+	require.NotNil(t, info)
+	assert.Equal(t, NewBlockTagValue+10, info.Value)
+	assert.Equal(t, 10, info.Tags["bsync"])
+
+	cm.UntagPeer(p, NewBlockTag)
+	assert.Equal(t, 10, cm.GetTagInfo(p).Value)
+}
+
+// testConn is a connection to a peer, only its peer and address are set.
+type testConn struct {
+	net.Conn
+	remote peer.ID
+}
+
+func (c *testConn) RemotePeer() peer.ID {
+	return c.remote
+}
+
+func (c *testConn) RemoteMultiaddr() ma.Multiaddr {
+	return ma.StringCast("/ip4/127.0.0.1/tcp/4001")
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+// newTestConnManager creates a ConnManager without background trimming.
+func newTestConnManager(low, high int, grace time.Duration) *ConnManager {
+	return &ConnManager{
+		low:       low,
+		high:      high,
+		grace:     grace,
+		peers:     make(map[peer.ID]*connPeerInfo),
+		protected: make(map[peer.ID]map[string]struct{}),
+		trigger:   make(chan struct{}, 1),
+		closing:   make(chan struct{}),
+	}
+}
+
+func TestConnManagerKeepsTagsOnReconnect(t *testing.T) {
+	tf.UnitTest(t)
+
+	cm := newTestConnManager(1, 2, time.Second)
+	p, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+
+	c1 := &testConn{remote: p}
+	cm.Notifee().Connected(nil, c1)
+	cm.TagPeer(p, NewBlockTag, NewBlockTagValue)
+	cm.Protect(p, ProtectedTag)
+	cm.Notifee().Disconnected(nil, c1)
+
+	info := cm.GetTagInfo(p)
+	require.NotNil(t, info)
+	assert.Equal(t, NewBlockTagValue, info.Value)
+	assert.Empty(t, info.Conns)
+	assert.True(t, cm.IsProtected(p, ProtectedTag))
+
+	c2 := &testConn{remote: p}
+	cm.Notifee().Connected(nil, c2)
+	info = cm.GetTagInfo(p)
+	require.NotNil(t, info)
+	assert.Equal(t, NewBlockTagValue, info.Value)
+	assert.Len(t, info.Conns, 1)
+}
+
+func TestConnManagerDropsIdleTags(t *testing.T) {
+	tf.UnitTest(t)
+
+	cm := newTestConnManager(1, 2, time.Second)
+	connected, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+	idle, err := peer.Decode("QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ")
+	require.NoError(t, err)
+
+	cm.Notifee().Connected(nil, &testConn{remote: connected})
+	cm.TagPeer(connected, NewBlockTag, NewBlockTagValue)
+	cm.TagPeer(idle, NewBlockTag, NewBlockTagValue)
+
+	cm.dropIdleTags(time.Now())
+	assert.NotNil(t, cm.GetTagInfo(idle))
+
+	cm.dropIdleTags(time.Now().Add(idleTagsTTL + time.Second))
+	assert.Nil(t, cm.GetTagInfo(idle))
+	assert.NotNil(t, cm.GetTagInfo(connected))
+}
+
+func TestConnManagerGraceFromConnectionOpen(t *testing.T) {
+	tf.UnitTest(t)
+
+	cm := newTestConnManager(0, 0, time.Minute)
+	p, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+
+	// The peer was tagged long before it connected, its new connection is
+	// still in its grace period.
+	cm.TagPeer(p, NewBlockTag, NewBlockTagValue)
+	cm.peers[p].firstSeen = time.Now().Add(-time.Hour)
+	c := &testConn{remote: p}
+	cm.Notifee().Connected(nil, c)
+	assert.Empty(t, cm.connsToClose())
+
+	cm.peers[p].conns[c] = time.Now().Add(-2 * time.Minute)
+	assert.Equal(t, []net.Conn{c}, cm.connsToClose())
+
+	cm.Protect(p, ProtectedTag)
+	assert.Empty(t, cm.connsToClose())
+}
+
+func TestParsePeerIDs(t *testing.T) {
+	tf.UnitTest(t)
+
+	ids, err := ParsePeerIDs([]string{
+		"QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt",
+		"/ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt", ids[0].Pretty())
+	assert.Equal(t, "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ", ids[1].Pretty())
+
+	_, err = ParsePeerIDs([]string{"not a peer"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package net
+
+import (
+	"fmt"
+	gonet "net"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	"github.com/libp2p/go-libp2p-core/control"
+	net "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net" //nolint
+)
+
+// GaterRules are the peers and networks the node is allowed to talk to.
+type GaterRules struct {
+	// allowedPeers is empty when all peers are allowed.
+	allowedPeers map[peer.ID]struct{}
+	// allowedNets is empty when all networks are allowed.
+	allowedNets []*gonet.IPNet
+	deniedNets  []*gonet.IPNet
+}
+
+// NewGaterRules parses the allowed peer ids or p2p multiaddrs, and the
+// allowed and denied CIDRs. Empty allow lists allow everything, a denied
+// network wins over an allowed one.
+func NewGaterRules(allowedPeers, allowedCIDRs, deniedCIDRs []string) (*GaterRules, error) {
+	peers, err := ParsePeerIDs(allowedPeers)
+	if err != nil {
+		return nil, err
+	}
+	rules := &GaterRules{
+		allowedPeers: make(map[peer.ID]struct{}, len(peers)),
+	}
+	for _, p := range peers {
+		rules.allowedPeers[p] = struct{}{}
+	}
+	if rules.allowedNets, err = parseCIDRs(allowedCIDRs); err != nil {
+		return nil, err
+	}
+	if rules.deniedNets, err = parseCIDRs(deniedCIDRs); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func parseCIDRs(cidrs []string) ([]*gonet.IPNet, error) {
+	out := make([]*gonet.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipnet, err := gonet.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %s", cidr, err)
+		}
+		out = append(out, ipnet)
+	}
+	return out, nil
+}
+
+func (r *GaterRules) allowsPeer(p peer.ID) bool {
+	if len(r.allowedPeers) == 0 {
+		return true
+	}
+	_, ok := r.allowedPeers[p]
+	return ok
+}
+
+// allowsAddr filters the addresses with an IP, other addresses, like relay
+// addresses, are only filtered by peer.
+func (r *GaterRules) allowsAddr(addr ma.Multiaddr) bool {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return true
+	}
+	for _, ipnet := range r.deniedNets {
+		if ipnet.Contains(ip) {
+			return false
+		}
+	}
+	if len(r.allowedNets) == 0 {
+		return true
+	}
+	for _, ipnet := range r.allowedNets {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnGater refuses the connections to banned peers and to the peers and
+// addresses not allowed by its rules. The rules can be replaced at runtime.
+type ConnGater struct {
+	lk    sync.RWMutex
+	rules *GaterRules
+
+	bans *BanList
+}
+
+var _ connmgr.ConnectionGater = (*ConnGater)(nil)
+
+// NewConnGater creates a ConnGater with the rules, also refusing the peers in bans.
+func NewConnGater(rules *GaterRules, bans *BanList) *ConnGater {
+	return &ConnGater{rules: rules, bans: bans}
+}
+
+// SetRules replaces the rules, current connections are not closed.
+func (cg *ConnGater) SetRules(rules *GaterRules) {
+	cg.lk.Lock()
+	defer cg.lk.Unlock()
+	cg.rules = rules
+}
+
+func (cg *ConnGater) getRules() *GaterRules {
+	cg.lk.RLock()
+	defer cg.lk.RUnlock()
+	return cg.rules
+}
+
+// AllowsPeer returns whether the node can talk to the peer.
+func (cg *ConnGater) AllowsPeer(p peer.ID) bool {
+	return !cg.bans.IsBanned(p) && cg.getRules().allowsPeer(p)
+}
+
+// AllowsConn returns whether the connection is allowed by the current rules.
+func (cg *ConnGater) AllowsConn(c net.Conn) bool {
+	return cg.AllowsPeer(c.RemotePeer()) && cg.getRules().allowsAddr(c.RemoteMultiaddr())
+}
+
+// InterceptPeerDial implements connmgr.ConnectionGater.
+func (cg *ConnGater) InterceptPeerDial(p peer.ID) bool {
+	return cg.AllowsPeer(p)
+}
+
+// InterceptAddrDial implements connmgr.ConnectionGater.
+func (cg *ConnGater) InterceptAddrDial(p peer.ID, addr ma.Multiaddr) bool {
+	return cg.getRules().allowsAddr(addr)
+}
+
+// InterceptAccept implements connmgr.ConnectionGater.
+func (cg *ConnGater) InterceptAccept(addrs net.ConnMultiaddrs) bool {
+	return cg.getRules().allowsAddr(addrs.RemoteMultiaddr())
+}
+
+// InterceptSecured implements connmgr.ConnectionGater, the peer of an inbound
+// connection is only known once it is secured.
+func (cg *ConnGater) InterceptSecured(dir net.Direction, p peer.ID, addrs net.ConnMultiaddrs) bool {
+	if !cg.AllowsPeer(p) {
+		log.Debugf("refusing connection to peer %s", p)
+		return false
+	}
+	return true
+}
+
+// InterceptUpgraded implements connmgr.ConnectionGater.
+func (cg *ConnGater) InterceptUpgraded(net.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
@@ -0,0 +1,48 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestConnGater(t *testing.T) {
+	tf.UnitTest(t)
+
+	allowed, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+	other, err := peer.Decode("QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ")
+	require.NoError(t, err)
+
+	rules, err := NewGaterRules(nil, nil, nil)
+	require.NoError(t, err)
+	bans := NewBanList()
+	cg := NewConnGater(rules, bans)
+	assert.True(t, cg.InterceptPeerDial(other))
+	assert.True(t, cg.InterceptAddrDial(other, ma.StringCast("/ip4/8.8.8.8/tcp/1")))
+
+	bans.Ban(other, time.Hour, "test")
+	assert.False(t, cg.InterceptPeerDial(other))
+
+	rules, err = NewGaterRules([]string{allowed.Pretty()}, []string{"10.0.0.0/8"}, []string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	cg.SetRules(rules)
+	bans.Unban(other)
+
+	assert.True(t, cg.InterceptPeerDial(allowed))
+	assert.False(t, cg.InterceptPeerDial(other))
+	assert.True(t, cg.InterceptAddrDial(allowed, ma.StringCast("/ip4/10.2.0.1/tcp/1")))
+	assert.False(t, cg.InterceptAddrDial(allowed, ma.StringCast("/ip4/10.1.0.1/tcp/1")))
+	assert.False(t, cg.InterceptAddrDial(allowed, ma.StringCast("/ip4/8.8.8.8/tcp/1")))
+	// Addresses without an IP are only filtered by peer.
+	assert.True(t, cg.InterceptAddrDial(allowed, ma.StringCast("/dns4/example.com/tcp/1")))
+
+	_, err = NewGaterRules(nil, []string{"10.0.0.0"}, nil)
+	assert.Error(t, err)
+}
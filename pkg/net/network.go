@@ -21,6 +21,14 @@ type SwarmConnInfo struct {
 	Latency string
 	Muxer   string
 	Streams []SwarmStreamInfo
+
+	AgentVersion string
+	Protocols    []string `json:",omitempty"`
+	BytesIn      int64
+	BytesOut     int64
+	// Chain is the chain claimed by the peer in the hello protocol, nil until
+	// the peer said hello.
+	Chain *PeerChainInfo `json:",omitempty"`
 }
 
 // SwarmStreamInfo represents details about a single swarm stream.
@@ -62,6 +70,9 @@ type Network struct {
 	host host.Host
 	metrics.Reporter
 	*Router
+
+	chainInfos    ChainInfoSource
+	exchangeStats ExchangeStatsSource
 }
 
 // New returns a new Network
@@ -152,8 +163,14 @@ func (network *Network) Peers(ctx context.Context, verbose, latency, streams boo
 		addr := c.RemoteMultiaddr()
 
 		ci := SwarmConnInfo{
-			Addr: addr.String(),
-			Peer: pid.Pretty(),
+			Addr:         addr.String(),
+			Peer:         pid.Pretty(),
+			AgentVersion: network.agentVersion(pid),
+			Chain:        network.peerChainInfo(pid),
+		}
+		if network.Reporter != nil {
+			bw := network.Reporter.GetBandwidthForPeer(pid)
+			ci.BytesIn, ci.BytesOut = bw.TotalIn, bw.TotalOut
 		}
 
 		if verbose || latency {
@@ -164,6 +181,11 @@ func (network *Network) Peers(ctx context.Context, verbose, latency, streams boo
 				ci.Latency = lat.String()
 			}
 		}
+		if verbose {
+			if protos, err := network.host.Peerstore().GetProtocols(pid); err == nil {
+				ci.Protocols = protos
+			}
+		}
 		if verbose || streams {
 			strs := c.GetStreams()
 
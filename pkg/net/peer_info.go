@@ -0,0 +1,135 @@
+package net
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+)
+
+// PeerChainInfo is the chain a peer claimed in the hello protocol.
+type PeerChainInfo struct {
+	Head   []cid.Cid
+	Height abi.ChainEpoch
+	// GenesisMatch is whether the peer said hello with our genesis, the
+	// peers with another genesis are disconnected.
+	GenesisMatch bool
+}
+
+// PeerExchangeStats is the bookkeeping of the chain exchange requests sent to a peer.
+type PeerExchangeStats struct {
+	Successes        int
+	Failures         int
+	Invalid          int
+	AverageTime      time.Duration
+	Throughput       float64
+	BlacklistedUntil time.Time
+}
+
+// ChainInfoSource returns the chain claimed by a peer in the hello protocol.
+type ChainInfoSource func(peer.ID) (*PeerChainInfo, bool)
+
+// ExchangeStatsSource returns the chain exchange statistics of a peer.
+type ExchangeStatsSource func(peer.ID) (*PeerExchangeStats, bool)
+
+// PeerInfo is what the node knows about a peer.
+type PeerInfo struct {
+	ID peer.ID
+	// Addrs are the addresses of the peer in the peerstore.
+	Addrs []string
+	// Conns are the remote addresses of the open connections to the peer.
+	Conns           []string
+	AgentVersion    string
+	ProtocolVersion string
+	Protocols       []string
+	Latency         time.Duration
+	BytesIn         int64
+	BytesOut        int64
+	RateIn          float64
+	RateOut         float64
+
+	// Protected, Banned and GossipScore are set by the network submodule.
+	Protected   bool
+	Banned      bool
+	GossipScore float64
+
+	Chain    *PeerChainInfo     `json:",omitempty"`
+	Exchange *PeerExchangeStats `json:",omitempty"`
+}
+
+// SetChainInfoSource sets the source of the chains claimed by the peers.
+func (network *Network) SetChainInfoSource(source ChainInfoSource) {
+	network.chainInfos = source
+}
+
+// SetExchangeStatsSource sets the source of the chain exchange statistics of the peers.
+func (network *Network) SetExchangeStatsSource(source ExchangeStatsSource) {
+	network.exchangeStats = source
+}
+
+func (network *Network) peerChainInfo(p peer.ID) *PeerChainInfo {
+	if network.chainInfos == nil {
+		return nil
+	}
+	ci, ok := network.chainInfos(p)
+	if !ok {
+		return nil
+	}
+	return ci
+}
+
+func (network *Network) agentVersion(p peer.ID) string {
+	agent, err := network.host.Peerstore().Get(p, "AgentVersion")
+	if err != nil {
+		return ""
+	}
+	s, _ := agent.(string)
+	return s
+}
+
+// PeerInfo combines the peerstore, bandwidth, hello and chain exchange
+// information about a peer.
+func (network *Network) PeerInfo(p peer.ID) (*PeerInfo, error) {
+	if network.host == nil {
+		return nil, errors.New("node must be online")
+	}
+
+	ps := network.host.Peerstore()
+	conns := network.host.Network().ConnsToPeer(p)
+	addrs := ps.Addrs(p)
+	if len(conns) == 0 && len(addrs) == 0 {
+		return nil, errors.New("unknown peer")
+	}
+
+	out := &PeerInfo{
+		ID:           p,
+		AgentVersion: network.agentVersion(p),
+		Latency:      ps.LatencyEWMA(p),
+		Chain:        network.peerChainInfo(p),
+	}
+	for _, addr := range addrs {
+		out.Addrs = append(out.Addrs, addr.String())
+	}
+	for _, c := range conns {
+		out.Conns = append(out.Conns, c.RemoteMultiaddr().String())
+	}
+	if v, err := ps.Get(p, "ProtocolVersion"); err == nil {
+		out.ProtocolVersion, _ = v.(string)
+	}
+	if protos, err := ps.GetProtocols(p); err == nil {
+		out.Protocols = protos
+	}
+	if network.Reporter != nil {
+		bw := network.Reporter.GetBandwidthForPeer(p)
+		out.BytesIn, out.BytesOut = bw.TotalIn, bw.TotalOut
+		out.RateIn, out.RateOut = bw.RateIn, bw.RateOut
+	}
+	if network.exchangeStats != nil {
+		if stats, ok := network.exchangeStats(p); ok {
+			out.Exchange = stats
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestPeerInfo(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mn, err := mocknet.FullMeshConnected(ctx, 3)
+	require.NoError(t, err)
+	a, b, c := mn.Hosts()[0], mn.Hosts()[1], mn.Hosts()[2]
+
+	network := New(a, nil, metrics.NewBandwidthCounter())
+	network.SetChainInfoSource(func(p peer.ID) (*PeerChainInfo, bool) {
+		if p != b.ID() {
+			return nil, false
+		}
+		return &PeerChainInfo{Height: 10, GenesisMatch: true}, true
+	})
+	network.SetExchangeStatsSource(func(p peer.ID) (*PeerExchangeStats, bool) {
+		return &PeerExchangeStats{Successes: 2, AverageTime: time.Second}, p == b.ID()
+	})
+
+	info, err := network.PeerInfo(b.ID())
+	require.NoError(t, err)
+	assert.Equal(t, b.ID(), info.ID)
+	assert.Len(t, info.Conns, 1)
+	require.NotNil(t, info.Chain)
+	assert.Equal(t, 10, int(info.Chain.Height))
+	assert.True(t, info.Chain.GenesisMatch)
+	require.NotNil(t, info.Exchange)
+	assert.Equal(t, 2, info.Exchange.Successes)
+
+	// the peers which didn't say hello have no chain
+	info, err = network.PeerInfo(c.ID())
+	require.NoError(t, err)
+	assert.Nil(t, info.Chain)
+	assert.Nil(t, info.Exchange)
+
+	unknown, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+	_, err = network.PeerInfo(unknown)
+	assert.Error(t, err)
+
+	_, err = New(nil, nil, nil).PeerInfo(b.ID())
+	assert.Error(t, err)
+}
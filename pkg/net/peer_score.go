@@ -0,0 +1,229 @@
+package net
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+const (
+	// PenaltyHalfLife is the time after which half of the penalties of a peer
+	// are forgiven.
+	PenaltyHalfLife = 30 * time.Minute
+	// AutoBanPenalty is the decayed penalty from which a peer is banned.
+	AutoBanPenalty = 10
+	// AutoBanDuration is how long a peer is banned for too many penalties.
+	AutoBanDuration = time.Hour
+
+	// autoBanEpsilon tolerates the decay of the penalties between rejects in
+	// quick succession, AutoBanPenalty of them ban the peer.
+	autoBanEpsilon = 0.01
+
+	// penaltyWeight is the application specific score of one penalty.
+	penaltyWeight = -100
+	// bannedScore is the application specific score of a banned peer, enough
+	// to graylist it.
+	bannedScore = -100000
+
+	// ScoreInspectInterval is the period of the gossipsub scores snapshot.
+	ScoreInspectInterval = 10 * time.Second
+)
+
+// PeerScore is the reputation of a peer.
+type PeerScore struct {
+	ID peer.ID
+	// Score is the gossipsub score, including the application specific score.
+	Score float64
+	// Penalty is the decayed count of payloads rejected by the node.
+	Penalty float64
+	Banned  bool
+}
+
+type penalty struct {
+	value float64
+	at    time.Time
+}
+
+func (pn *penalty) decayed(now time.Time) float64 {
+	return pn.value * math.Pow(0.5, float64(now.Sub(pn.at))/float64(PenaltyHalfLife))
+}
+
+// ScoreKeeper keeps the gossipsub scores of the peers and the penalties of
+// the peers which sent payloads rejected by the topic validators. Peers
+// with too many penalties are banned.
+type ScoreKeeper struct {
+	lk        sync.Mutex
+	scores    map[peer.ID]float64
+	penalties map[peer.ID]*penalty
+
+	bans *BanList
+}
+
+// NewScoreKeeper creates a ScoreKeeper banning peers in bans.
+func NewScoreKeeper(bans *BanList) *ScoreKeeper {
+	return &ScoreKeeper{
+		scores:    make(map[peer.ID]float64),
+		penalties: make(map[peer.ID]*penalty),
+		bans:      bans,
+	}
+}
+
+// Update replaces the gossipsub scores, it is the pubsub score inspect function.
+func (sk *ScoreKeeper) Update(scores map[peer.ID]float64) {
+	sk.lk.Lock()
+	defer sk.lk.Unlock()
+	sk.scores = scores
+}
+
+// Penalize records a payload of the peer rejected for reason, the peer is
+// banned when its penalties reach AutoBanPenalty.
+func (sk *ScoreKeeper) Penalize(p peer.ID, reason string) {
+	sk.lk.Lock()
+	now := time.Now()
+	pn, ok := sk.penalties[p]
+	if !ok {
+		pn = &penalty{at: now}
+		sk.penalties[p] = pn
+	}
+	pn.value = pn.decayed(now) + 1
+	pn.at = now
+	ban := pn.value >= AutoBanPenalty-autoBanEpsilon
+	if ban {
+		delete(sk.penalties, p)
+	}
+	sk.lk.Unlock()
+
+	if ban {
+		sk.bans.Ban(p, AutoBanDuration, "too many rejected payloads, last on "+reason)
+	}
+}
+
+// AppSpecificScore is the application specific part of the gossipsub score.
+func (sk *ScoreKeeper) AppSpecificScore(p peer.ID) float64 {
+	if sk.bans.IsBanned(p) {
+		return bannedScore
+	}
+
+	sk.lk.Lock()
+	defer sk.lk.Unlock()
+	if pn, ok := sk.penalties[p]; ok {
+		return penaltyWeight * pn.decayed(time.Now())
+	}
+	return 0
+}
+
+// Validator wraps a topic validator to penalize the peers which sent
+// rejected payloads, payloads from banned peers are rejected.
+func (sk *ScoreKeeper) Validator(topic string, v pubsub.Validator) pubsub.Validator {
+	return func(ctx context.Context, p peer.ID, msg *pubsub.Message) bool {
+		if sk.bans.IsBanned(p) {
+			return false
+		}
+		if !v(ctx, p, msg) {
+			sk.Penalize(p, topic)
+			return false
+		}
+		return true
+	}
+}
+
+// Scores returns the scores of the peers known to gossipsub or penalized,
+// lowest first.
+func (sk *ScoreKeeper) Scores() []PeerScore {
+	sk.lk.Lock()
+	now := time.Now()
+	byPeer := make(map[peer.ID]*PeerScore, len(sk.scores))
+	for p, s := range sk.scores {
+		byPeer[p] = &PeerScore{ID: p, Score: s}
+	}
+	for p, pn := range sk.penalties {
+		ps, ok := byPeer[p]
+		if !ok {
+			ps = &PeerScore{ID: p}
+			byPeer[p] = ps
+		}
+		ps.Penalty = pn.decayed(now)
+	}
+	sk.lk.Unlock()
+
+	out := make([]PeerScore, 0, len(byPeer))
+	for p, ps := range byPeer {
+		ps.Banned = sk.bans.IsBanned(p)
+		out = append(out, *ps)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Score < out[j].Score
+	})
+	return out
+}
+
+// PeerScoreParams returns the gossipsub peer scoring parameters of the blocks
+// and messages topics.
+func PeerScoreParams(blocksTopic, msgsTopic string, appScore func(peer.ID) float64) *pubsub.PeerScoreParams {
+	return &pubsub.PeerScoreParams{
+		AppSpecificScore:  appScore,
+		AppSpecificWeight: 1,
+
+		// Penalize many peers behind the same IP, usually a sybil.
+		IPColocationFactorThreshold: 5,
+		IPColocationFactorWeight:    -100,
+
+		BehaviourPenaltyThreshold: 6,
+		BehaviourPenaltyWeight:    -10,
+		BehaviourPenaltyDecay:     pubsub.ScoreParameterDecay(time.Hour),
+
+		DecayInterval: pubsub.DefaultDecayInterval,
+		DecayToZero:   pubsub.DefaultDecayToZero,
+		RetainScore:   6 * time.Hour,
+
+		Topics: map[string]*pubsub.TopicScoreParams{
+			blocksTopic: {
+				TopicWeight: 0.1,
+
+				// 1 tick per second, caps at 1 after 1 hour in the mesh.
+				TimeInMeshWeight:  0.00027,
+				TimeInMeshQuantum: time.Second,
+				TimeInMeshCap:     1,
+
+				// Blocks are rare, reward the first deliveries a lot.
+				FirstMessageDeliveriesWeight: 5,
+				FirstMessageDeliveriesDecay:  pubsub.ScoreParameterDecay(time.Hour),
+				FirstMessageDeliveriesCap:    100,
+
+				// An invalid block is a strong sign of misbehaviour.
+				InvalidMessageDeliveriesWeight: -1000,
+				InvalidMessageDeliveriesDecay:  pubsub.ScoreParameterDecay(time.Hour),
+			},
+			msgsTopic: {
+				TopicWeight: 0.1,
+
+				TimeInMeshWeight:  0.0002778,
+				TimeInMeshQuantum: time.Second,
+				TimeInMeshCap:     1,
+
+				FirstMessageDeliveriesWeight: 0.5,
+				FirstMessageDeliveriesDecay:  pubsub.ScoreParameterDecay(10 * time.Minute),
+				FirstMessageDeliveriesCap:    100,
+
+				InvalidMessageDeliveriesWeight: -1000,
+				InvalidMessageDeliveriesDecay:  pubsub.ScoreParameterDecay(time.Hour),
+			},
+		},
+	}
+}
+
+// PeerScoreThresholds returns the gossipsub score thresholds.
+func PeerScoreThresholds() *pubsub.PeerScoreThresholds {
+	return &pubsub.PeerScoreThresholds{
+		GossipThreshold:             -500,
+		PublishThreshold:            -1000,
+		GraylistThreshold:           -2500,
+		AcceptPXThreshold:           1000,
+		OpportunisticGraftThreshold: 3.5,
+	}
+}
@@ -0,0 +1,65 @@
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestBanList(t *testing.T) {
+	tf.UnitTest(t)
+
+	p, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+
+	bans := NewBanList()
+	var banned []peer.ID
+	bans.OnBan(func(p peer.ID) { banned = append(banned, p) })
+
+	bans.Ban(p, time.Hour, "test")
+	assert.True(t, bans.IsBanned(p))
+	assert.Equal(t, []peer.ID{p}, banned)
+	require.Len(t, bans.List(), 1)
+	assert.Equal(t, "test", bans.List()[0].Reason)
+
+	assert.True(t, bans.Unban(p))
+	assert.False(t, bans.IsBanned(p))
+	assert.False(t, bans.Unban(p))
+
+	bans.Ban(p, -time.Second, "expired")
+	assert.False(t, bans.IsBanned(p))
+	assert.Empty(t, bans.List())
+}
+
+func TestScoreKeeperAutoBan(t *testing.T) {
+	tf.UnitTest(t)
+
+	p, err := peer.Decode("QmcZf59bWwK5XFi76CZX8cbJ4BhTzzA3gU1ZjYZcYW3dwt")
+	require.NoError(t, err)
+
+	bans := NewBanList()
+	sk := NewScoreKeeper(bans)
+	reject := sk.Validator("blocks", func(context.Context, peer.ID, *pubsub.Message) bool { return false })
+
+	for i := 0; i < AutoBanPenalty-1; i++ {
+		assert.False(t, reject(context.Background(), p, nil))
+	}
+	assert.False(t, bans.IsBanned(p))
+	assert.Less(t, sk.AppSpecificScore(p), float64(0))
+	require.Len(t, sk.Scores(), 1)
+	assert.InDelta(t, AutoBanPenalty-1, sk.Scores()[0].Penalty, 0.01)
+
+	assert.False(t, reject(context.Background(), p, nil))
+	assert.True(t, bans.IsBanned(p))
+	assert.Equal(t, float64(bannedScore), sk.AppSpecificScore(p))
+
+	accept := sk.Validator("blocks", func(context.Context, peer.ID, *pubsub.Message) bool { return true })
+	assert.False(t, accept(context.Background(), p, nil))
+}
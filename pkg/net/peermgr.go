@@ -28,6 +28,7 @@ type IPeerMgr interface {
 	GetPeerLatency(p peer.ID) (time.Duration, bool)
 	SetPeerLatency(p peer.ID, latency time.Duration)
 	Disconnect(p peer.ID)
+	IsBanned(p peer.ID) bool
 	Stop(ctx context.Context) error
 	Run(ctx context.Context)
 }
@@ -50,8 +51,9 @@ type PeerMgr struct {
 
 	expanding chan struct{}
 
-	h   host.Host
-	dht *dht.IpfsDHT
+	h    host.Host
+	dht  *dht.IpfsDHT
+	bans *BanList
 
 	notifee        *net.NotifyBundle
 	filPeerEmitter event.Emitter
@@ -64,10 +66,11 @@ type NewFilPeer struct {
 	Id peer.ID //nolint
 }
 
-func NewPeerMgr(h host.Host, dht *dht.IpfsDHT, period time.Duration, bootstrap []peer.AddrInfo) (*PeerMgr, error) {
+func NewPeerMgr(h host.Host, dht *dht.IpfsDHT, period time.Duration, bootstrap []peer.AddrInfo, bans *BanList) (*PeerMgr, error) {
 	pm := &PeerMgr{
 		h:             h,
 		dht:           dht,
+		bans:          bans,
 		bootstrappers: bootstrap,
 
 		peers:     make(map[peer.ID]time.Duration),
@@ -86,6 +89,12 @@ func NewPeerMgr(h host.Host, dht *dht.IpfsDHT, period time.Duration, bootstrap [
 	pm.filPeerEmitter = emitter
 
 	pm.notifee = &net.NotifyBundle{
+		ConnectedF: func(_ net.Network, c net.Conn) {
+			if pm.IsBanned(c.RemotePeer()) {
+				log.Debugf("closing connection to banned peer %s", c.RemotePeer())
+				_ = c.Close()
+			}
+		},
 		DisconnectedF: func(_ net.Network, c net.Conn) {
 			pm.Disconnect(c.RemotePeer())
 		},
@@ -93,10 +102,19 @@ func NewPeerMgr(h host.Host, dht *dht.IpfsDHT, period time.Duration, bootstrap [
 
 	h.Network().Notify(pm.notifee)
 
+	bans.OnBan(func(p peer.ID) {
+		if err := h.Network().ClosePeer(p); err != nil {
+			log.Warnf("failed to close connections to banned peer %s: %s", p, err)
+		}
+	})
+
 	return pm, nil
 }
 
 func (pmgr *PeerMgr) AddFilecoinPeer(p peer.ID) {
+	if pmgr.IsBanned(p) {
+		return
+	}
 	_ = pmgr.filPeerEmitter.Emit(NewFilPeer{Id: p}) //nolint:errcheck
 	pmgr.peersLk.Lock()
 	defer pmgr.peersLk.Unlock()
@@ -127,6 +145,11 @@ func (pmgr *PeerMgr) Disconnect(p peer.ID) {
 	}
 }
 
+// IsBanned returns whether the peer is banned.
+func (pmgr *PeerMgr) IsBanned(p peer.ID) bool {
+	return pmgr.bans.IsBanned(p)
+}
+
 func (pmgr *PeerMgr) Stop(ctx context.Context) error {
 	log.Warn("closing peermgr done")
 	_ = pmgr.filPeerEmitter.Close()
@@ -185,6 +208,9 @@ func (pmgr *PeerMgr) doExpand(ctx context.Context) {
 
 		log.Info("connecting to bootstrap peers")
 		for _, bsp := range pmgr.bootstrappers {
+			if pmgr.IsBanned(bsp.ID) {
+				continue
+			}
 			if err := pmgr.h.Connect(ctx, bsp); err != nil {
 				log.Warnf("failed to connect to bootstrap peer: %s", err)
 			}
@@ -217,6 +243,10 @@ func (m MockPeerMgr) Disconnect(p peer.ID) {
 	return
 }
 
+func (m MockPeerMgr) IsBanned(p peer.ID) bool {
+	return false
+}
+
 func (m MockPeerMgr) Stop(ctx context.Context) error {
 	return nil
 }
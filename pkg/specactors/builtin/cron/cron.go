@@ -1,9 +1,35 @@
 package cron
 
 import (
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/ipfs/go-cid"
+
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+	cron0 "github.com/filecoin-project/specs-actors/actors/builtin/cron"
 	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	cron2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/cron"
+
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
 )
 
+func init() {
+	builtin.RegisterActorState(builtin0.CronActorCodeID, func(store adt.Store, root cid.Cid) (cbor.Marshaler, error) {
+		var out cron0.State
+		if err := store.Get(store.Context(), root, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	})
+	builtin.RegisterActorState(builtin2.CronActorCodeID, func(store adt.Store, root cid.Cid) (cbor.Marshaler, error) {
+		var out cron2.State
+		if err := store.Get(store.Context(), root, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	})
+}
+
 var (
 	Address = builtin2.CronActorAddr
 	Methods = builtin2.MethodsCron
@@ -0,0 +1,67 @@
+package verifreg
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	verifreg2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/verifreg"
+
+	"github.com/filecoin-project/venus/pkg/specactors"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// these types are the same between v0 and v2
+type DataCap = verifreg2.DataCap
+type AddVerifierParams = verifreg2.AddVerifierParams
+type AddVerifiedClientParams = verifreg2.AddVerifiedClientParams
+
+// AddVerifier builds the message the root key sends to make verifier a verifier
+// able to grant allowance bytes of datacap to clients.
+func AddVerifier(rootKey, verifier address.Address, allowance DataCap) (*types.UnsignedMessage, error) {
+	if verifier == address.Undef {
+		return nil, xerrors.Errorf("must provide a verifier address")
+	}
+	if allowance.Sign() <= 0 {
+		return nil, xerrors.Errorf("allowance must be positive, was %s", allowance)
+	}
+	return dataCapMessage(rootKey, Methods.AddVerifier, &AddVerifierParams{
+		Address:   verifier,
+		Allowance: allowance,
+	})
+}
+
+// AddVerifiedClient builds the message a verifier sends to grant client
+// allowance bytes of datacap, taken from the datacap of the verifier.
+func AddVerifiedClient(verifier, client address.Address, allowance DataCap) (*types.UnsignedMessage, error) {
+	if client == address.Undef {
+		return nil, xerrors.Errorf("must provide a client address")
+	}
+	if allowance.Sign() <= 0 {
+		return nil, xerrors.Errorf("allowance must be positive, was %s", allowance)
+	}
+	return dataCapMessage(verifier, Methods.AddVerifiedClient, &AddVerifiedClientParams{
+		Address:   client,
+		Allowance: allowance,
+	})
+}
+
+func dataCapMessage(from address.Address, method abi.MethodNum, params cbg.CBORMarshaler) (*types.UnsignedMessage, error) {
+	if from == address.Undef {
+		return nil, xerrors.Errorf("must provide source address")
+	}
+	enc, actErr := specactors.SerializeParams(params)
+	if actErr != nil {
+		return nil, xerrors.Errorf("failed to serialize parameters: %w", actErr)
+	}
+
+	return &types.UnsignedMessage{
+		To:     Address,
+		From:   from,
+		Value:  abi.NewTokenAmount(0),
+		Method: method,
+		Params: enc,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package verifreg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestDataCapMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	from, err := address.NewIDAddress(100)
+	require.NoError(t, err)
+	to, err := address.NewIDAddress(101)
+	require.NoError(t, err)
+	allowance := abi.NewStoragePower(1 << 30)
+
+	t.Run("add verifier", func(t *testing.T) {
+		msg, err := AddVerifier(from, to, allowance)
+		require.NoError(t, err)
+		assert.Equal(t, Address, msg.To)
+		assert.Equal(t, from, msg.From)
+		assert.Equal(t, Methods.AddVerifier, msg.Method)
+		assert.True(t, msg.Value.IsZero())
+
+		var params AddVerifierParams
+		require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+		assert.Equal(t, to, params.Address)
+		assert.True(t, allowance.Equals(params.Allowance))
+	})
+
+	t.Run("add verified client", func(t *testing.T) {
+		msg, err := AddVerifiedClient(from, to, allowance)
+		require.NoError(t, err)
+		assert.Equal(t, Address, msg.To)
+		assert.Equal(t, from, msg.From)
+		assert.Equal(t, Methods.AddVerifiedClient, msg.Method)
+		assert.True(t, msg.Value.IsZero())
+
+		var params AddVerifiedClientParams
+		require.NoError(t, params.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+		assert.Equal(t, to, params.Address)
+		assert.True(t, allowance.Equals(params.Allowance))
+	})
+
+	t.Run("rejects non-positive allowances", func(t *testing.T) {
+		for _, allowance := range []DataCap{abi.NewStoragePower(0), abi.NewStoragePower(-1)} {
+			_, err := AddVerifier(from, to, allowance)
+			assert.Error(t, err)
+			_, err = AddVerifiedClient(from, to, allowance)
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("rejects missing addresses", func(t *testing.T) {
+		_, err := AddVerifier(address.Undef, to, allowance)
+		assert.Error(t, err)
+		_, err = AddVerifier(from, address.Undef, allowance)
+		assert.Error(t, err)
+		_, err = AddVerifiedClient(address.Undef, to, allowance)
+		assert.Error(t, err)
+		_, err = AddVerifiedClient(from, address.Undef, allowance)
+		assert.Error(t, err)
+	})
+}
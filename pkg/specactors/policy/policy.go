@@ -53,6 +53,18 @@ func AddSupportedProofTypes(types ...abi.RegisteredSealProof) {
 	}
 }
 
+// SupportedProofTypes returns the supported seal proof types, sorted.
+func SupportedProofTypes() []abi.RegisteredSealProof {
+	types := make([]abi.RegisteredSealProof, 0, len(miner0.SupportedProofTypes))
+	for t := range miner0.SupportedProofTypes {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	return types
+}
+
 // SetPreCommitChallengeDelay sets the pre-commit challenge delay across all
 // actors versions. Use for testing.
 func SetPreCommitChallengeDelay(delay abi.ChainEpoch) {
@@ -0,0 +1,264 @@
+package state
+
+import (
+	"context"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
+	"github.com/filecoin-project/venus/pkg/types"
+	vmstate "github.com/filecoin-project/venus/pkg/vm/state"
+)
+
+// ActorDiff describes the change of a single actor between two state trees.
+// Pre is nil for an added actor and Cur is nil for a removed one.
+type ActorDiff struct {
+	Address      addr.Address
+	Code         cid.Cid
+	Pre          *types.Actor `json:",omitempty"`
+	Cur          *types.Actor `json:",omitempty"`
+	BalanceDelta abi.TokenAmount
+	NonceDelta   int64
+
+	// Details of the actor state changes, only set when drilling into supported actors.
+	Sectors    *miner.SectorChanges        `json:",omitempty"`
+	PreCommits *miner.PreCommitChanges     `json:",omitempty"`
+	DealStates *market.DealStateChanges    `json:",omitempty"`
+	Proposals  *market.DealProposalChanges `json:",omitempty"`
+	Claims     []ClaimChange               `json:",omitempty"`
+}
+
+// ClaimChange is a change of a miner's power claim. From is nil for an added claim and
+// To is nil for a removed one.
+type ClaimChange struct {
+	Miner addr.Address
+	From  *power.Claim `json:",omitempty"`
+	To    *power.Claim `json:",omitempty"`
+}
+
+// StateDiff lists the actors added, removed and modified between two state trees.
+type StateDiff struct {
+	PreRoot  cid.Cid
+	CurRoot  cid.Cid
+	Added    []*ActorDiff
+	Removed  []*ActorDiff
+	Modified []*ActorDiff
+}
+
+// DiffStateTrees compares the actors of the pre and cur state trees. If drill is true the
+// states of miner, market and power actors which changed are diffed too.
+func DiffStateTrees(ctx context.Context, ipldStore cbor.IpldStore, preRoot, curRoot cid.Cid, drill bool) (*StateDiff, error) {
+	pre, err := vmstate.LoadState(ctx, ipldStore, preRoot)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to load state tree %s", preRoot)
+	}
+	cur, err := vmstate.LoadState(ctx, ipldStore, curRoot)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to load state tree %s", curRoot)
+	}
+
+	out := &StateDiff{PreRoot: preRoot, CurRoot: curRoot}
+	if preRoot.Equals(curRoot) {
+		return out, nil
+	}
+
+	// vmstate.Diff only reports actors which are new or changed in the second tree,
+	// so removed actors are found by diffing the other way around.
+	changed, err := vmstate.Diff(pre, cur)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to diff state trees")
+	}
+	removed, err := vmstate.Diff(cur, pre)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to diff state trees")
+	}
+
+	store := StoreFromCbor(ctx, ipldStore)
+	for k := range changed {
+		curAct := changed[k]
+		a, err := addr.NewFromString(k)
+		if err != nil {
+			return nil, err
+		}
+
+		preAct, found, err := pre.GetActor(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			out.Added = append(out.Added, newActorDiff(a, nil, &curAct))
+			continue
+		}
+
+		d := newActorDiff(a, preAct, &curAct)
+		if drill {
+			if err := drillActorDiff(store, d); err != nil {
+				return nil, xerrors.Wrapf(err, "failed to diff state of actor %s", a)
+			}
+		}
+		out.Modified = append(out.Modified, d)
+	}
+
+	for k := range removed {
+		// actors in both trees were already reported as modified
+		if _, ok := changed[k]; ok {
+			continue
+		}
+		preAct := removed[k]
+		a, err := addr.NewFromString(k)
+		if err != nil {
+			return nil, err
+		}
+		out.Removed = append(out.Removed, newActorDiff(a, &preAct, nil))
+	}
+
+	return out, nil
+}
+
+func newActorDiff(a addr.Address, pre, cur *types.Actor) *ActorDiff {
+	d := &ActorDiff{
+		Address:      a,
+		Pre:          pre,
+		Cur:          cur,
+		BalanceDelta: big.Zero(),
+	}
+	if pre != nil {
+		d.Code = pre.Code.Cid
+		d.BalanceDelta = big.Sub(d.BalanceDelta, pre.Balance)
+		d.NonceDelta -= int64(pre.Nonce)
+	}
+	if cur != nil {
+		d.Code = cur.Code.Cid
+		d.BalanceDelta = big.Add(d.BalanceDelta, cur.Balance)
+		d.NonceDelta += int64(cur.Nonce)
+	}
+	return d
+}
+
+// drillActorDiff fills in the state changes of a modified miner, market or power actor.
+func drillActorDiff(store adt.Store, d *ActorDiff) error {
+	if d.Pre.Head.Cid.Equals(d.Cur.Head.Cid) {
+		return nil
+	}
+
+	switch {
+	case builtin.IsStorageMinerActor(d.Code):
+		preSt, err := miner.Load(store, d.Pre)
+		if err != nil {
+			return err
+		}
+		curSt, err := miner.Load(store, d.Cur)
+		if err != nil {
+			return err
+		}
+		if d.Sectors, err = miner.DiffSectors(preSt, curSt); err != nil {
+			return err
+		}
+		if d.PreCommits, err = miner.DiffPreCommits(preSt, curSt); err != nil {
+			return err
+		}
+	case d.Address == market.Address:
+		preSt, err := market.Load(store, d.Pre)
+		if err != nil {
+			return err
+		}
+		curSt, err := market.Load(store, d.Cur)
+		if err != nil {
+			return err
+		}
+		if d.DealStates, err = diffDealStates(preSt, curSt); err != nil {
+			return err
+		}
+		if d.Proposals, err = diffDealProposals(preSt, curSt); err != nil {
+			return err
+		}
+	case d.Address == power.Address:
+		preSt, err := power.Load(store, d.Pre)
+		if err != nil {
+			return err
+		}
+		curSt, err := power.Load(store, d.Cur)
+		if err != nil {
+			return err
+		}
+		if d.Claims, err = diffClaims(preSt, curSt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffDealStates(pre, cur market.State) (*market.DealStateChanges, error) {
+	changed, err := pre.StatesChanged(cur)
+	if err != nil || !changed {
+		return nil, err
+	}
+	preStates, err := pre.States()
+	if err != nil {
+		return nil, err
+	}
+	curStates, err := cur.States()
+	if err != nil {
+		return nil, err
+	}
+	return market.DiffDealStates(preStates, curStates)
+}
+
+func diffDealProposals(pre, cur market.State) (*market.DealProposalChanges, error) {
+	changed, err := pre.ProposalsChanged(cur)
+	if err != nil || !changed {
+		return nil, err
+	}
+	preProposals, err := pre.Proposals()
+	if err != nil {
+		return nil, err
+	}
+	curProposals, err := cur.Proposals()
+	if err != nil {
+		return nil, err
+	}
+	return market.DiffDealProposals(preProposals, curProposals)
+}
+
+// diffClaims compares the power claims of all miners, the power actor has no diff
+// helper as claims are few compared to sectors and deals.
+func diffClaims(pre, cur power.State) ([]ClaimChange, error) {
+	preClaims := map[addr.Address]power.Claim{}
+	if err := pre.ForEachClaim(func(miner addr.Address, claim power.Claim) error {
+		preClaims[miner] = claim
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var out []ClaimChange
+	if err := cur.ForEachClaim(func(miner addr.Address, claim power.Claim) error {
+		preClaim, found := preClaims[miner]
+		delete(preClaims, miner)
+		if !found {
+			out = append(out, ClaimChange{Miner: miner, To: &claim})
+			return nil
+		}
+		if !preClaim.RawBytePower.Equals(claim.RawBytePower) || !preClaim.QualityAdjPower.Equals(claim.QualityAdjPower) {
+			out = append(out, ClaimChange{Miner: miner, From: &preClaim, To: &claim})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for miner, claim := range preClaims {
+		claim := claim
+		out = append(out, ClaimChange{Miner: miner, From: &claim})
+	}
+	return out, nil
+}
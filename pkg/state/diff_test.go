@@ -0,0 +1,72 @@
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	vmstate "github.com/filecoin-project/venus/pkg/vm/state"
+)
+
+func TestDiffStateTrees(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	cst := cbor.NewMemCborStore()
+
+	kept := types.RequireIDAddress(t, 100)
+	changed := types.RequireIDAddress(t, 101)
+	removed := types.RequireIDAddress(t, 102)
+	added := types.RequireIDAddress(t, 103)
+	head := types.CidFromString(t, "head")
+
+	tree, err := vmstate.NewState(cst, vmstate.StateTreeVersion1)
+	require.NoError(t, err)
+	for _, a := range []struct {
+		addr   address.Address
+		amount int64
+	}{{kept, 1}, {changed, 10}, {removed, 5}} {
+		require.NoError(t, tree.SetActor(ctx, a.addr, types.NewActor(builtin.AccountActorCodeID, abi.NewTokenAmount(a.amount), head)))
+	}
+	preRoot, err := tree.Flush(ctx)
+	require.NoError(t, err)
+
+	changedAct := types.NewActor(builtin.AccountActorCodeID, abi.NewTokenAmount(7), head)
+	changedAct.Nonce = 2
+	require.NoError(t, tree.SetActor(ctx, changed, changedAct))
+	require.NoError(t, tree.DeleteActor(ctx, removed))
+	require.NoError(t, tree.SetActor(ctx, added, types.NewActor(builtin.AccountActorCodeID, abi.NewTokenAmount(3), head)))
+	curRoot, err := tree.Flush(ctx)
+	require.NoError(t, err)
+
+	diff, err := DiffStateTrees(ctx, cst, preRoot, curRoot, true)
+	require.NoError(t, err)
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, added, diff.Added[0].Address)
+	assert.Nil(t, diff.Added[0].Pre)
+	assert.Equal(t, abi.NewTokenAmount(3), diff.Added[0].BalanceDelta)
+
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, removed, diff.Removed[0].Address)
+	assert.Nil(t, diff.Removed[0].Cur)
+	assert.Equal(t, abi.NewTokenAmount(-5), diff.Removed[0].BalanceDelta)
+
+	require.Len(t, diff.Modified, 1)
+	assert.Equal(t, changed, diff.Modified[0].Address)
+	assert.Equal(t, abi.NewTokenAmount(-3), diff.Modified[0].BalanceDelta)
+	assert.Equal(t, int64(2), diff.Modified[0].NonceDelta)
+
+	same, err := DiffStateTrees(ctx, cst, curRoot, curRoot, false)
+	require.NoError(t, err)
+	assert.Empty(t, same.Added)
+	assert.Empty(t, same.Removed)
+	assert.Empty(t, same.Modified)
+}
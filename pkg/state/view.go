@@ -511,27 +511,37 @@ func (v *View) MarketDealProviderCollateralBounds(ctx context.Context, size abi.
 	panic("not impl")
 }
 
-func (v *View) StateVerifiedClientStatus(ctx context.Context, addr addr.Address) (abi.StoragePower, error) {
-	act, err := v.loadActor(ctx, verifreg.Address)
+// StateVerifiedClientStatus returns the datacap a verified client can still use in deals, nil
+// when addr is not a verified client.
+func (v *View) StateVerifiedClientStatus(ctx context.Context, addr addr.Address) (*abi.StoragePower, error) {
+	return v.verifregDataCap(ctx, addr, verifreg.State.VerifiedClientDataCap)
+}
+
+// StateVerifierStatus returns the datacap a verifier can still grant to clients, nil when addr
+// is not a verifier.
+func (v *View) StateVerifierStatus(ctx context.Context, addr addr.Address) (*abi.StoragePower, error) {
+	return v.verifregDataCap(ctx, addr, verifreg.State.VerifierDataCap)
+}
+
+func (v *View) verifregDataCap(ctx context.Context, a addr.Address, get func(verifreg.State, addr.Address) (bool, abi.StoragePower, error)) (*abi.StoragePower, error) {
+	state, err := v.LoadVerifregActor(ctx)
 	if err != nil {
-		return abi.NewStoragePower(0), err
+		return nil, xerrors.Wrap(err, "failed to load verified registry actor")
 	}
-
-	state, err := verifreg.Load(v.adtStore(ctx), act)
+	// The registry is keyed by ID addresses.
+	idAddr, err := v.InitResolveAddress(ctx, a)
 	if err != nil {
-		return abi.NewStoragePower(0), err
+		return nil, xerrors.Wrapf(err, "failed to resolve address %s", a)
 	}
 
-	found, storagePower, err := state.VerifiedClientDataCap(addr)
+	found, dcap, err := get(state, idAddr)
 	if err != nil {
-		return abi.NewStoragePower(0), err
+		return nil, err
 	}
-
 	if !found {
-		return abi.NewStoragePower(0), xerrors.New("address not found")
+		return nil, nil
 	}
-
-	return storagePower, nil
+	return &dcap, nil
 }
 
 func (v *View) StateMarketStorageDeal(ctx context.Context, dealID abi.DealID) (*MarketDeal, error) {
@@ -760,6 +770,11 @@ func (v *View) loadMinerActor(ctx context.Context, address addr.Address) (miner.
 	return miner.Load(adt.WrapStore(context.TODO(), v.ipldStore), actr)
 }
 
+// LoadPowerActor loads the state of the power actor.
+func (v *View) LoadPowerActor(ctx context.Context) (power.State, error) {
+	return v.loadPowerActor(ctx)
+}
+
 func (v *View) loadPowerActor(ctx context.Context) (power.State, error) {
 	actr, err := v.loadActor(ctx, power.Address)
 	if err != nil {
@@ -769,6 +784,11 @@ func (v *View) loadPowerActor(ctx context.Context) (power.State, error) {
 	return power.Load(adt.WrapStore(ctx, v.ipldStore), actr)
 }
 
+// LoadRewardActor loads the state of the reward actor.
+func (v *View) LoadRewardActor(ctx context.Context) (reward.State, error) {
+	return v.loadRewardActor(ctx)
+}
+
 func (v *View) loadRewardActor(ctx context.Context) (reward.State, error) {
 	actr, err := v.loadActor(ctx, reward.Address)
 	if err != nil {
@@ -778,6 +798,11 @@ func (v *View) loadRewardActor(ctx context.Context) (reward.State, error) {
 	return reward.Load(adt.WrapStore(ctx, v.ipldStore), actr)
 }
 
+// LoadMarketActor loads the state of the storage market actor.
+func (v *View) LoadMarketActor(ctx context.Context) (market.State, error) {
+	return v.loadMarketActor(ctx)
+}
+
 func (v *View) loadMarketActor(ctx context.Context) (market.State, error) {
 	actr, err := v.loadActor(ctx, market.Address)
 	if err != nil {
@@ -787,6 +812,30 @@ func (v *View) loadMarketActor(ctx context.Context) (market.State, error) {
 	return market.Load(adt.WrapStore(ctx, v.ipldStore), actr)
 }
 
+// LoadActorState loads the actor at addr and decodes its state with the state type of its
+// builtin actor code and version.
+func (v *View) LoadActorState(ctx context.Context, a addr.Address) (*types.Actor, interface{}, error) {
+	actr, err := v.loadActor(ctx, a)
+	if err != nil {
+		return nil, nil, err
+	}
+	st, err := builtin.Load(adt.WrapStore(ctx, v.ipldStore), actr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return actr, st, nil
+}
+
+// LoadVerifregActor loads the state of the verified registry actor.
+func (v *View) LoadVerifregActor(ctx context.Context) (verifreg.State, error) {
+	actr, err := v.loadActor(ctx, verifreg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifreg.Load(adt.WrapStore(ctx, v.ipldStore), actr)
+}
+
 func (v *View) loadAccountActor(ctx context.Context, a addr.Address) (account.State, error) {
 	resolvedAddr, err := v.InitResolveAddress(ctx, a)
 	if err != nil {
@@ -817,10 +866,6 @@ func (v *View) loadActor(ctx context.Context, address addr.Address) (*types.Acto
 	return actor, err
 }
 
-func (v *View) adtStore(ctx context.Context) adt.Store {
-	return StoreFromCbor(ctx, v.ipldStore)
-}
-
 func getFilMarketLocked(ctx context.Context, ipldStore cbor.IpldStore, st vmstate.Tree) (abi.TokenAmount, error) {
 	mactor, found, err := st.GetActor(ctx, market.Address)
 	if !found || err != nil {
@@ -199,6 +199,11 @@ func (f *TestFetcher) FetchTipSetHeaders(ctx context.Context, tsKey block.TipSet
 	return f.FetchTipSets(ctx, tsKey, from, done)
 }
 
+// FetchStateTrees does nothing, the TestFetcher only serves blocks.
+func (f *TestFetcher) FetchStateTrees(ctx context.Context, roots []cid.Cid) error {
+	return nil
+}
+
 // GetBlocks returns any blocks in the source with matching cids.
 func (f *TestFetcher) GetBlocks(ctx context.Context, cids []cid.Cid) ([]*block.Block, error) {
 	var ret []*block.Block
@@ -245,6 +250,10 @@ func (f *TestExchange) AddPeer(peer peer.ID) {
 	return
 }
 
+func (f *TestExchange) PeerScores() []exchange.PeerScore {
+	return nil
+}
+
 func (f *TestExchange) RemovePeer(peer peer.ID) {
 	return
 }
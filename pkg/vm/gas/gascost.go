@@ -184,6 +184,17 @@ func NewPricesSchedule(forkParams *config.ForkUpgradeConfig) *PricesSchedule {
 
 // PricelistByEpoch finds the latest prices for the given epoch
 func (schedule *PricesSchedule) PricelistByEpoch(epoch abi.ChainEpoch) Pricelist {
+	_, bestPrice := schedule.pricelistAt(epoch)
+	return bestPrice
+}
+
+// PricelistEpoch returns the epoch from which the prices used at the given epoch apply
+func (schedule *PricesSchedule) PricelistEpoch(epoch abi.ChainEpoch) abi.ChainEpoch {
+	bestEpoch, _ := schedule.pricelistAt(epoch)
+	return bestEpoch
+}
+
+func (schedule *PricesSchedule) pricelistAt(epoch abi.ChainEpoch) (abi.ChainEpoch, Pricelist) {
 	// since we are storing the prices as map or epoch to price
 	// we need to get the price with the highest epoch that is lower or equal to the `epoch` arg
 	bestEpoch := abi.ChainEpoch(0)
@@ -198,5 +209,5 @@ func (schedule *PricesSchedule) PricelistByEpoch(epoch abi.ChainEpoch) Pricelist
 	if bestPrice == nil {
 		panic(fmt.Sprintf("bad setup: no gas prices available for epoch %d", epoch))
 	}
-	return bestPrice
+	return bestEpoch, bestPrice
 }